@@ -10,7 +10,6 @@ import (
 func main() {
 	ctx := context.Background()
 	if err := cli.Execute(ctx, os.Args[1:]); err != nil {
-		cli.PrintError(err)
-		os.Exit(1)
+		cli.Exit(err)
 	}
 }
@@ -0,0 +1,54 @@
+// Package bytesize parses and prints byte counts such as "500MB" or "2G".
+package bytesize
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var units = []struct {
+	suffix string
+	mult   int64
+}{
+	{"GB", 1 << 30}, {"G", 1 << 30},
+	{"MB", 1 << 20}, {"M", 1 << 20},
+	{"KB", 1 << 10}, {"K", 1 << 10},
+	{"B", 1},
+}
+
+// Parse accepts a byte count with an optional K/M/G (or KB/MB/GB) suffix, in powers of
+// 1024. Zero is allowed; callers that need a positive size check for it.
+func Parse(s string) (int64, error) {
+	v := strings.ToUpper(strings.TrimSpace(s))
+	mult := int64(1)
+	for _, u := range units {
+		if strings.HasSuffix(v, u.suffix) {
+			v = strings.TrimSpace(strings.TrimSuffix(v, u.suffix))
+			mult = u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseFloat(v, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 500MB or 2G)", s)
+	}
+	return int64(n * float64(mult)), nil
+}
+
+// Format prints n in the largest unit it reaches, with one decimal unless the unit
+// divides it evenly: "512MB", "1.5GB", "300B".
+func Format(n int64) string {
+	for _, u := range []struct {
+		suffix string
+		mult   int64
+	}{{"GB", 1 << 30}, {"MB", 1 << 20}, {"KB", 1 << 10}} {
+		if n >= u.mult {
+			if n%u.mult == 0 {
+				return fmt.Sprintf("%d%s", n/u.mult, u.suffix)
+			}
+			return fmt.Sprintf("%.1f%s", float64(n)/float64(u.mult), u.suffix)
+		}
+	}
+	return fmt.Sprintf("%dB", n)
+}
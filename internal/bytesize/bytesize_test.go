@@ -0,0 +1,24 @@
+package bytesize
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	for in, want := range map[string]int64{"500MB": 500 << 20, "1.5g": 3 << 29, " 64 k ": 64 << 10, "0": 0, "12": 12} {
+		if n, err := Parse(in); err != nil || n != want {
+			t.Errorf("Parse(%q) = %d, %v; want %d", in, n, err, want)
+		}
+	}
+	for _, bad := range []string{"lots", "-1M", "MB"} {
+		if _, err := Parse(bad); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	for n, want := range map[int64]string{512 << 20: "512MB", 3 << 29: "1.5GB", 1536: "1.5KB", 300: "300B", 0: "0B"} {
+		if got := Format(n); got != want {
+			t.Errorf("Format(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
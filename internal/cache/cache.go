@@ -6,25 +6,45 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strings"
 	"time"
+
+	"github.com/leolaurindo/gixt/internal/gist"
 )
 
 type Manifest struct {
 	GistID      string    `json:"gist_id"`
+	Host        string    `json:"host,omitempty"` // empty for github.com
 	SHA         string    `json:"sha"`
 	Description string    `json:"description"`
 	Owner       string    `json:"owner"`
 	Files       []string  `json:"files"`
 	Source      string    `json:"source_url,omitempty"`
 	CreatedAt   time.Time `json:"created_at"`
+	LastUsed    time.Time `json:"last_used,omitempty"` // last run from this revision; drives cache prune
+}
+
+// Key returns the host-qualified gist key for the manifest (see gist.Key).
+func (m Manifest) Key() string {
+	return gist.Key(m.Host, m.GistID)
 }
 
 var cleaner = regexp.MustCompile(`[^a-zA-Z0-9._-]`)
 
-func Dir(cacheRoot, gistID, sha string) string {
-	cleanID := cleaner.ReplaceAllString(gistID, "-")
+// Dir returns the work dir for a gist key at sha.
+func Dir(cacheRoot, key, sha string) string {
 	cleanSHA := cleaner.ReplaceAllString(sha, "-")
-	return filepath.Join(cacheRoot, cleanID, cleanSHA)
+	return filepath.Join(GistDir(cacheRoot, key), cleanSHA)
+}
+
+// GistDir returns the directory holding every cached revision of a gist key.
+func GistDir(cacheRoot, key string) string {
+	return filepath.Join(cacheRoot, CleanName(key))
+}
+
+// CleanName maps a gist key to the directory name used under the cache root.
+func CleanName(key string) string {
+	return cleaner.ReplaceAllString(key, "-")
 }
 
 func ManifestPath(cacheDir string) string {
@@ -72,7 +92,11 @@ func PresentFiles(dir string, files []string) bool {
 	return true
 }
 
+// Shorten abbreviates a gist ID or SHA to 8 characters, keeping any "host/" key prefix.
 func Shorten(id string) string {
+	if i := strings.LastIndex(id, "/"); i >= 0 {
+		return id[:i+1] + Shorten(id[i+1:])
+	}
 	if len(id) <= 8 {
 		return id
 	}
@@ -90,3 +114,28 @@ func IsEmptyDir(dir string) bool {
 	}
 	return len(entries) == 0
 }
+
+// LatestRevision returns the manifest and dir of the most recently cached revision of key
+// whose files are all present.
+func LatestRevision(cacheRoot, key string) (Manifest, string, bool) {
+	entries, err := os.ReadDir(GistDir(cacheRoot, key))
+	if err != nil {
+		return Manifest{}, "", false
+	}
+	var best Manifest
+	var bestDir string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		dir := filepath.Join(GistDir(cacheRoot, key), e.Name())
+		m, err := LoadManifest(ManifestPath(dir))
+		if err != nil || !PresentFiles(dir, m.Files) {
+			continue
+		}
+		if bestDir == "" || m.CreatedAt.After(best.CreatedAt) {
+			best, bestDir = m, dir
+		}
+	}
+	return best, bestDir, bestDir != ""
+}
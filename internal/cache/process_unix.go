@@ -0,0 +1,17 @@
+//go:build !windows
+
+package cache
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive reports whether pid is running. A process owned by another user still counts.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,23 @@
+//go:build windows
+
+package cache
+
+import "syscall"
+
+// stillActive is the exit code GetExitCodeProcess reports for a running process.
+const stillActive = 259
+
+// processAlive reports whether pid is running.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	h, err := syscall.OpenProcess(syscall.PROCESS_QUERY_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// Access denied means the process exists but belongs to someone else.
+		return err == syscall.ERROR_ACCESS_DENIED
+	}
+	defer syscall.CloseHandle(h)
+	var code uint32
+	return syscall.GetExitCodeProcess(h, &code) == nil && code == stillActive
+}
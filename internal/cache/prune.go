@@ -0,0 +1,181 @@
+package cache
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TempPrefix names the scratch dirs ephemeral runs create under the cache root.
+const TempPrefix = "gixt-"
+
+// StaleTempAge is how long a scratch dir with no owner record must sit untouched before
+// prune treats it as left over from a crashed run rather than one still in progress.
+const StaleTempAge = 24 * time.Hour
+
+// OwnerFile holds the pid of the gixt process using a scratch dir.
+const OwnerFile = ".gixt-owner"
+
+// MkdirTemp creates a scratch dir under root whose name starts with TempPrefix+pattern and
+// records the current process as its owner, so prune leaves it alone while gixt runs.
+func MkdirTemp(root, pattern string) (string, error) {
+	dir, err := os.MkdirTemp(root, TempPrefix+pattern)
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(filepath.Join(dir, OwnerFile), []byte(strconv.Itoa(os.Getpid())), 0o644); err != nil {
+		os.RemoveAll(dir)
+		return "", fmt.Errorf("record temp dir owner: %w", err)
+	}
+	return dir, nil
+}
+
+// Revision is one cached gist revision directory.
+type Revision struct {
+	Dir      string
+	Key      string
+	SHA      string
+	LastUsed time.Time
+	Size     int64
+}
+
+// PrunePolicy selects revisions to delete. Zero values disable a rule.
+type PrunePolicy struct {
+	OlderThan  time.Duration
+	KeepLatest int   // revisions to keep per gist, most recently used first
+	MaxSize    int64 // bytes; least recently used revisions go first
+	Now        time.Time
+}
+
+type Removal struct {
+	Revision
+	Reason string
+}
+
+// Scan lists cached revisions and leftover scratch dirs under root.
+func Scan(root string) ([]Revision, []string, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+	var revs []Revision
+	var temps []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		dir := filepath.Join(root, e.Name())
+		if strings.HasPrefix(e.Name(), TempPrefix) {
+			temps = append(temps, dir)
+			continue
+		}
+		shaDirs, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, s := range shaDirs {
+			if !s.IsDir() {
+				continue
+			}
+			revDir := filepath.Join(dir, s.Name())
+			mpath := ManifestPath(revDir)
+			m, err := LoadManifest(mpath)
+			if err != nil {
+				continue
+			}
+			rev := Revision{Dir: revDir, Key: m.Key(), SHA: m.SHA, LastUsed: m.LastUsed, Size: DirSize(revDir)}
+			if rev.LastUsed.IsZero() {
+				rev.LastUsed = m.CreatedAt
+			}
+			if rev.LastUsed.IsZero() {
+				if info, err := os.Stat(mpath); err == nil {
+					rev.LastUsed = info.ModTime()
+				}
+			}
+			revs = append(revs, rev)
+		}
+	}
+	return revs, temps, nil
+}
+
+// StaleTemps filters scratch dirs that are no longer in use: those whose owning process has
+// exited, and those without an owner record not modified within StaleTempAge of now.
+func StaleTemps(dirs []string, now time.Time) []string {
+	var out []string
+	for _, d := range dirs {
+		if data, err := os.ReadFile(filepath.Join(d, OwnerFile)); err == nil {
+			if pid, err := strconv.Atoi(strings.TrimSpace(string(data))); err == nil {
+				if !processAlive(pid) {
+					out = append(out, d)
+				}
+				continue
+			}
+		}
+		info, err := os.Stat(d)
+		if err != nil || now.Sub(info.ModTime()) < StaleTempAge {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
+// PlanPrune applies the policy rules in order (keep-latest, older-than, max-size) and
+// returns the revisions to remove.
+func PlanPrune(revs []Revision, p PrunePolicy) []Removal {
+	sorted := append([]Revision(nil), revs...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].LastUsed.After(sorted[j].LastUsed) })
+
+	var removals []Removal
+	var kept []Revision
+	perGist := map[string]int{}
+	for _, r := range sorted {
+		perGist[r.Key]++
+		switch {
+		case p.KeepLatest > 0 && perGist[r.Key] > p.KeepLatest:
+			removals = append(removals, Removal{r, "beyond keep-latest"})
+		case p.OlderThan > 0 && p.Now.Sub(r.LastUsed) > p.OlderThan:
+			removals = append(removals, Removal{r, "not used within older-than"})
+		default:
+			kept = append(kept, r)
+		}
+	}
+
+	if p.MaxSize > 0 {
+		var total int64
+		for _, r := range kept {
+			total += r.Size
+		}
+		// kept is most recent first, so evict from the end.
+		for i := len(kept) - 1; i >= 0 && total > p.MaxSize; i-- {
+			removals = append(removals, Removal{kept[i], "over max-size"})
+			total -= kept[i].Size
+		}
+	}
+	return removals
+}
+
+// DirSize sums the sizes of regular files under dir.
+func DirSize(dir string) int64 {
+	var total int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.Type().IsRegular() {
+			if info, err := d.Info(); err == nil {
+				total += info.Size()
+			}
+		}
+		return nil
+	})
+	return total
+}
@@ -0,0 +1,100 @@
+package cache
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeRevision(t *testing.T, root, key, sha string, lastUsed time.Time, size int) string {
+	t.Helper()
+	dir := Dir(root, key, sha)
+	if err := EnsureDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.sh"), []byte(strings.Repeat("x", size)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	m := Manifest{GistID: key, SHA: sha, CreatedAt: lastUsed.Add(-time.Hour), LastUsed: lastUsed}
+	if err := SaveManifest(ManifestPath(dir), m); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func removedSHAs(rs []Removal) []string {
+	var out []string
+	for _, r := range rs {
+		out = append(out, r.SHA)
+	}
+	sort.Strings(out)
+	return out
+}
+
+func TestPlanPrunePolicies(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	writeRevision(t, root, "aaaa", "a1", now.Add(-1*time.Hour), 100)
+	writeRevision(t, root, "aaaa", "a2", now.Add(-48*time.Hour), 100)
+	writeRevision(t, root, "aaaa", "a3", now.Add(-40*24*time.Hour), 100)
+	writeRevision(t, root, "bbbb", "b1", now.Add(-2*time.Hour), 5000)
+	temp := filepath.Join(root, TempPrefix+"123")
+	if err := os.Mkdir(temp, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	revs, temps, err := Scan(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 4 || len(temps) != 1 {
+		t.Fatalf("Scan found %d revisions, %d temps", len(revs), len(temps))
+	}
+	if got := StaleTemps(temps, now); len(got) != 0 {
+		t.Fatalf("fresh temp dir should be kept, got %v", got)
+	}
+	if got := StaleTemps(temps, now.Add(2*StaleTempAge)); len(got) != 1 {
+		t.Fatalf("old temp dir should be stale, got %v", got)
+	}
+
+	live, err := MkdirTemp(root, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := StaleTemps([]string{live}, now.Add(2*StaleTempAge)); len(got) != 0 {
+		t.Fatalf("temp dir of a running process should be kept, got %v", got)
+	}
+	exited := exec.Command(os.Args[0], "-test.run=^$")
+	if err := exited.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(live, OwnerFile), []byte(strconv.Itoa(exited.Process.Pid)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := StaleTemps([]string{live}, now); len(got) != 1 {
+		t.Fatalf("temp dir of an exited process should be stale, got %v", got)
+	}
+
+	cases := []struct {
+		name   string
+		policy PrunePolicy
+		want   []string
+	}{
+		{"none", PrunePolicy{Now: now}, nil},
+		{"keep-latest", PrunePolicy{KeepLatest: 1, Now: now}, []string{"a2", "a3"}},
+		{"older-than", PrunePolicy{OlderThan: 30 * 24 * time.Hour, Now: now}, []string{"a3"}},
+		// LRU: drops the oldest revisions until the rest fits.
+		{"max-size", PrunePolicy{MaxSize: 5600, Now: now}, []string{"a2", "a3"}},
+	}
+	for _, tc := range cases {
+		got := removedSHAs(PlanPrune(revs, tc.policy))
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("%s: removed %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -9,13 +9,13 @@ import (
 	"github.com/leolaurindo/gixt/internal/gist"
 )
 
-func handleAlias(args []string) error {
+func handleAlias(args []string, hostFlag string) error {
 	if len(args) == 0 {
 		fmt.Println("alias commands: add <name> <gist-id>, list, remove <name>")
 		return nil
 	}
 
-	paths, err := ensurePaths("")
+	paths, settings, err := ensurePathsAndSettings("")
 	if err != nil {
 		return err
 	}
@@ -31,7 +31,11 @@ func handleAlias(args []string) error {
 			return errors.New("usage: gixt alias add <name> <gist-id>")
 		}
 		name := args[1]
-		id := gist.ExtractID(args[2])
+		host, id := gist.ExtractHostID(args[2])
+		if host == "" {
+			host = activeHost(settings, hostFlag)
+		}
+		id = gist.Key(host, id)
 		aliases[name] = id
 		if err := alias.Save(paths.AliasFile, aliases); err != nil {
 			return err
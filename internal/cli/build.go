@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/leolaurindo/gixt/internal/runner"
+)
+
+// buildDirName holds compiled binaries inside a gist's work dir.
+const buildDirName = ".gixt-build"
+
+// ensureBuilt compiles b unless its binary already exists. rebuild (--rebuild) forces a
+// fresh build. Compiler output is streamed with --verbose and otherwise shown only when the
+// build fails. Build scripts run gist code, so the compiler runs confined by conf.
+func ensureBuilt(ctx context.Context, b runner.Build, rebuild bool, verbose bool, conf confinement) error {
+	if b.Built() && !rebuild {
+		if verbose {
+			fmt.Printf("%susing cached %s build %s%s\n", clrInfo, b.Lang, b.Binary, clrReset)
+		}
+		return nil
+	}
+	if err := os.RemoveAll(filepath.Dir(b.Binary)); err != nil {
+		return fmt.Errorf("clear old build: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(b.Binary), 0o755); err != nil {
+		return fmt.Errorf("create build dir: %w", err)
+	}
+	if verbose {
+		fmt.Printf("%sbuilding with %s: %s%s\n", clrInfo, b.Toolchain, runner.FormatArgv(b.Argv), clrReset)
+	}
+	c := exec.CommandContext(ctx, b.Argv[0], b.Argv[1:]...)
+	c.Dir = b.Dir
+	if err := confineCommand(c, conf, nil); err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	if verbose {
+		c.Stdout, c.Stderr = os.Stdout, os.Stderr
+	} else {
+		c.Stdout, c.Stderr = &out, &out
+	}
+	if err := c.Run(); err != nil {
+		os.Stderr.Write(out.Bytes())
+		_ = os.Remove(b.Binary)
+		return withExitCode(ExitSetup, &setupError{step: b.Lang + " build", cause: err})
+	}
+	if !b.Built() {
+		return withExitCode(ExitSetup, &setupError{step: b.Lang + " build", cause: fmt.Errorf("no binary at %s", b.Binary)})
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/leolaurindo/gixt/internal/runner"
+)
+
+func TestEnsureBuiltCompilesOnceAndRebuilds(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil || testing.Short() {
+		t.Skip("needs the go toolchain")
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "hello.go"), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd, err := runner.Resolve(runner.Request{Dir: dir, Files: []string{"hello.go"}, ExecDir: dir, BuildDir: filepath.Join(dir, buildDirName)})
+	if err != nil || cmd.Build == nil {
+		t.Fatalf("expected a build step: %+v %v", cmd, err)
+	}
+	if cmd.Argv[0] != cmd.Build.Binary || cmd.Reason != "build go" {
+		t.Fatalf("command should exec the binary: %+v", cmd)
+	}
+	ctx := context.Background()
+	if err := ensureBuilt(ctx, *cmd.Build, false, false, confinement{}); err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	first, err := os.Stat(cmd.Build.Binary)
+	if err != nil {
+		t.Fatalf("binary missing: %v", err)
+	}
+	if err := ensureBuilt(ctx, *cmd.Build, false, false, confinement{}); err != nil {
+		t.Fatal(err)
+	}
+	if again, _ := os.Stat(cmd.Build.Binary); !again.ModTime().Equal(first.ModTime()) {
+		t.Fatalf("cached binary should be reused")
+	}
+	old := time.Now().Add(-time.Hour)
+	_ = os.Chtimes(cmd.Build.Binary, old, old)
+	if err := ensureBuilt(ctx, *cmd.Build, true, false, confinement{}); err != nil {
+		t.Fatal(err)
+	}
+	if rebuilt, _ := os.Stat(cmd.Build.Binary); !rebuilt.ModTime().After(old) {
+		t.Fatalf("--rebuild should compile again")
+	}
+	// The compiler gets the confined environment, not gixt's own.
+	scrubbed := confinement{baseEnv: append(os.Environ(), "GOFLAGS=-gixt-not-a-flag")}
+	if err := ensureBuilt(ctx, *cmd.Build, true, false, scrubbed); ExitCode(err) != ExitSetup {
+		t.Fatalf("build should have used the confined environment, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "hello.go"), []byte("package main\n\nfunc main() { nope }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ensureBuilt(ctx, *cmd.Build, true, false, confinement{}); ExitCode(err) != ExitSetup {
+		t.Fatalf("expected build failure exit code, got %v", err)
+	}
+}
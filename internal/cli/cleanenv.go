@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/leolaurindo/gixt/internal/config"
+	"github.com/leolaurindo/gixt/internal/envvar"
+	"github.com/leolaurindo/gixt/internal/secret"
+)
+
+// defaultEnvAllowlist is what a clean environment keeps when settings name no allowlist.
+func defaultEnvAllowlist() []string {
+	allow := []string{"PATH", "HOME", "LANG", "TERM"}
+	if runtime.GOOS == "windows" {
+		// Windows programs and runtimes fail in odd ways without these.
+		allow = append(allow, "SYSTEMROOT", "SYSTEMDRIVE", "WINDIR", "COMSPEC", "PATHEXT", "TEMP", "TMP", "USERPROFILE")
+	}
+	return allow
+}
+
+// toolchainEnvNames are the variables that locate compilers and their caches. A clean
+// environment withholds them from the gist but gives them back to installs and compiles.
+func toolchainEnvNames() []string {
+	names := []string{"GOROOT", "GOPATH", "GOCACHE", "GOMODCACHE", "GOFLAGS", "CARGO_HOME", "RUSTUP_HOME", "CC", "CXX"}
+	if runtime.GOOS == "windows" {
+		names = append(names, "LOCALAPPDATA", "APPDATA") // Go keeps its build cache here
+	}
+	return names
+}
+
+// withToolchainEnv adds gixt's toolchain variables to a clean environment, keeping any
+// value base already has. A nil base (the full environment) is returned as is.
+func withToolchainEnv(base []string) []string {
+	if base == nil {
+		return nil
+	}
+	have := environLookup(base)
+	out := append([]string(nil), base...)
+	tools, _ := scrubEnv(childEnviron(), toolchainEnvNames())
+	for _, kv := range tools {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := have(name); !ok {
+			out = append(out, kv)
+		}
+	}
+	return out
+}
+
+func envAllowlist(settings config.Settings) []string {
+	if len(settings.EnvAllowlist) > 0 {
+		return settings.EnvAllowlist
+	}
+	return defaultEnvAllowlist()
+}
+
+// childEnviron is gixt's environment as passed to gists, without the secret store passphrase.
+func childEnviron() []string {
+	env := os.Environ()
+	out := env[:0:0]
+	for _, kv := range env {
+		if !strings.HasPrefix(kv, secret.PassphraseEnv+"=") {
+			out = append(out, kv)
+		}
+	}
+	return out
+}
+
+// scrubEnv keeps the KEY=VALUE entries of env whose names are allowed and returns the names
+// of the others, sorted.
+func scrubEnv(env []string, allow []string) ([]string, []string) {
+	kept := []string{}
+	var withheld []string
+	for _, kv := range env {
+		name, _, _ := strings.Cut(kv, "=")
+		if envAllowed(name, allow) {
+			kept = append(kept, kv)
+		} else if name != "" {
+			withheld = append(withheld, name)
+		}
+	}
+	sort.Strings(withheld)
+	return kept, withheld
+}
+
+// envAllowed reports whether name matches an allowlist entry: an exact name, or a prefix
+// followed by *. Names are compared case-insensitively on Windows.
+func envAllowed(name string, allow []string) bool {
+	for _, a := range allow {
+		n := name
+		if runtime.GOOS == "windows" {
+			n, a = strings.ToUpper(n), strings.ToUpper(a)
+		}
+		if prefix, ok := strings.CutSuffix(a, "*"); ok {
+			if strings.HasPrefix(n, prefix) {
+				return true
+			}
+		} else if n == a {
+			return true
+		}
+	}
+	return false
+}
+
+// environLookup looks variables up in a KEY=VALUE list, so ${VAR} references and
+// required_env only see what the gist will receive.
+func environLookup(env []string) envvar.Lookup {
+	vars := make(map[string]string, len(env))
+	for _, kv := range env {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			if runtime.GOOS == "windows" {
+				name = strings.ToUpper(name)
+			}
+			vars[name] = value
+		}
+	}
+	return func(name string) (string, bool) {
+		if runtime.GOOS == "windows" {
+			name = strings.ToUpper(name)
+		}
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+func handleConfigEnv(clean string, allow []string, disallow []string, resetAllow, show bool) error {
+	paths, settings, err := ensurePathsAndSettings("")
+	if err != nil {
+		return err
+	}
+	if resetAllow && (len(allow) > 0 || len(disallow) > 0) {
+		return errors.New("use --reset-allow on its own")
+	}
+
+	changed := false
+	if clean != "" {
+		scope, err := parseTrustScope(clean)
+		if err != nil {
+			return fmt.Errorf("unknown clean env mode %s (expected off|untrusted|all)", clean)
+		}
+		settings.CleanEnv = scope
+		changed = true
+	}
+	if resetAllow {
+		settings.EnvAllowlist = nil
+		changed = true
+	}
+	if len(allow) > 0 || len(disallow) > 0 {
+		list := slices.Clone(envAllowlist(settings))
+		for _, a := range allow {
+			if !envvar.ValidName(strings.TrimSuffix(a, "*")) {
+				return fmt.Errorf("%q is not a variable name or NAME* prefix", a)
+			}
+			if !slices.Contains(list, a) {
+				list = append(list, a)
+			}
+		}
+		var next []string
+		for _, a := range list {
+			if !slices.Contains(disallow, a) {
+				next = append(next, a)
+			}
+		}
+		if len(next) == 0 {
+			return errors.New("the allowlist cannot be empty (a gist needs at least PATH)")
+		}
+		settings.EnvAllowlist = next
+		changed = true
+	}
+	if changed {
+		if err := config.SaveSettings(paths.Settings, settings); err != nil {
+			return err
+		}
+	}
+
+	if show || changed {
+		mode := settings.CleanEnv
+		if mode == "" {
+			mode = config.ScopeOff
+		}
+		fmt.Printf("Clean env: %s\n", mode)
+		source := ""
+		if len(settings.EnvAllowlist) == 0 {
+			source = " (default)"
+		}
+		fmt.Printf("Allowlist: %s%s\n", strings.Join(envAllowlist(settings), ", "), source)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"testing"
+
+	"github.com/leolaurindo/gixt/internal/config"
+)
+
+func TestScrubEnvKeepsAllowlistedNames(t *testing.T) {
+	env := []string{"PATH=/bin", "GH_TOKEN=x", "LC_ALL=C", "LC_CTYPE=C", "HOME=/home/a", "AWS_SECRET_ACCESS_KEY=y"}
+	kept, withheld := scrubEnv(env, []string{"PATH", "HOME", "LC_*"})
+	if want := []string{"PATH=/bin", "LC_ALL=C", "LC_CTYPE=C", "HOME=/home/a"}; !reflect.DeepEqual(kept, want) {
+		t.Fatalf("kept %v, want %v", kept, want)
+	}
+	if want := []string{"AWS_SECRET_ACCESS_KEY", "GH_TOKEN"}; !reflect.DeepEqual(withheld, want) {
+		t.Fatalf("withheld %v, want %v", withheld, want)
+	}
+}
+
+func TestToolchainGetsToolchainVariablesBack(t *testing.T) {
+	t.Setenv("GOCACHE", "/tmp/gocache")
+	t.Setenv("GOFLAGS", "-mod=mod")
+	gistEnv, _ := scrubEnv(childEnviron(), defaultEnvAllowlist())
+	conf := confinement{baseEnv: append(gistEnv, "GOFLAGS=-trimpath")}
+	if _, ok := environLookup(conf.environ())("GOCACHE"); ok {
+		t.Fatalf("the gist's clean environment should not keep GOCACHE")
+	}
+	lookup := environLookup(conf.toolchain().environ())
+	if v, _ := lookup("GOCACHE"); v != "/tmp/gocache" {
+		t.Fatalf("builds should get GOCACHE back, got %q", v)
+	}
+	if v, _ := lookup("GOFLAGS"); v != "-trimpath" {
+		t.Fatalf("a value already in the environment should win, got %q", v)
+	}
+}
+
+func TestInScopeByTrustReason(t *testing.T) {
+	cases := []struct {
+		mode   config.TrustScope
+		reason trustReason
+		want   bool
+	}{
+		{config.ScopeOff, untrusted, false},
+		{config.ScopeUntrusted, untrusted, true},
+		{config.ScopeUntrusted, trustedByMode, true},
+		{config.ScopeUntrusted, trustedOwner, false},
+		{config.ScopeUntrusted, trustedGist, false},
+		{config.ScopeAll, trustedGist, true},
+	}
+	for _, c := range cases {
+		if got := inScope(c.mode, c.reason); got != c.want {
+			t.Errorf("inScope(%s, %d) = %v, want %v", c.mode, c.reason, got, c.want)
+		}
+	}
+}
+
+func TestCleanEnvWithholdsCallerVariables(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"deadbeefcafe","owner":{"login":"alice"},"files":{
+			"gixt.json":{"filename":"gixt.json","content":"{\"run\":\"test -z \\\"$GIXT_TEST_LEAK\\\" && test \\\"$FROM_MANIFEST\\\" = kept && test -n \\\"$PATH\\\"\",\"env\":{\"FROM_MANIFEST\":\"kept${GIXT_TEST_LEAK}\"}}"}},"history":[{"version":"rev1"}]}`)
+	})
+	t.Setenv("GIXT_TEST_LEAK", "leaked")
+	ctx := context.Background()
+	opts := runOptions{cacheDir: t.TempDir(), manifestFile: "gixt.json", isolate: true, yes: true}
+
+	if got := ExitCode(runWithOptions(ctx, opts, "deadbeefcafe", nil)); got != 1 {
+		t.Fatalf("without --clean-env the gist should see the variable, got exit %d", got)
+	}
+	opts.cleanEnv = true
+	if err := runWithOptions(ctx, opts, "deadbeefcafe", nil); err != nil {
+		t.Fatalf("run with --clean-env: %v", err)
+	}
+}
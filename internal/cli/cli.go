@@ -10,6 +10,8 @@ import (
 	ucli "github.com/urfave/cli/v2"
 
 	"github.com/leolaurindo/gixt/internal/config"
+	"github.com/leolaurindo/gixt/internal/gist"
+	"github.com/leolaurindo/gixt/internal/rlimit"
 	"github.com/leolaurindo/gixt/internal/version"
 )
 
@@ -26,6 +28,14 @@ const (
 )
 
 func Execute(ctx context.Context, args []string) error {
+	if len(args) > 0 {
+		switch args[0] {
+		case sandboxHelperCommand:
+			return runSandboxHelper(args[1:])
+		case limitsHelperCommand:
+			return runLimitsHelper(args[1:])
+		}
+	}
 	app := newApp()
 	return app.RunContext(ctx, append([]string{commandName}, args...))
 }
@@ -45,8 +55,7 @@ func newApp() *ucli.App {
 		CommandNotFound: func(c *ucli.Context, name string) {
 			args := append([]string{name}, c.Args().Slice()...)
 			if err := runAction(c, args); err != nil {
-				PrintError(err)
-				os.Exit(1)
+				Exit(err)
 			}
 		},
 		Commands: []*ucli.Command{
@@ -63,8 +72,9 @@ func newApp() *ucli.App {
 				Name:      "alias",
 				Usage:     "manage aliases",
 				ArgsUsage: "add <name> <gist-id> | list | remove <name>",
+				Flags:     []ucli.Flag{hostFlag()},
 				Action: func(c *ucli.Context) error {
-					return handleAlias(c.Args().Slice())
+					return handleAlias(c.Args().Slice(), c.String("host"))
 				},
 			},
 			{
@@ -77,8 +87,9 @@ func newApp() *ucli.App {
 			{
 				Name:  "index-mine",
 				Usage: "refresh friendly-name index for your user",
+				Flags: []ucli.Flag{hostFlag()},
 				Action: func(c *ucli.Context) error {
-					return handleIndexMine(c.Context)
+					return handleIndexMine(c.Context, c.String("host"))
 				},
 			},
 			{
@@ -91,6 +102,55 @@ func newApp() *ucli.App {
 					return handleCleanCache(c.String("cache-dir"))
 				},
 			},
+			{
+				Name:      "search",
+				Usage:     "fuzzy-search indexed gists by filename, description, owner, and manifest details",
+				ArgsUsage: "<query>",
+				Flags: []ucli.Flag{
+					&ucli.StringFlag{Name: "owner", Usage: "only show gists from this owner"},
+					&ucli.IntFlag{Name: "limit", Value: 10, Usage: "maximum results (0 for all)"},
+					&ucli.BoolFlag{Name: "json", Usage: "output results as JSON"},
+				},
+				Action: func(c *ucli.Context) error {
+					return handleSearch(strings.Join(c.Args().Slice(), " "), c.String("owner"), c.Int("limit"), c.Bool("json"))
+				},
+			},
+			{
+				Name:  "cache",
+				Usage: "manage cached gist revisions",
+				Subcommands: []*ucli.Command{
+					{
+						Name:  "prune",
+						Usage: "delete cached revisions by age, count or total size, plus leftover temp dirs",
+						Flags: []ucli.Flag{
+							&ucli.StringFlag{Name: "older-than", Usage: "remove revisions not used within this age (e.g. 30d, 2w, 12h)"},
+							&ucli.IntFlag{Name: "keep-latest", Usage: "keep only the N most recently used revisions of each gist"},
+							&ucli.StringFlag{Name: "max-size", Usage: "remove least recently used revisions until the cache fits (e.g. 500MB)"},
+							&ucli.BoolFlag{Name: "dry-run", Usage: "list what would be removed without deleting"},
+							&ucli.StringFlag{Name: "cache-dir", Usage: "override cache dir"},
+						},
+						Action: func(c *ucli.Context) error {
+							return handleCachePrune(pruneOpts{
+								olderThan:  c.String("older-than"),
+								keepLatest: c.Int("keep-latest"),
+								maxSize:    c.String("max-size"),
+								dryRun:     c.Bool("dry-run"),
+								cacheDir:   c.String("cache-dir"),
+							})
+						},
+					},
+					{
+						Name:  "envs",
+						Usage: "list the Python virtualenvs and node_modules created for cached revisions",
+						Flags: []ucli.Flag{
+							&ucli.StringFlag{Name: "cache-dir", Usage: "override cache dir"},
+						},
+						Action: func(c *ucli.Context) error {
+							return handleCacheEnvs(c.String("cache-dir"))
+						},
+					},
+				},
+			},
 			{
 				Name:  "remove",
 				Usage: "remove specific gists from cache and/or index",
@@ -100,6 +160,7 @@ func newApp() *ucli.App {
 					&ucli.StringSliceFlag{Name: "cache-index", Usage: "remove these gists from both cache and index (id|name|owner/name)"},
 					&ucli.StringSliceFlag{Name: "owner", Usage: "remove all cached/indexed gists for these owners"},
 					&ucli.StringFlag{Name: "cache-dir", Usage: "override cache dir"},
+					hostFlag(),
 				},
 				Action: func(c *ucli.Context) error {
 					return handleRemove(
@@ -109,6 +170,7 @@ func newApp() *ucli.App {
 						c.StringSlice("cache-index"),
 						c.StringSlice("owner"),
 						c.String("cache-dir"),
+						c.String("host"),
 					)
 				},
 			},
@@ -118,20 +180,39 @@ func newApp() *ucli.App {
 				Flags: []ucli.Flag{
 					&ucli.BoolFlag{Name: "cache", Aliases: []string{"c"}, Usage: "show cached gists only"},
 					&ucli.BoolFlag{Name: "mine", Usage: "filter to gists owned by the authenticated user"},
+					hostFlag(),
+				},
+				Action: func(c *ucli.Context) error {
+					return handleList(c.Context, c.Bool("cache"), c.Bool("mine"), c.String("host"))
+				},
+			},
+			{
+				Name:      "scan",
+				Usage:     "list risky patterns in a gist's files without running it",
+				ArgsUsage: "<gist-id|url|alias|name|owner/name>",
+				Flags: []ucli.Flag{
+					&ucli.StringFlag{Name: "ref", Usage: "scan a specific gist ref"},
+					&ucli.StringFlag{Name: "cache-dir", Usage: "override cache directory"},
+					&ucli.BoolFlag{Name: "offline", Usage: "scan the newest cached revision without contacting GitHub"},
+					hostFlag(),
 				},
 				Action: func(c *ucli.Context) error {
-					return handleList(c.Context, c.Bool("cache"), c.Bool("mine"))
+					if c.Args().Len() == 0 {
+						return errors.New("usage: gixt scan <gist-id|url|alias|name|owner/name>")
+					}
+					return handleScan(c.Context, c.Args().First(), c.String("ref"), c.String("cache-dir"), c.String("host"), c.Bool("offline"))
 				},
 			},
 			{
 				Name:      "describe",
 				Usage:     "show description for a gist",
 				ArgsUsage: "<gist-id|url|alias|name|owner/name>",
+				Flags:     []ucli.Flag{hostFlag()},
 				Action: func(c *ucli.Context) error {
 					if c.Args().Len() == 0 {
 						return errors.New("usage: gixt describe <gist-id|url|alias|name|owner/name>")
 					}
-					return handleDescribe(c.Context, c.Args().First())
+					return handleDescribe(c.Context, c.Args().First(), c.String("host"))
 				},
 			},
 			{
@@ -141,6 +222,7 @@ func newApp() *ucli.App {
 				Flags: []ucli.Flag{
 					&ucli.StringFlag{Name: "gist", Usage: "target gist (id|name|owner/name)"},
 					&ucli.StringFlag{Name: "description", Usage: "description text"},
+					hostFlag(),
 				},
 				Action: func(c *ucli.Context) error {
 					desc := strings.TrimSpace(c.String("description"))
@@ -150,7 +232,7 @@ func newApp() *ucli.App {
 						return errors.New("usage: gixt set-description --description \"<text>\" --gist <gist-id|url|alias|name|owner/name>")
 					}
 
-					return handleSetDescription(c.Context, target, desc)
+					return handleSetDescription(c.Context, target, desc, c.String("host"))
 				},
 			},
 			{
@@ -158,6 +240,7 @@ func newApp() *ucli.App {
 				Usage: "configure trust policy",
 				Flags: []ucli.Flag{
 					&ucli.StringFlag{Name: "mode", Usage: "trust mode: never|mine|all"},
+					&ucli.StringFlag{Name: "pin", Usage: "pin trust to approved revisions: off|gists|all (all includes trusted owners)"},
 					&ucli.StringSliceFlag{Name: "owner", Usage: "trust this owner (repeatable)"},
 					&ucli.StringSliceFlag{Name: "trust-owner", Usage: "alias for --owner"},
 					&ucli.StringSliceFlag{Name: "remove-owner", Usage: "remove this owner from trusted list (repeatable)"},
@@ -166,6 +249,7 @@ func newApp() *ucli.App {
 					&ucli.BoolFlag{Name: "clear-gists", Usage: "clear per-gist trust"},
 					&ucli.BoolFlag{Name: "reset", Usage: "clear all trust and return to mode=never"},
 					&ucli.BoolFlag{Name: "show", Usage: "show current trust config"},
+					hostFlag(),
 				},
 				Action: func(c *ucli.Context) error {
 					owners := append([]string{}, c.StringSlice("owner")...)
@@ -173,6 +257,7 @@ func newApp() *ucli.App {
 					return handleConfigTrust(
 						c.Context,
 						c.String("mode"),
+						c.String("pin"),
 						owners,
 						c.StringSlice("remove-owner"),
 						c.StringSlice("remove-gist"),
@@ -180,6 +265,7 @@ func newApp() *ucli.App {
 						c.Bool("clear-gists"),
 						c.Bool("reset"),
 						c.Bool("show"),
+						c.String("host"),
 					)
 				},
 			},
@@ -199,13 +285,14 @@ func newApp() *ucli.App {
 				ArgsUsage: "--owner <login>",
 				Flags: []ucli.Flag{
 					&ucli.StringFlag{Name: "owner", Usage: "owner login whose gists to index"},
+					hostFlag(),
 				},
 				Action: func(c *ucli.Context) error {
 					owner := c.String("owner")
 					if owner == "" && c.Args().Len() > 0 {
 						owner = c.Args().First()
 					}
-					return handleIndexOwner(c.Context, owner)
+					return handleIndexOwner(c.Context, owner, c.String("host"))
 				},
 			},
 			{
@@ -215,12 +302,13 @@ func newApp() *ucli.App {
 					&ucli.StringFlag{Name: "ref", Usage: "pin to specific ref when caching"},
 					&ucli.StringFlag{Name: "cache-dir", Usage: "override cache dir"},
 					&ucli.BoolFlag{Name: "update", Usage: "force re-download even if cached"},
+					hostFlag(),
 				},
 				Action: func(c *ucli.Context) error {
 					if c.Args().Len() == 0 {
 						return errors.New("usage: gixt register <gist-id|url> [--ref <sha>]")
 					}
-					return handleRegister(c.Context, c.Args().First(), c.String("ref"), c.String("cache-dir"), c.Bool("update"))
+					return handleRegister(c.Context, c.Args().First(), c.String("ref"), c.String("cache-dir"), c.Bool("update"), c.String("host"))
 				},
 			},
 			{
@@ -245,6 +333,84 @@ func newApp() *ucli.App {
 					return handleConfigExec(c.String("mode"), c.Bool("show"))
 				},
 			},
+			{
+				Name:      "config-interpreter",
+				Usage:     "configure the commands that run gist files by extension",
+				ArgsUsage: "[--ext .lua --cmd luajit --cmd lua5.4]",
+				Flags: []ucli.Flag{
+					&ucli.StringFlag{Name: "ext", Usage: "file extension to configure (e.g. .py)"},
+					&ucli.StringSliceFlag{Name: "cmd", Usage: "candidate command, tried in the order given (repeatable)"},
+					&ucli.BoolFlag{Name: "remove", Usage: "remove the configured commands for --ext"},
+					&ucli.BoolFlag{Name: "show", Usage: "show configured and built-in interpreters and which one is found"},
+				},
+				Action: func(c *ucli.Context) error {
+					return handleConfigInterpreter(c.String("ext"), c.StringSlice("cmd"), c.Bool("remove"), c.Bool("show"))
+				},
+			},
+			{
+				Name:  "config-env",
+				Usage: "configure which gists run with a clean environment and what it keeps",
+				Flags: []ucli.Flag{
+					&ucli.StringFlag{Name: "clean", Usage: "off|untrusted|all (untrusted: gists not trusted by ID, owner or mode mine)"},
+					&ucli.StringSliceFlag{Name: "allow", Usage: "keep this variable, or NAME* prefix, in a clean environment (repeatable)"},
+					&ucli.StringSliceFlag{Name: "disallow", Usage: "drop an entry from the allowlist (repeatable)"},
+					&ucli.BoolFlag{Name: "reset-allow", Usage: "restore the default allowlist"},
+					&ucli.BoolFlag{Name: "show", Usage: "show the clean env mode and allowlist"},
+				},
+				Action: func(c *ucli.Context) error {
+					return handleConfigEnv(c.String("clean"), c.StringSlice("allow"), c.StringSlice("disallow"), c.Bool("reset-allow"), c.Bool("show"))
+				},
+			},
+			{
+				Name:  "config-sandbox",
+				Usage: "configure which gists run in the Linux namespace sandbox",
+				Flags: []ucli.Flag{
+					&ucli.StringFlag{Name: "mode", Usage: "off|untrusted|all (untrusted: gists not trusted by ID, owner or mode mine)"},
+					&ucli.BoolFlag{Name: "show", Usage: "show the sandbox mode and whether this system supports it"},
+				},
+				Action: func(c *ucli.Context) error {
+					return handleConfigSandbox(c.String("mode"), c.Bool("show"))
+				},
+			},
+			{
+				Name:  "config-limits",
+				Usage: "set default resource limits for gists (Linux)",
+				Flags: []ucli.Flag{
+					&ucli.StringFlag{Name: "mem", Usage: "address space, e.g. 1G (none to clear)"},
+					&ucli.StringFlag{Name: "cpu", Usage: "CPU time, e.g. 5m (none to clear)"},
+					&ucli.StringFlag{Name: "fsize", Usage: "largest file written, e.g. 1G (none to clear)"},
+					&ucli.IntFlag{Name: "procs", Usage: "processes your user may have (0 to clear)"},
+					&ucli.BoolFlag{Name: "reset", Usage: "clear all default limits"},
+					&ucli.BoolFlag{Name: "show", Usage: "show the default limits"},
+				},
+				Action: func(c *ucli.Context) error {
+					set := rlimit.Spec{Memory: c.String("mem"), CPU: c.String("cpu"), FileSize: c.String("fsize"), Processes: c.Int("procs")}
+					return handleConfigLimits(set, c.IsSet("procs"), c.Bool("reset"), c.Bool("show"))
+				},
+			},
+			{
+				Name:  "config-scan",
+				Usage: "choose which gists are refused when the risk scan finds high-severity patterns",
+				Flags: []ucli.Flag{
+					&ucli.StringFlag{Name: "block", Usage: "off|untrusted|all (untrusted: gists not trusted by ID, owner or mode mine)"},
+					&ucli.BoolFlag{Name: "show", Usage: "show the scan block mode"},
+				},
+				Action: func(c *ucli.Context) error {
+					return handleConfigScan(c.String("block"), c.Bool("show"))
+				},
+			},
+			{
+				Name:  "config-api",
+				Usage: "configure how gixt talks to the GitHub API",
+				Flags: []ucli.Flag{
+					&ucli.StringFlag{Name: "mode", Usage: "gh|http (http needs GH_TOKEN/GITHUB_TOKEN or a gh login)"},
+					&ucli.StringFlag{Name: "host", Usage: "default GitHub host for bare IDs and names (e.g. github.example.com)"},
+					&ucli.BoolFlag{Name: "show", Usage: "show current api backend and host"},
+				},
+				Action: func(c *ucli.Context) error {
+					return handleConfigAPI(c.String("mode"), c.String("host"), c.Bool("show"))
+				},
+			},
 			{
 				Name:  "check-updates",
 				Usage: "check if a newer gixt release is available",
@@ -261,12 +427,13 @@ func newApp() *ucli.App {
 				ArgsUsage: "<gist-id|url|alias|name|owner/name>",
 				Flags: []ucli.Flag{
 					&ucli.StringFlag{Name: "dir", Usage: "target directory (default: gist id)"},
+					hostFlag(),
 				},
 				Action: func(c *ucli.Context) error {
 					if c.Args().Len() == 0 {
 						return errors.New("usage: gixt clone <gist-id|url|alias|name|owner/name> [--dir <path>]")
 					}
-					return handleClone(c.Context, c.Args().First(), c.String("dir"))
+					return handleClone(c.Context, c.Args().First(), c.String("dir"), c.String("host"))
 				},
 			},
 			{
@@ -276,12 +443,155 @@ func newApp() *ucli.App {
 				Flags: []ucli.Flag{
 					&ucli.BoolFlag{Name: "public", Usage: "create fork as public (default private)"},
 					&ucli.StringFlag{Name: "description", Usage: "description for new gist"},
+					hostFlag(),
 				},
 				Action: func(c *ucli.Context) error {
 					if c.Args().Len() == 0 {
 						return errors.New("usage: gixt fork <gist-id|url|alias|name|owner/name> [--public] [--description <desc>]")
 					}
-					return handleFork(c.Context, c.Args().First(), c.Bool("public"), c.String("description"))
+					return handleFork(c.Context, c.Args().First(), c.Bool("public"), c.String("description"), c.String("host"))
+				},
+			},
+			{
+				Name:  "lock",
+				Usage: "pin gists to exact revisions in gixt.lock",
+				Subcommands: []*ucli.Command{
+					{
+						Name:      "add",
+						Usage:     "pin gists at their latest (or --ref) revision",
+						ArgsUsage: "<gist>...",
+						Flags: []ucli.Flag{
+							&ucli.StringFlag{Name: "ref", Usage: "pin this revision instead of the latest"},
+							hostFlag(),
+						},
+						Action: func(c *ucli.Context) error {
+							return handleLockAdd(c.Context, c.Args().Slice(), c.String("ref"), c.String("host"))
+						},
+					},
+					{
+						Name:      "update",
+						Usage:     "move pins to the latest revision (all gists when none are named)",
+						ArgsUsage: "[<gist>...]",
+						Flags: []ucli.Flag{
+							hostFlag(),
+						},
+						Action: func(c *ucli.Context) error {
+							return handleLockUpdate(c.Context, c.Args().Slice(), c.String("host"))
+						},
+					},
+					{
+						Name:  "verify",
+						Usage: "check that pinned gists materialize to the recorded file hashes",
+						Flags: []ucli.Flag{
+							&ucli.StringFlag{Name: "cache-dir", Usage: "override cache dir"},
+							hostFlag(),
+						},
+						Action: func(c *ucli.Context) error {
+							return handleLockVerify(c.Context, c.String("cache-dir"), c.String("host"))
+						},
+					},
+				},
+			},
+			{
+				Name:  "secret",
+				Usage: "store credentials that gists declaring them in `secrets` receive",
+				Subcommands: []*ucli.Command{
+					{
+						Name:      "set",
+						Usage:     "store a secret (the value is read from a hidden prompt or stdin)",
+						ArgsUsage: "NAME",
+						Flags: []ucli.Flag{
+							&ucli.StringFlag{Name: "value", Usage: "the value (visible in shell history; prefer the prompt or stdin)"},
+						},
+						Action: func(c *ucli.Context) error {
+							if c.Args().Len() != 1 {
+								return errors.New("usage: gixt secret set NAME [--value <value>]")
+							}
+							return handleSecretSet(c.Args().First(), c.String("value"), c.IsSet("value"))
+						},
+					},
+					{
+						Name:      "get",
+						Usage:     "print a secret's value",
+						ArgsUsage: "NAME",
+						Action: func(c *ucli.Context) error {
+							if c.Args().Len() != 1 {
+								return errors.New("usage: gixt secret get NAME")
+							}
+							return handleSecretGet(c.Args().First())
+						},
+					},
+					{
+						Name:  "list",
+						Usage: "list secret names",
+						Action: func(c *ucli.Context) error {
+							return handleSecretList()
+						},
+					},
+					{
+						Name:      "rm",
+						Usage:     "delete secrets",
+						ArgsUsage: "NAME...",
+						Action: func(c *ucli.Context) error {
+							return handleSecretRemove(c.Args().Slice())
+						},
+					},
+					{
+						Name:  "encrypt",
+						Usage: "encrypt the store with a passphrase, or change the passphrase",
+						Action: func(c *ucli.Context) error {
+							return handleSecretEncrypt()
+						},
+					},
+					{
+						Name:  "decrypt",
+						Usage: "store secrets unencrypted, protected by file permissions only",
+						Action: func(c *ucli.Context) error {
+							return handleSecretDecrypt()
+						},
+					},
+				},
+			},
+			{
+				Name:      "install",
+				Usage:     "install a gist as a command on PATH",
+				ArgsUsage: "<gist-id|url|alias|name|owner/name> [--as <cmd>] [--bin-dir <dir>] [--ref <sha>] [run flags...]",
+				Flags: []ucli.Flag{
+					&ucli.StringFlag{Name: "as", Usage: "command name (default: the gist's friendly name)"},
+					&ucli.StringFlag{Name: "bin-dir", Usage: "directory for the launcher (default ~/.local/bin)"},
+					&ucli.StringFlag{Name: "ref", Usage: "pin the launcher to a specific gist ref"},
+					hostFlag(),
+				},
+				Action: func(c *ucli.Context) error {
+					opts := installOpts{
+						as:     c.String("as"),
+						binDir: c.String("bin-dir"),
+						ref:    c.String("ref"),
+						host:   c.String("host"),
+					}
+					if err := parseInstallArgs(c.Args().Slice(), &opts); err != nil {
+						return err
+					}
+					return handleInstall(c.Context, opts)
+				},
+			},
+			{
+				Name:  "installed",
+				Usage: "list gists installed as commands",
+				Action: func(c *ucli.Context) error {
+					return handleInstalled()
+				},
+			},
+			{
+				Name:      "uninstall",
+				Usage:     "remove commands created by gixt install",
+				ArgsUsage: "<cmd>...",
+				Flags: []ucli.Flag{
+					&ucli.BoolFlag{Name: "force", Usage: "remove launchers even if they were edited after install"},
+					&ucli.StringFlag{Name: "bin-dir", Usage: "only remove launchers installed in this directory"},
+				},
+				Action: func(c *ucli.Context) error {
+					return handleUninstall(c.Args().Slice(), c.String("bin-dir"), c.Bool("force"))
 				},
 			},
 			{
@@ -300,6 +610,7 @@ func newApp() *ucli.App {
 					&ucli.StringFlag{Name: "details", Usage: "manifest details/docstring"},
 					&ucli.StringFlag{Name: "version", Usage: "manifest version"},
 					&ucli.BoolFlag{Name: "force", Usage: "skip overwrite confirmation"},
+					hostFlag(),
 				},
 				Action: func(c *ucli.Context) error {
 					opts := manifestOpts{
@@ -314,6 +625,7 @@ func newApp() *ucli.App {
 						details: c.String("details"),
 						version: c.String("version"),
 						force:   c.Bool("force"),
+						host:    c.String("host"),
 					}
 					if err := applyManifestArgs(c.Args().Slice(), &opts); err != nil {
 						return err
@@ -349,10 +661,27 @@ func runAction(c *ucli.Context, args []string) error {
 		isolate:        c.Bool("isolate"),
 		cwd:            c.Bool("cwd"),
 		timeout:        c.Duration("timeout"),
+		killGrace:      c.Duration("kill-grace"),
 		yes:            c.Bool("yes"),
 		trustAlways:    c.Bool("trust-always"),
 		trustAll:       c.Bool("trust-all"),
 		ignoreManifest: c.Bool("ignore-manifest"),
+		host:           c.String("host"),
+		noLock:         c.Bool("no-lock"),
+		offline:        c.Bool("offline"),
+		noDeps:         c.Bool("no-deps"),
+		rebuild:        c.Bool("rebuild"),
+		file:           c.String("file"),
+		env:            c.StringSlice("env"),
+		envFiles:       c.StringSlice("env-file"),
+		cleanEnv:       c.Bool("clean-env"),
+		sandbox:        c.Bool("sandbox"),
+		limits: rlimit.Spec{
+			Memory:    c.String("limit-mem"),
+			CPU:       c.String("limit-cpu"),
+			FileSize:  c.String("limit-fsize"),
+			Processes: c.Int("limit-procs"),
+		},
 	}
 
 	opts.userPages = normalizeUserPages(opts.userPages)
@@ -381,13 +710,32 @@ func runFlags() []ucli.Flag {
 		&ucli.BoolFlag{Name: "isolate", Usage: "run in an isolated work dir instead of current directory"},
 		&ucli.BoolFlag{Name: "cwd", Aliases: []string{"here"}, Usage: "run in current working directory (overrides execution mode)"},
 		&ucli.DurationFlag{Name: "timeout", Usage: "timeout for gist execution (e.g. 30s, 2m)"},
+		&ucli.DurationFlag{Name: "kill-grace", Value: defaultKillGrace, Usage: "after --timeout, wait this long between SIGTERM and SIGKILL"},
 		&ucli.BoolFlag{Name: "yes", Aliases: []string{"y"}, Usage: "skip trust prompt"},
 		&ucli.BoolFlag{Name: "trust-always", Usage: "trust this gist permanently"},
 		&ucli.BoolFlag{Name: "trust-all", Usage: "trust all gists permanently"},
 		&ucli.BoolFlag{Name: "ignore-manifest", Usage: "skip manifest for this run"},
+		&ucli.BoolFlag{Name: "no-lock", Usage: "ignore gixt.lock pins for this run"},
+		&ucli.BoolFlag{Name: "offline", Usage: "run the newest cached revision without contacting GitHub"},
+		&ucli.StringSliceFlag{Name: "env", Usage: "set KEY=VALUE for the gist, over the manifest env (repeatable)"},
+		&ucli.StringSliceFlag{Name: "env-file", Usage: "read KEY=VALUE lines from a .env file, over the manifest env (repeatable)"},
+		&ucli.BoolFlag{Name: "clean-env", Usage: "pass the gist only allowlisted variables (PATH, HOME, LANG, TERM by default) plus the manifest env"},
+		&ucli.BoolFlag{Name: "sandbox", Usage: "Linux: run the gist with a read-only file system outside its work dir and no network, unless its manifest permissions allow"},
+		&ucli.StringFlag{Name: "limit-mem", Usage: "Linux: cap the gist's address space (e.g. 512M, 2G)"},
+		&ucli.StringFlag{Name: "limit-cpu", Usage: "Linux: cap the gist's CPU time (e.g. 30s, 5m)"},
+		&ucli.StringFlag{Name: "limit-fsize", Usage: "Linux: cap the size of files the gist writes (e.g. 100M)"},
+		&ucli.IntFlag{Name: "limit-procs", Usage: "Linux: cap the processes your user may have while the gist runs"},
+		&ucli.StringFlag{Name: "file", Usage: "run this gist file by its shebang or extension instead of the manifest command"},
+		&ucli.BoolFlag{Name: "rebuild", Usage: "recompile Go, Rust and C/C++ gists instead of reusing the cached binary"},
+		&ucli.BoolFlag{Name: "no-deps", Usage: "do not create a virtualenv or node_modules from requirements.txt, script metadata or package.json"},
+		hostFlag(),
 	}
 }
 
+func hostFlag() *ucli.StringFlag {
+	return &ucli.StringFlag{Name: "host", Usage: "GitHub host for bare IDs and names (default: settings, then github.com)"}
+}
+
 func colorize(s, code string) string {
 	if code == "" {
 		return s
@@ -428,3 +776,54 @@ func ensurePathsAndSettings(cacheOverride string) (config.Paths, config.Settings
 	}
 	return paths, settings, nil
 }
+
+// newGistClient builds the API client for host using the backend selected in settings.
+// Tests replace it to point handlers at an httptest server.
+var newGistClient = func(settings config.Settings, host string) (gist.Client, error) {
+	switch settings.APIBackend {
+	case config.APIBackendHTTP:
+		return gist.NewHTTPClient(gist.APIURL(host), gist.DiscoverToken(host)), nil
+	default:
+		return gist.NewGHClient(host), nil
+	}
+}
+
+// activeHost picks the GitHub host for bare IDs and names: the --host flag, then settings.
+func activeHost(settings config.Settings, hostFlag string) string {
+	if strings.TrimSpace(hostFlag) != "" {
+		return gist.NormalizeHost(hostFlag)
+	}
+	return gist.NormalizeHost(settings.Host)
+}
+
+// clientForPaths loads settings and returns the active host along with its API client.
+func clientForPaths(paths config.Paths, hostFlag string) (gist.Client, string, error) {
+	settings, err := config.LoadSettings(paths.Settings)
+	if err != nil {
+		return nil, "", err
+	}
+	host := activeHost(settings, hostFlag)
+	client, err := newGistClient(settings, host)
+	if err != nil {
+		return nil, "", err
+	}
+	return client, host, nil
+}
+
+// clientForKey returns a client for the host encoded in a gist key, reusing current when
+// the key lives on the active host.
+func clientForKey(paths config.Paths, current gist.Client, currentHost string, key string) (gist.Client, string, error) {
+	host, id := gist.SplitKey(key)
+	if host == currentHost && current != nil {
+		return current, id, nil
+	}
+	settings, err := config.LoadSettings(paths.Settings)
+	if err != nil {
+		return nil, "", err
+	}
+	client, err := newGistClient(settings, host)
+	if err != nil {
+		return nil, "", err
+	}
+	return client, id, nil
+}
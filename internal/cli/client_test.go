@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/leolaurindo/gixt/internal/cache"
+	"github.com/leolaurindo/gixt/internal/config"
+	"github.com/leolaurindo/gixt/internal/gist"
+)
+
+// useTestServer points the CLI handlers at an httptest server and isolates config/cache dirs.
+func useTestServer(t *testing.T, handler http.HandlerFunc) string {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, "config"))
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "cache"))
+	t.Setenv("AppData", filepath.Join(home, "config"))
+	t.Setenv("LocalAppData", filepath.Join(home, "cache"))
+
+	orig := newGistClient
+	newGistClient = func(config.Settings, string) (gist.Client, error) {
+		return gist.NewHTTPClient(srv.URL, "test-token"), nil
+	}
+	t.Cleanup(func() { newGistClient = orig })
+	return home
+}
+
+func TestHandleRegisterAgainstHTTPServer(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/gists/deadbeefcafe" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"id":"deadbeefcafe","description":"hello","owner":{"login":"alice"},"files":{"hello.sh":{"filename":"hello.sh","content":"echo hi"}},"history":[{"version":"rev1"}]}`)
+	})
+	cacheDir := t.TempDir()
+
+	if err := handleRegister(context.Background(), "deadbeefcafe", "", cacheDir, false, ""); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	workDir := cache.Dir(cacheDir, "deadbeefcafe", "rev1")
+	m, err := cache.LoadManifest(cache.ManifestPath(workDir))
+	if err != nil {
+		t.Fatalf("load manifest: %v", err)
+	}
+	if m.Owner != "alice" || len(m.Files) != 1 || m.Files[0] != "hello.sh" {
+		t.Fatalf("unexpected manifest %+v", m)
+	}
+	data, err := os.ReadFile(filepath.Join(workDir, "hello.sh"))
+	if err != nil || string(data) != "echo hi" {
+		t.Fatalf("unexpected cached file: %q (%v)", data, err)
+	}
+
+	if err := handleRegister(context.Background(), "0123456789ab", "", cacheDir, false, ""); !gist.IsNotFound(err) {
+		t.Fatalf("expected not found for unknown gist, got %v", err)
+	}
+}
+
+func TestHandleRegisterEnterpriseHost(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"deadbeefcafe","owner":{"login":"alice"},"files":{"hello.sh":{"filename":"hello.sh","content":"echo hi"}},"history":[{"version":"rev1"}]}`)
+	})
+	var hosts []string
+	serverClient := newGistClient
+	newGistClient = func(s config.Settings, host string) (gist.Client, error) {
+		hosts = append(hosts, host)
+		return serverClient(s, host)
+	}
+	cacheDir := t.TempDir()
+
+	if err := handleRegister(context.Background(), "https://ghe.example.com/gist/alice/deadbeefcafe", "", cacheDir, false, ""); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	if len(hosts) == 0 || hosts[len(hosts)-1] != "ghe.example.com" {
+		t.Fatalf("expected client for ghe.example.com, got %v", hosts)
+	}
+	m, err := cache.LoadManifest(cache.ManifestPath(cache.Dir(cacheDir, "ghe.example.com/deadbeefcafe", "rev1")))
+	if err != nil {
+		t.Fatalf("load manifest: %v", err)
+	}
+	if m.Host != "ghe.example.com" || m.Key() != "ghe.example.com/deadbeefcafe" {
+		t.Fatalf("unexpected manifest host %+v", m)
+	}
+}
@@ -16,7 +16,7 @@ import (
 	"github.com/leolaurindo/gixt/internal/index"
 )
 
-func handleClone(ctx context.Context, target string, dir string) error {
+func handleClone(ctx context.Context, target string, dir string, hostFlag string) error {
 	if strings.TrimSpace(target) == "" {
 		return errors.New("usage: gixt clone <gist-id|url|alias|name|owner/name> [--dir <path>]")
 	}
@@ -24,11 +24,16 @@ func handleClone(ctx context.Context, target string, dir string) error {
 	if err != nil {
 		return err
 	}
+	client, activeHost, err := clientForPaths(paths, hostFlag)
+	if err != nil {
+		return err
+	}
 	aliases, _ := alias.Load(paths.AliasFile)
-	id, _, _, err := resolveIdentifier(ctx, target, aliases, paths, false, true, normalizeUserPages(0))
+	key, _, _, err := resolveIdentifier(ctx, client, activeHost, target, aliases, paths, false, true, normalizeUserPages(0))
 	if err != nil {
 		return err
 	}
+	host, id := gist.SplitKey(key)
 	dest := dir
 	if strings.TrimSpace(dest) == "" {
 		dest = id
@@ -37,6 +42,9 @@ func handleClone(ctx context.Context, target string, dir string) error {
 		return fmt.Errorf("target path %s already exists", dest)
 	}
 	cmd := exec.CommandContext(ctx, "gh", "gist", "clone", id, dest)
+	if !gist.IsDefaultHost(host) {
+		cmd.Env = append(os.Environ(), "GH_HOST="+host)
+	}
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 	cmd.Stdin = os.Stdin
@@ -47,7 +55,7 @@ func handleClone(ctx context.Context, target string, dir string) error {
 	return nil
 }
 
-func handleFork(ctx context.Context, target string, public bool, desc string) error {
+func handleFork(ctx context.Context, target string, public bool, desc string, hostFlag string) error {
 	if strings.TrimSpace(target) == "" {
 		return errors.New("usage: gixt fork <gist-id|url|alias|name|owner/name> [--public] [--description <desc>]")
 	}
@@ -55,13 +63,22 @@ func handleFork(ctx context.Context, target string, public bool, desc string) er
 	if err != nil {
 		return err
 	}
+	client, activeHost, err := clientForPaths(paths, hostFlag)
+	if err != nil {
+		return err
+	}
 	aliases, _ := alias.Load(paths.AliasFile)
-	id, _, _, err := resolveIdentifier(ctx, target, aliases, paths, false, true, normalizeUserPages(0))
+	key, _, _, err := resolveIdentifier(ctx, client, activeHost, target, aliases, paths, false, true, normalizeUserPages(0))
+	if err != nil {
+		return err
+	}
+	client, id, err := clientForKey(paths, client, activeHost, key)
 	if err != nil {
 		return err
 	}
+	host, _ := gist.SplitKey(key)
 
-	g, err := gist.Fetch(ctx, id, "")
+	g, err := client.Fetch(ctx, id, "")
 	if err != nil {
 		return err
 	}
@@ -74,7 +91,7 @@ func handleFork(ctx context.Context, target string, public bool, desc string) er
 		description = g.Description
 	}
 
-	newGist, err := gist.Create(ctx, files, description, public)
+	newGist, err := client.Create(ctx, files, description, public)
 	if err != nil {
 		return err
 	}
@@ -82,7 +99,7 @@ func handleFork(ctx context.Context, target string, public bool, desc string) er
 
 	// Update index with new gist
 	idx, _ := index.Load(paths.IndexFile)
-	idx.Entries = append(idx.Entries, toIndexEntryFromGist(newGist))
+	idx.Entries = append(idx.Entries, toIndexEntryFromGist(newGist, host))
 	sortIndexEntries(idx.Entries)
 	idx.GeneratedAt = newGist.UpdatedAt
 	_ = index.Save(paths.IndexFile, idx)
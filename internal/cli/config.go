@@ -2,14 +2,19 @@ package cli
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"sort"
 	"strings"
+	"text/tabwriter"
 
 	"github.com/leolaurindo/gixt/internal/config"
+	"github.com/leolaurindo/gixt/internal/gist"
+	"github.com/leolaurindo/gixt/internal/runner"
 )
 
-func handleConfigTrust(_ context.Context, mode string, owners []string, removeOwners []string, removeGists []string, clearOwners, clearGists, reset, show bool) error {
+func handleConfigTrust(_ context.Context, mode string, pin string, owners []string, removeOwners []string, removeGists []string, clearOwners, clearGists, reset, show bool, hostFlag string) error {
 	paths, settings, err := ensurePathsAndSettings("")
 	if err != nil {
 		return err
@@ -19,6 +24,7 @@ func handleConfigTrust(_ context.Context, mode string, owners []string, removeOw
 		settings.Mode = config.TrustNever
 		settings.TrustedOwners = map[string]bool{}
 		settings.TrustedGists = map[string]bool{}
+		settings.TrustedRevisions = nil
 		fmt.Printf("%scleared stored trust decisions (mode=never).%s\n", clrWarn, clrReset)
 	}
 	if settings.TrustedOwners == nil {
@@ -33,15 +39,34 @@ func handleConfigTrust(_ context.Context, mode string, owners []string, removeOw
 	}
 	if clearGists {
 		settings.TrustedGists = map[string]bool{}
+		settings.TrustedRevisions = nil
 	}
+	host := activeHost(settings, hostFlag)
 	for _, o := range owners {
-		settings.TrustedOwners[strings.ToLower(o)] = true
+		settings.TrustedOwners[ownerTrustKey(host, o)] = true
 	}
 	for _, o := range removeOwners {
-		delete(settings.TrustedOwners, strings.ToLower(o))
+		delete(settings.TrustedOwners, ownerTrustKey(host, o))
 	}
 	for _, g := range removeGists {
-		delete(settings.TrustedGists, strings.ToLower(g))
+		key := aliasKey(strings.ToLower(strings.TrimSpace(g)))
+		if !strings.Contains(key, "/") {
+			key = gist.Key(host, key)
+		}
+		delete(settings.TrustedGists, key)
+		delete(settings.TrustedRevisions, key)
+	}
+	if pin != "" {
+		switch strings.ToLower(pin) {
+		case string(config.TrustPinOff):
+			settings.TrustPin = config.TrustPinOff
+		case string(config.TrustPinGists):
+			settings.TrustPin = config.TrustPinGists
+		case string(config.TrustPinAll):
+			settings.TrustPin = config.TrustPinAll
+		default:
+			return fmt.Errorf("unknown pin mode %s (expected off|gists|all)", pin)
+		}
 	}
 	if mode != "" {
 		switch strings.ToLower(mode) {
@@ -59,9 +84,10 @@ func handleConfigTrust(_ context.Context, mode string, owners []string, removeOw
 		return err
 	}
 
-	if show || mode != "" || len(owners) > 0 || len(removeOwners) > 0 || len(removeGists) > 0 || clearOwners || clearGists || reset {
+	if show || mode != "" || pin != "" || len(owners) > 0 || len(removeOwners) > 0 || len(removeGists) > 0 || clearOwners || clearGists || reset {
 		fmt.Println(colorize("Trust configuration:", clrTitle))
 		fmt.Printf("  mode: %s\n", settings.Mode)
+		fmt.Printf("  pin: %s\n", settings.TrustPin)
 		if len(settings.TrustedOwners) > 0 {
 			var list []string
 			for o := range settings.TrustedOwners {
@@ -136,3 +162,123 @@ func handleConfigExec(mode string, show bool) error {
 	}
 	return nil
 }
+
+func handleConfigAPI(mode string, host string, show bool) error {
+	paths, settings, err := ensurePathsAndSettings("")
+	if err != nil {
+		return err
+	}
+
+	if mode != "" {
+		switch strings.ToLower(mode) {
+		case string(config.APIBackendGH):
+			settings.APIBackend = config.APIBackendGH
+		case string(config.APIBackendHTTP):
+			settings.APIBackend = config.APIBackendHTTP
+		default:
+			return fmt.Errorf("unknown api backend %s (expected gh|http)", mode)
+		}
+	}
+	if host != "" {
+		settings.Host = hostField(host)
+	}
+	if mode != "" || host != "" {
+		if err := config.SaveSettings(paths.Settings, settings); err != nil {
+			return err
+		}
+	}
+
+	if show || mode != "" || host != "" {
+		fmt.Printf("API backend: %s\n", settings.APIBackend)
+		fmt.Printf("Host: %s\n", gist.NormalizeHost(settings.Host))
+	}
+	return nil
+}
+
+func handleConfigInterpreter(ext string, cmds []string, remove, show bool) error {
+	paths, settings, err := ensurePathsAndSettings("")
+	if err != nil {
+		return err
+	}
+	ext = runner.NormalizeExt(ext)
+	if (len(cmds) > 0 || remove) && ext == "" {
+		return errors.New("--ext is required with --cmd or --remove")
+	}
+	if len(cmds) > 0 && remove {
+		return errors.New("use either --cmd or --remove")
+	}
+
+	changed := false
+	switch {
+	case remove:
+		if _, ok := settings.Interpreters[ext]; !ok {
+			return fmt.Errorf("no interpreter configured for %s", ext)
+		}
+		delete(settings.Interpreters, ext)
+		changed = true
+	case len(cmds) > 0:
+		for _, c := range cmds {
+			if argv, err := runner.SplitShellWords(c); err != nil || len(argv) == 0 {
+				return fmt.Errorf("interpreter %q for %s: not a valid command", c, ext)
+			}
+		}
+		if settings.Interpreters == nil {
+			settings.Interpreters = map[string][]string{}
+		}
+		settings.Interpreters[ext] = cmds
+		changed = true
+	}
+	if changed {
+		if err := config.SaveSettings(paths.Settings, settings); err != nil {
+			return err
+		}
+	}
+
+	if !show && !changed {
+		return nil
+	}
+	candidates := interpreterCandidates(settings)
+	exts := runner.InterpreterExts()
+	for e := range candidates {
+		if len(runner.BuiltinInterpreters(e)) == 0 {
+			exts = append(exts, e)
+		}
+	}
+	sort.Strings(exts)
+	if ext != "" {
+		exts = []string{ext}
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Extension\tConfigured\tBuilt-in\tUses")
+	fmt.Fprintln(tw, "---------\t----------\t--------\t----")
+	for _, e := range exts {
+		found, err := runner.LookupInterpreter(e, candidates)
+		if err != nil {
+			found = err.Error()
+		} else if found == "" {
+			found = colorize("(none found)", clrWarn)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", e, listOrDash(candidates[e]), listOrDash(runner.BuiltinInterpreters(e)), found)
+	}
+	return tw.Flush()
+}
+
+// interpreterCandidates returns the interpreters from settings keyed by normalized extension.
+func interpreterCandidates(settings config.Settings) map[string][]string {
+	if len(settings.Interpreters) == 0 {
+		return nil
+	}
+	out := make(map[string][]string, len(settings.Interpreters))
+	for ext, cmds := range settings.Interpreters {
+		ext = runner.NormalizeExt(ext)
+		out[ext] = append(out[ext], cmds...)
+	}
+	return out
+}
+
+func listOrDash(list []string) string {
+	if len(list) == 0 {
+		return "-"
+	}
+	return strings.Join(list, ", ")
+}
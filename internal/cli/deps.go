@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/leolaurindo/gixt/internal/bytesize"
+	"github.com/leolaurindo/gixt/internal/cache"
+	"github.com/leolaurindo/gixt/internal/deps"
+	"github.com/leolaurindo/gixt/internal/runner"
+)
+
+// envRequest returns the interpreter replacements and variables that run a gist inside its
+// dependency environments.
+func envRequest(envs []deps.Env) (map[string]string, map[string]string) {
+	if len(envs) == 0 {
+		return nil, nil
+	}
+	interpreters := map[string]string{}
+	for _, e := range envs {
+		for k, v := range e.Interpreters() {
+			interpreters[k] = v
+		}
+	}
+	return interpreters, deps.Vars(envs, os.Getenv("PATH"))
+}
+
+// installEnvs builds each environment in workDir that is missing or was built from other
+// declarations. force (--update) rebuilds them all.
+func installEnvs(ctx context.Context, workDir string, envs []deps.Env, force bool, opts runOptions) error {
+	records, err := deps.LoadRecords(workDir)
+	if err != nil {
+		return err
+	}
+	for _, e := range envs {
+		if !force && deps.Ready(e, records) {
+			if opts.verbose {
+				fmt.Printf("%susing %s%s\n", clrInfo, e.Describe(), clrReset)
+			}
+			continue
+		}
+		if err := os.RemoveAll(e.Dir); err != nil {
+			return fmt.Errorf("remove old %s: %w", e.Dir, err)
+		}
+		delete(records, e.Kind)
+		installer := "npm"
+		python := ""
+		if e.Kind == deps.KindPython {
+			python, err = findPython()
+			if err != nil {
+				return withExitCode(ExitSetup, &setupError{step: "python venv install", cause: err})
+			}
+			installer = python
+		}
+		fmt.Printf("%sinstalling %s...%s\n", clrInfo, e.Describe(), clrReset)
+		for _, argv := range e.Installs(python) {
+			if opts.verbose {
+				fmt.Printf("%s%s%s\n", clrDim, runner.FormatArgv(argv), clrReset)
+			}
+			if err := execute(ctx, workDir, argv, opts.confine.toolchain(), nil, opts.killGrace); err != nil {
+				_ = os.RemoveAll(e.Dir)
+				_ = deps.SaveRecords(workDir, records)
+				return withExitCode(ExitSetup, &setupError{step: string(e.Kind) + " dependency install", cause: err})
+			}
+		}
+		records[e.Kind] = deps.Record{Fingerprint: e.Fingerprint(), Sources: e.Sources, Installer: installer, CreatedAt: time.Now()}
+		if err := deps.SaveRecords(workDir, records); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findPython returns the interpreter used to create virtualenvs.
+func findPython() (string, error) {
+	candidates := []string{"python3", "python"}
+	if runtime.GOOS == "windows" {
+		candidates = []string{"python", "py", "python3"}
+	}
+	for _, name := range candidates {
+		if path, err := exec.LookPath(name); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("no %s found on PATH to create a virtualenv (install Python or run with --no-deps)", strings.Join(candidates, " or "))
+}
+
+// handleCacheEnvs lists the dependency environments in cached revisions.
+func handleCacheEnvs(cacheOverride string) error {
+	paths, err := discoverPaths(cacheOverride)
+	if err != nil {
+		return err
+	}
+	revs, _, err := cache.Scan(paths.CacheDir)
+	if err != nil {
+		return fmt.Errorf("scan cache: %w", err)
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	count := 0
+	var total int64
+	for _, r := range revs {
+		records, err := deps.LoadRecords(r.Dir)
+		if err != nil {
+			fmt.Printf("%s%s@%s: %v%s\n", clrWarn, r.Key, cache.Shorten(r.SHA), err, clrReset)
+			continue
+		}
+		for _, kind := range []deps.Kind{deps.KindPython, deps.KindNode} {
+			rec, ok := records[kind]
+			if !ok {
+				continue
+			}
+			dir := filepath.Join(r.Dir, deps.VenvDir)
+			if kind == deps.KindNode {
+				dir = filepath.Join(r.Dir, deps.NodeModulesDir)
+			}
+			size := cache.DirSize(dir)
+			if count == 0 {
+				fmt.Fprintln(tw, "Gist\tRevision\tKind\tSize\tCreated\tFrom")
+				fmt.Fprintln(tw, "----\t--------\t----\t----\t-------\t----")
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Key, cache.Shorten(r.SHA), kind, bytesize.Format(size), rec.CreatedAt.Format("2006-01-02"), strings.Join(rec.Sources, ", "))
+			count++
+			total += size
+		}
+	}
+	if count == 0 {
+		fmt.Println("no dependency environments in the cache")
+		return nil
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Printf("%d environment(s), %s total; `gixt cache prune` removes them with their revisions\n", count, bytesize.Format(total))
+	return nil
+}
@@ -16,7 +16,7 @@ import (
 	"github.com/leolaurindo/gixt/internal/runner"
 )
 
-func handleDescribe(ctx context.Context, input string) error {
+func handleDescribe(ctx context.Context, input string, hostFlag string) error {
 	target := strings.TrimSpace(input)
 	if target == "" {
 		return errors.New("usage: gixt describe <gist-id|url|alias|name|owner/name>")
@@ -27,20 +27,27 @@ func handleDescribe(ctx context.Context, input string) error {
 		return err
 	}
 
+	client, activeHost, err := clientForPaths(paths, hostFlag)
+	if err != nil {
+		return err
+	}
 	aliases, _ := alias.Load(paths.AliasFile)
-	gistID, owner, _, err := resolveIdentifier(ctx, target, aliases, paths, false, false, normalizeUserPages(0))
+	key, owner, _, err := resolveIdentifier(ctx, client, activeHost, target, aliases, paths, false, false, normalizeUserPages(0))
 	if err != nil {
 		return err
 	}
+	host, gistID := gist.SplitKey(key)
 
 	desc := ""
 	manifestDetails := ""
 	manifestVersion := ""
+	manifestPlatforms := ""
+	var manifestArgs *runner.RunManifest
 
 	// Prefer indexed data when available.
 	if idx, err := index.Load(paths.IndexFile); err == nil {
 		for _, e := range idx.Entries {
-			if e.ID == gistID {
+			if e.Key() == key {
 				desc = strings.TrimSpace(e.Description)
 				if owner == "" {
 					owner = e.Owner
@@ -52,7 +59,7 @@ func handleDescribe(ctx context.Context, input string) error {
 
 	// Fall back to cached manifest.
 	if desc == "" || owner == "" || manifestDetails == "" || manifestVersion == "" {
-		if m, dir, ok := latestManifest(paths.CacheDir, gistID); ok {
+		if m, dir, ok := latestManifest(paths.CacheDir, key); ok {
 			if desc == "" {
 				desc = strings.TrimSpace(m.Description)
 			}
@@ -63,7 +70,15 @@ func handleDescribe(ctx context.Context, input string) error {
 				if manifestPath := findManifestFile(dir, m.Files); manifestPath != "" {
 					if rm, err := runner.LoadRunManifest(manifestPath); err == nil {
 						manifestDetails = rm.Details
+						if rm.Args != nil || len(rm.Commands) > 0 {
+							manifestArgs = &rm
+						}
 						manifestVersion = strings.TrimSpace(rm.Version)
+						if len(rm.Platforms) > 0 {
+							manifestPlatforms = strings.Join(rm.Platforms, ", ")
+						} else if keys := rm.Run.Keys(); len(keys) > 0 {
+							manifestPlatforms = strings.Join(keys, ", ")
+						}
 						if desc == "" {
 							desc = strings.TrimSpace(rm.Details)
 						}
@@ -75,7 +90,11 @@ func handleDescribe(ctx context.Context, input string) error {
 
 	// Final fallback: live fetch.
 	if desc == "" || owner == "" {
-		if g, err := gist.Fetch(ctx, gistID, ""); err == nil {
+		client, _, err := clientForKey(paths, client, activeHost, key)
+		if err != nil {
+			return err
+		}
+		if g, err := client.Fetch(ctx, gistID, ""); err == nil {
 			if desc == "" {
 				desc = strings.TrimSpace(g.Description)
 			}
@@ -95,6 +114,9 @@ func handleDescribe(ctx context.Context, input string) error {
 	}
 
 	fmt.Printf("ID: %s\n", gistID)
+	if !gist.IsDefaultHost(host) {
+		fmt.Printf("Host: %s\n", host)
+	}
 	if owner != "" {
 		fmt.Printf("Owner: %s\n", owner)
 	}
@@ -104,16 +126,24 @@ func handleDescribe(ctx context.Context, input string) error {
 	if manifestDetails != "" {
 		fmt.Printf("Manifest details: %s\n", manifestDetails)
 	}
+	if manifestPlatforms != "" {
+		fmt.Printf("Manifest platforms: %s\n", manifestPlatforms)
+	}
 	fmt.Printf("Description: %s\n", desc)
+	if manifestArgs != nil {
+		usage := *manifestArgs
+		usage.Details, usage.Platforms = "", nil // already printed above
+		fmt.Printf("\n%s", runner.Usage(target, usage))
+	}
 	return nil
 }
 
-func latestManifest(cacheDir, gistID string) (cache.Manifest, string, bool) {
+func latestManifest(cacheDir, key string) (cache.Manifest, string, bool) {
 	var latest cache.Manifest
 	var latestTime time.Time
 	var manifestDir string
 
-	root := filepath.Join(cacheDir, gistID)
+	root := cache.GistDir(cacheDir, key)
 	entries, err := os.ReadDir(root)
 	if err != nil {
 		return cache.Manifest{}, "", false
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/leolaurindo/gixt/internal/envvar"
+)
+
+// loadEnvOverrides reads the --env-file files in order, then the --env assignments, into the
+// variables applied over the manifest env. Relative file paths are resolved against callerDir.
+func loadEnvOverrides(files []string, assignments []string, callerDir string) (map[string]string, error) {
+	if len(files) == 0 && len(assignments) == 0 {
+		return nil, nil
+	}
+	vars := map[string]string{}
+	lookup := func(name string) (string, bool) {
+		if v, ok := vars[name]; ok {
+			return v, true
+		}
+		return os.LookupEnv(name)
+	}
+	for _, f := range files {
+		path := f
+		if !filepath.IsAbs(path) && callerDir != "" {
+			path = filepath.Join(callerDir, path)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read env file: %w", err)
+		}
+		parsed, err := envvar.ParseFile(data, lookup)
+		if err != nil {
+			return nil, fmt.Errorf("env file %s: %w", f, err)
+		}
+		for k, v := range parsed {
+			vars[k] = v
+		}
+	}
+	for _, a := range assignments {
+		k, v, err := envvar.ParseAssignment(a)
+		if err != nil {
+			return nil, err
+		}
+		vars[k] = v
+	}
+	return vars, nil
+}
+
+// askRequiredEnv prompts for required variables that are not set. Without a terminal on
+// stdin it fails, naming the variables and how to provide them.
+func askRequiredEnv(gistKey string, missing []string) (map[string]string, error) {
+	if !stdinIsTerminal() {
+		return nil, fmt.Errorf("gist %s requires %s; set %s in the environment, or pass --env KEY=VALUE or --env-file <path>",
+			gistKey, strings.Join(missing, ", "), pluralize(len(missing), "it", "them"))
+	}
+	fmt.Printf("%sGist %s requires environment variables that are not set.%s\n", clrTitle, gistKey, clrReset)
+	reader := bufio.NewReader(os.Stdin)
+	values := map[string]string{}
+	for _, name := range missing {
+		fmt.Printf("%s%s: %s", clrPrompt, name, clrReset)
+		hidden := looksSecret(name) && echoOff()
+		line, err := reader.ReadString('\n')
+		if hidden {
+			echoOn()
+			fmt.Println()
+		}
+		value := strings.TrimRight(line, "\r\n")
+		if value == "" {
+			if err != nil {
+				return nil, fmt.Errorf("read %s: %w", name, err)
+			}
+			return nil, fmt.Errorf("%s is required; aborting", name)
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+func pluralize(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// looksSecret reports whether a variable name suggests a credential, whose value is read
+// without echo.
+func looksSecret(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, word := range []string{"TOKEN", "SECRET", "PASSWORD", "PASSWD", "KEY", "CREDENTIAL", "AUTH"} {
+		if strings.Contains(upper, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// stdinIsTerminal reports whether gixt can prompt; tests replace it.
+var stdinIsTerminal = func() bool {
+	info, err := os.Stdin.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// echoOff stops the terminal from echoing input, reporting whether it did. It uses stty, so
+// on Windows input stays visible.
+func echoOff() bool {
+	if runtime.GOOS == "windows" {
+		return false
+	}
+	return stty("-echo") == nil
+}
+
+func echoOn() {
+	_ = stty("echo")
+}
+
+func stty(arg string) error {
+	cmd := exec.Command("stty", arg)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+
+	"github.com/leolaurindo/gixt/internal/gist"
+)
+
+// Exit codes for gixt's own failures. When the gist itself runs, its exit status (or the
+// signal that killed it) is passed through instead.
+const (
+	ExitError   = 1   // any other gixt failure (usage, config, filesystem)
+	ExitResolve = 66  // identifier could not be resolved to a gist, or the gist does not exist
+	ExitNetwork = 69  // GitHub API or network failure
+	ExitTrust   = 77  // trust prompt declined
+	ExitSetup   = 78  // setup step, dependency install or build failed, or the sandbox or limits could not be set up
+	ExitTimeout = 124 // --timeout expired (same code as timeout(1))
+)
+
+// exitError tags err with the exit code gixt should terminate with.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string { return e.err.Error() }
+func (e *exitError) Unwrap() error { return e.err }
+
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// childExitError reports that the gist ran and exited unsuccessfully.
+type childExitError struct {
+	state *os.ProcessState
+}
+
+func (e *childExitError) Error() string {
+	if sig, ok := childSignal(e.state); ok {
+		return fmt.Sprintf("gist killed by signal: %v", sig)
+	}
+	return fmt.Sprintf("gist exited with status %d", e.state.ExitCode())
+}
+
+// ExitCode maps an error returned by Execute to the process exit code.
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var child *childExitError
+	if errors.As(err, &child) {
+		if sig, ok := childSignal(child.state); ok {
+			return 128 + int(sig)
+		}
+		return child.state.ExitCode()
+	}
+	var coded *exitError
+	if errors.As(err, &coded) {
+		return coded.code
+	}
+	if isNetworkError(err) {
+		return ExitNetwork
+	}
+	return ExitError
+}
+
+func isNetworkError(err error) bool {
+	var apiErr *gist.APIError
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &apiErr) || errors.As(err, &urlErr) || errors.As(err, &netErr)
+}
+
+// Exit terminates gixt for err. A gist's failure is passed through silently: its exit
+// status is reused and, where the platform allows, the signal that killed it is re-raised.
+// When a resource limit ended the gist, gixt says which one before exiting with its status.
+func Exit(err error) {
+	var limit *limitError
+	if errors.As(err, &limit) {
+		PrintError(err)
+		os.Exit(ExitCode(err))
+	}
+	var child *childExitError
+	if errors.As(err, &child) {
+		if sig, ok := childSignal(child.state); ok {
+			reraise(sig)
+		}
+		os.Exit(ExitCode(err))
+	}
+	PrintError(err)
+	os.Exit(ExitCode(err))
+}
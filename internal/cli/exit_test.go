@@ -0,0 +1,45 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"testing"
+
+	"github.com/leolaurindo/gixt/internal/gist"
+)
+
+func TestExitCodeClassifiesErrors(t *testing.T) {
+	cases := []struct {
+		err  error
+		want int
+	}{
+		{errors.New("boom"), ExitError},
+		{withExitCode(ExitTrust, errors.New("aborted by user")), ExitTrust},
+		{fmt.Errorf("wrapped: %w", withExitCode(ExitResolve, errors.New("could not resolve"))), ExitResolve},
+		{fmt.Errorf("fetch: %w", &gist.APIError{Status: 502, Message: "bad gateway"}), ExitNetwork},
+	}
+	for _, tc := range cases {
+		if got := ExitCode(tc.err); got != tc.want {
+			t.Fatalf("ExitCode(%v) = %d, want %d", tc.err, got, tc.want)
+		}
+	}
+}
+
+func TestExecutePassesThroughChildStatus(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := t.TempDir()
+
+	err := execute(context.Background(), dir, []string{"sh", "-c", "exit 3"}, confinement{}, nil, defaultKillGrace)
+	if got := ExitCode(err); got != 3 {
+		t.Fatalf("expected child exit code 3, got %d (%v)", got, err)
+	}
+
+	err = execute(context.Background(), dir, []string{"sh", "-c", "kill -TERM $$"}, confinement{}, nil, defaultKillGrace)
+	if got := ExitCode(err); got != 128+15 {
+		t.Fatalf("expected 143 for SIGTERM, got %d (%v)", got, err)
+	}
+}
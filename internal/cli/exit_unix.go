@@ -0,0 +1,30 @@
+//go:build !windows
+
+package cli
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+func childSignal(state *os.ProcessState) (syscall.Signal, bool) {
+	ws, ok := state.Sys().(syscall.WaitStatus)
+	if !ok || !ws.Signaled() {
+		return 0, false
+	}
+	return ws.Signal(), true
+}
+
+// reraise kills gixt with sig so callers observe the same termination as the gist. Only
+// signals whose default action is a plain exit are re-raised; the Go runtime turns others
+// (SIGQUIT, SIGSEGV, ...) into stack dumps, so those fall back to exit code 128+n.
+func reraise(sig syscall.Signal) {
+	switch sig {
+	case syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM, syscall.SIGKILL:
+	default:
+		return
+	}
+	signal.Reset(sig)
+	_ = syscall.Kill(os.Getpid(), sig)
+}
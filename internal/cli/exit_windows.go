@@ -0,0 +1,15 @@
+//go:build windows
+
+package cli
+
+import (
+	"os"
+	"syscall"
+)
+
+// Windows has no termination signals; the exit status carries everything.
+func childSignal(*os.ProcessState) (syscall.Signal, bool) {
+	return 0, false
+}
+
+func reraise(syscall.Signal) {}
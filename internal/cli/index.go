@@ -16,10 +16,12 @@ import (
 	"github.com/leolaurindo/gixt/internal/config"
 	"github.com/leolaurindo/gixt/internal/gist"
 	"github.com/leolaurindo/gixt/internal/index"
+	"github.com/leolaurindo/gixt/internal/runner"
 )
 
 type listRow struct {
 	ID          string
+	Host        string
 	Owner       string
 	Description string
 	Files       []string
@@ -38,7 +40,7 @@ func handleUpdateIndex(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
-	entries, removed, err := refreshIndexedGists(ctx, current.Entries)
+	entries, removed, err := refreshIndexedGists(ctx, paths, current.Entries)
 	if err != nil {
 		return err
 	}
@@ -54,18 +56,22 @@ func handleUpdateIndex(ctx context.Context) error {
 	return nil
 }
 
-func handleIndexMine(ctx context.Context) error {
+func handleIndexMine(ctx context.Context, hostFlag string) error {
 	paths, err := ensurePaths("")
 	if err != nil {
 		return err
 	}
 
-	fmt.Println("fetching your gists via gh...")
-	mine, err := gist.List(ctx, 100, 5)
+	client, host, err := clientForPaths(paths, hostFlag)
 	if err != nil {
 		return err
 	}
-	freshEntries := entriesFromList(mine)
+	fmt.Printf("fetching your gists from %s...\n", host)
+	mine, err := client.List(ctx, 100, 5)
+	if err != nil {
+		return err
+	}
+	freshEntries := entriesFromList(mine, host)
 	ownerSet := map[string]bool{}
 	for _, e := range freshEntries {
 		ownerKey := strings.ToLower(strings.TrimSpace(e.Owner))
@@ -79,14 +85,20 @@ func handleIndexMine(ctx context.Context) error {
 		return err
 	}
 	merged := map[string]index.Entry{}
+	previous := map[string]index.Entry{}
 	for _, e := range idx.Entries {
-		if ownerSet[strings.ToLower(strings.TrimSpace(e.Owner))] {
+		previous[e.Key()] = e
+		if gist.NormalizeHost(e.Host) == host && ownerSet[strings.ToLower(strings.TrimSpace(e.Owner))] {
 			continue
 		}
-		merged[e.ID] = e
+		merged[e.Key()] = e
 	}
 	for _, e := range freshEntries {
-		merged[e.ID] = e
+		// Listings carry no file contents; keep commands learned from earlier runs.
+		if prev, ok := previous[e.Key()]; ok {
+			e.Commands = prev.Commands
+		}
+		merged[e.Key()] = e
 	}
 
 	entries := make([]index.Entry, 0, len(merged))
@@ -103,32 +115,46 @@ func handleIndexMine(ctx context.Context) error {
 	return nil
 }
 
-func refreshIndexedGists(ctx context.Context, entries []index.Entry) ([]index.Entry, int, error) {
+func refreshIndexedGists(ctx context.Context, paths config.Paths, entries []index.Entry) ([]index.Entry, int, error) {
 	if len(entries) == 0 {
 		fmt.Println("index is empty; nothing to refresh (add entries via index-mine, index-owner, or register).")
 		return nil, 0, nil
 	}
 
-	fmt.Println("refreshing indexed gists individually via gh...")
+	fmt.Println("refreshing indexed gists individually...")
+	settings, err := config.LoadSettings(paths.Settings)
+	if err != nil {
+		return nil, 0, err
+	}
+	clients := map[string]gist.Client{}
 	var refreshed []index.Entry
 	missing := 0
 	for _, ent := range entries {
-		fmt.Printf("  %s\n", ent.ID)
-		g, err := gist.Fetch(ctx, ent.ID, "")
+		fmt.Printf("  %s\n", ent.Key())
+		host := gist.NormalizeHost(ent.Host)
+		client, ok := clients[host]
+		if !ok {
+			client, err = newGistClient(settings, host)
+			if err != nil {
+				return nil, missing, err
+			}
+			clients[host] = client
+		}
+		g, err := client.Fetch(ctx, ent.ID, "")
 		if err != nil {
 			if gist.IsNotFound(err) {
-				fmt.Printf("%sskip missing gist %s (removed from index)%s\n", clrWarn, ent.ID, clrReset)
+				fmt.Printf("%sskip missing gist %s (removed from index)%s\n", clrWarn, ent.Key(), clrReset)
 				missing++
 				continue
 			}
 			return nil, missing, err
 		}
-		refreshed = append(refreshed, toIndexEntryFromGist(g))
+		refreshed = append(refreshed, toIndexEntryFromGist(g, host))
 	}
 	// dedupe in case of duplicates
 	uniq := map[string]index.Entry{}
 	for _, e := range refreshed {
-		uniq[e.ID] = e
+		uniq[e.Key()] = e
 	}
 	deduped := make([]index.Entry, 0, len(uniq))
 	for _, e := range uniq {
@@ -159,30 +185,27 @@ func handleClearIndex(cacheDir string) error {
 	return nil
 }
 
-func handleList(ctx context.Context, cacheOnly bool, mine bool) error {
+func handleList(ctx context.Context, cacheOnly bool, mine bool, hostFlag string) error {
 	paths, err := ensurePaths("")
 	if err != nil {
 		return err
 	}
+	client, host, err := clientForPaths(paths, hostFlag)
+	if err != nil {
+		return err
+	}
 	aliasesMap, _ := alias.Load(paths.AliasFile)
 	idx, _ := index.Load(paths.IndexFile)
 	aliasByID := map[string][]string{}
 	for name, target := range aliasesMap {
-		id := resolveAliasTarget(target, idx)
-		if id == "" {
-			raw := gist.ExtractID(target)
-			if gist.IsLikelyGistID(raw) {
-				id = raw
-			}
-		}
-		if id != "" {
-			aliasByID[keyForID(id)] = append(aliasByID[keyForID(id)], name)
+		if key := resolveAliasTarget(target, idx); key != "" {
+			aliasByID[keyForID(key)] = append(aliasByID[keyForID(key)], name)
 		}
 	}
 
 	currentUser := ""
 	if mine {
-		if login, err := gist.CurrentUser(ctx); err == nil {
+		if login, err := client.CurrentUser(ctx); err == nil {
 			currentUser = login
 		} else {
 			return fmt.Errorf("detect current user for --mine: %w", err)
@@ -199,7 +222,7 @@ func handleList(ctx context.Context, cacheOnly bool, mine bool) error {
 		if cacheOnly && !r.Cached {
 			continue
 		}
-		if currentUser != "" && !strings.EqualFold(r.Owner, currentUser) {
+		if currentUser != "" && (!strings.EqualFold(r.Owner, currentUser) || gist.NormalizeHost(r.Host) != host) {
 			continue
 		}
 		filtered = append(filtered, r)
@@ -216,7 +239,7 @@ func handleList(ctx context.Context, cacheOnly bool, mine bool) error {
 	return nil
 }
 
-func handleIndexOwner(ctx context.Context, owner string) error {
+func handleIndexOwner(ctx context.Context, owner string, hostFlag string) error {
 	if owner == "" {
 		return errors.New("usage: gixt index-owner --owner <login>")
 	}
@@ -226,8 +249,12 @@ func handleIndexOwner(ctx context.Context, owner string) error {
 		return err
 	}
 
-	fmt.Printf("fetching gists for owner %s via gh...\n", owner)
-	items, err := gist.ListForOwner(ctx, owner, 100, 5)
+	client, host, err := clientForPaths(paths, hostFlag)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("fetching gists for owner %s from %s...\n", owner, host)
+	items, err := client.ListForOwner(ctx, owner, 100, 5)
 	if err != nil {
 		return err
 	}
@@ -238,13 +265,14 @@ func handleIndexOwner(ctx context.Context, owner string) error {
 	}
 	existing := map[string]bool{}
 	for _, e := range idx.Entries {
-		existing[e.ID] = true
+		existing[e.Key()] = true
 	}
 	for _, it := range items {
-		if existing[it.ID] {
+		entry := toIndexEntry(it, host)
+		if existing[entry.Key()] {
 			continue
 		}
-		idx.Entries = append(idx.Entries, toIndexEntry(it))
+		idx.Entries = append(idx.Entries, entry)
 	}
 	idx.GeneratedAt = time.Now()
 	if err := index.Save(paths.IndexFile, idx); err != nil {
@@ -259,9 +287,10 @@ func gatherListRows(paths config.Paths, aliasByID map[string][]string) ([]listRo
 
 	idx, _ := index.Load(paths.IndexFile)
 	for _, e := range idx.Entries {
-		key := keyForID(e.ID)
+		key := keyForID(e.Key())
 		rows[key] = listRow{
 			ID:          e.ID,
+			Host:        e.Host,
 			Owner:       e.Owner,
 			Description: strings.TrimSpace(e.Description),
 			Files:       append([]string{}, e.Filenames...),
@@ -277,7 +306,6 @@ func gatherListRows(paths config.Paths, aliasByID map[string][]string) ([]listRo
 			continue
 		}
 		gistID := e.Name()
-		key := keyForID(gistID)
 		shaEntries, err := os.ReadDir(filepath.Join(paths.CacheDir, gistID))
 		if err != nil {
 			continue
@@ -305,10 +333,12 @@ func gatherListRows(paths config.Paths, aliasByID map[string][]string) ([]listRo
 		if latest.GistID == "" {
 			continue
 		}
+		key := keyForID(latest.Key())
 		existing, ok := rows[key]
 		if !ok || !existing.Cached {
 			rows[key] = listRow{
 				ID:          latest.GistID,
+				Host:        latest.Host,
 				Owner:       latest.Owner,
 				Description: strings.TrimSpace(latest.Description),
 				Files:       append([]string{}, latest.Files...),
@@ -343,9 +373,9 @@ func gatherListRows(paths config.Paths, aliasByID map[string][]string) ([]listRo
 }
 
 func resolveAliasTarget(target string, idx index.Index) string {
-	id := gist.ExtractID(target)
-	if gist.IsLikelyGistID(id) {
-		return id
+	key := aliasKey(target)
+	if _, id := gist.SplitKey(key); gist.IsLikelyGistID(id) {
+		return key
 	}
 	if len(idx.Entries) == 0 {
 		return ""
@@ -362,13 +392,13 @@ func resolveAliasTarget(target string, idx index.Index) string {
 			}
 			desc := strings.ToLower(strings.TrimSpace(e.Description))
 			if desc == namePart {
-				matches = append(matches, e.ID)
+				matches = append(matches, e.Key())
 				continue
 			}
 			for _, f := range e.Filenames {
 				base := strings.ToLower(strings.TrimSuffix(filepath.Base(f), filepath.Ext(f)))
 				if base == namePart {
-					matches = append(matches, e.ID)
+					matches = append(matches, e.Key())
 					break
 				}
 			}
@@ -384,13 +414,13 @@ func resolveAliasTarget(target string, idx index.Index) string {
 	for _, e := range idx.Entries {
 		desc := strings.ToLower(strings.TrimSpace(e.Description))
 		if desc == nameLower {
-			matches = append(matches, e.ID)
+			matches = append(matches, e.Key())
 			continue
 		}
 		for _, f := range e.Filenames {
 			base := strings.ToLower(strings.TrimSuffix(filepath.Base(f), filepath.Ext(f)))
 			if base == nameLower {
-				matches = append(matches, e.ID)
+				matches = append(matches, e.Key())
 				break
 			}
 		}
@@ -433,9 +463,13 @@ func printListTable(rows []listRow, aliasByID map[string][]string) {
 	fmt.Fprintln(tw, "--\t------\t-----\t-----\t-------\t-----------")
 	for _, r := range rows {
 		files := strings.Join(r.Files, ",")
-		aliases := strings.Join(aliasByID[keyForID(r.ID)], ",")
+		aliases := strings.Join(aliasByID[keyForID(gist.Key(r.Host, r.ID))], ",")
+		id := trimCell(cache.Shorten(r.ID), idMax)
+		if !gist.IsDefaultHost(r.Host) {
+			id = gist.Key(r.Host, cache.Shorten(r.ID))
+		}
 		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
-			trimCell(cache.Shorten(r.ID), idMax),
+			id,
 			trimCell(r.Source, sourceMax),
 			trimCell(r.Owner, ownerMax),
 			trimCell(files, filesMax),
@@ -457,15 +491,15 @@ func trimCell(s string, max int) string {
 	return s[:max-3] + "..."
 }
 
-func entriesFromList(items []gist.ListItem) []index.Entry {
+func entriesFromList(items []gist.ListItem, host string) []index.Entry {
 	entries := make([]index.Entry, 0, len(items))
 	for _, it := range items {
-		entries = append(entries, toIndexEntry(it))
+		entries = append(entries, toIndexEntry(it, host))
 	}
 	return entries
 }
 
-func toIndexEntry(it gist.ListItem) index.Entry {
+func toIndexEntry(it gist.ListItem, host string) index.Entry {
 	var names []string
 	for name := range it.Files {
 		names = append(names, name)
@@ -477,10 +511,11 @@ func toIndexEntry(it gist.ListItem) index.Entry {
 		Filenames:   names,
 		UpdatedAt:   it.UpdatedAt,
 		Owner:       it.Owner.Login,
+		Host:        hostField(host),
 	}
 }
 
-func toIndexEntryFromGist(g gist.Gist) index.Entry {
+func toIndexEntryFromGist(g gist.Gist, host string) index.Entry {
 	var names []string
 	for name := range g.Files {
 		names = append(names, name)
@@ -492,7 +527,23 @@ func toIndexEntryFromGist(g gist.Gist) index.Entry {
 		Filenames:   names,
 		UpdatedAt:   g.UpdatedAt,
 		Owner:       strings.TrimSpace(gist.GuessOwner(g)),
+		Host:        hostField(host),
+		Commands:    gistCommands(g),
+	}
+}
+
+// gistCommands returns the command names declared by the gist's gixt.json, when the fetched
+// gist carries its content.
+func gistCommands(g gist.Gist) []string {
+	f, ok := g.Files["gixt.json"]
+	if !ok || f.Truncated || f.Content == "" {
+		return nil
+	}
+	rm, err := runner.LoadRunManifestBytes([]byte(f.Content))
+	if err != nil {
+		return nil
 	}
+	return rm.CommandNames()
 }
 
 func sortIndexEntries(entries []index.Entry) {
@@ -0,0 +1,335 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/leolaurindo/gixt/internal/alias"
+	"github.com/leolaurindo/gixt/internal/cache"
+	"github.com/leolaurindo/gixt/internal/gist"
+	"github.com/leolaurindo/gixt/internal/shim"
+)
+
+type installOpts struct {
+	target string
+	as     string
+	binDir string
+	ref    string
+	host   string
+	flags  []string // run flags baked into the launcher
+}
+
+// parseInstallArgs reads `<gist> [--as <cmd>] [--bin-dir <dir>] [--ref <sha>] [run flags...]`.
+// Install flags may follow the gist; anything else after it is kept as run flags.
+func parseInstallArgs(args []string, opts *installOpts) error {
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		name, val, hasVal := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		var dest *string
+		if strings.HasPrefix(arg, "-") {
+			switch name {
+			case "as":
+				dest = &opts.as
+			case "bin-dir":
+				dest = &opts.binDir
+			case "ref":
+				dest = &opts.ref
+			case "host":
+				dest = &opts.host
+			}
+		}
+		switch {
+		case dest != nil && hasVal:
+			*dest = val
+		case dest != nil:
+			if i+1 >= len(args) {
+				return fmt.Errorf("missing value for --%s", name)
+			}
+			i++
+			*dest = args[i]
+		case opts.target == "" && !strings.HasPrefix(arg, "-"):
+			opts.target = arg
+		default:
+			opts.flags = append(opts.flags, arg)
+		}
+	}
+	if opts.target == "" {
+		return errors.New("usage: gixt install <gist> [--as <cmd>] [--bin-dir <dir>] [--ref <sha>] [run flags...]")
+	}
+	return nil
+}
+
+// validateRunFlags parses the flags to bake into a launcher with the `gixt run` flag set, so
+// a typo fails now instead of every time the command is used.
+func validateRunFlags(flags []string) error {
+	if slices.Contains(flags, "--") {
+		return errors.New("run flags cannot include \"--\": the launcher passes its own arguments to the gist")
+	}
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	for _, f := range runFlags() {
+		if err := f.Apply(fs); err != nil {
+			return err
+		}
+	}
+	if err := fs.Parse(flags); err != nil {
+		return fmt.Errorf("invalid run flags %q: %w", strings.Join(flags, " "), err)
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("invalid run flags %q: unexpected argument %q", strings.Join(flags, " "), fs.Arg(0))
+	}
+	return nil
+}
+
+func defaultBinDir() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("detect home dir: %w", err)
+	}
+	return filepath.Join(home, ".local", "bin"), nil
+}
+
+// shimName picks the command name for an install: --as, else the friendly name the gist
+// was requested by.
+func shimName(opts installOpts) (string, error) {
+	name := strings.TrimSpace(opts.as)
+	if name == "" {
+		target := strings.TrimSpace(opts.target)
+		_, id := gist.ExtractHostID(target)
+		if strings.Contains(target, "://") || gist.IsLikelyGistID(id) || gist.IsLikelyGistID(target[strings.LastIndex(target, "/")+1:]) {
+			return "", errors.New("installing by ID or URL needs a command name: add --as <cmd>")
+		}
+		name = target[strings.LastIndex(target, "/")+1:]
+		if _, command := splitCommand(target, nil); command != "" {
+			name = command // gixt install tool:deploy installs "deploy"
+		} else {
+			name = strings.TrimSuffix(name, filepath.Ext(name))
+		}
+	}
+	if name == "" || strings.HasPrefix(name, "-") || strings.ContainsAny(name, `/\:*?"<>| `) {
+		return "", fmt.Errorf("invalid command name %q", name)
+	}
+	return name, nil
+}
+
+func handleInstall(ctx context.Context, opts installOpts) error {
+	name, err := shimName(opts)
+	if err != nil {
+		return err
+	}
+	if err := validateRunFlags(opts.flags); err != nil {
+		return err
+	}
+	paths, err := ensurePaths("")
+	if err != nil {
+		return err
+	}
+	client, host, err := clientForPaths(paths, opts.host)
+	if err != nil {
+		return err
+	}
+	aliases, _ := alias.Load(paths.AliasFile)
+	target, command := splitCommand(opts.target, aliases)
+	key, _, fromIndex, err := resolveIdentifier(ctx, client, host, target, aliases, paths, false, false, normalizeUserPages(0))
+	if err != nil {
+		return err
+	}
+	if command == "" && fromIndex {
+		command = indexedCommand(paths, key, target)
+	}
+
+	binDir := opts.binDir
+	if binDir == "" {
+		if binDir, err = defaultBinDir(); err != nil {
+			return err
+		}
+	}
+	binDir, err = filepath.Abs(binDir)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		return fmt.Errorf("create bin dir: %w", err)
+	}
+	gixtPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate gixt binary: %w", err)
+	}
+
+	records, err := shim.Load(paths.Installs)
+	if err != nil {
+		return err
+	}
+	rec := shim.Record{
+		Name:        name,
+		Path:        filepath.Join(binDir, shim.FileName(runtime.GOOS, name)),
+		Gist:        key,
+		Command:     command,
+		Ref:         opts.ref,
+		Flags:       opts.flags,
+		InstalledAt: time.Now(),
+	}
+	owned := -1
+	for i, r := range records {
+		if r.Path == rec.Path {
+			owned = i
+		}
+	}
+	if _, err := os.Stat(rec.Path); err == nil {
+		if owned < 0 {
+			return fmt.Errorf("%s already exists and was not installed by gixt; choose another name with --as", rec.Path)
+		}
+		if status := records[owned].Status(); status == "modified" {
+			return fmt.Errorf("%s was modified after gixt installed it; remove it yourself or run `gixt uninstall --force %s` first", rec.Path, records[owned].Name)
+		}
+	}
+
+	content, err := shim.Render(runtime.GOOS, gixtPath, rec)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(rec.Path, []byte(content), 0o755); err != nil {
+		return fmt.Errorf("write launcher: %w", err)
+	}
+	rec.SHA256 = shim.Hash([]byte(content))
+	if owned >= 0 {
+		records[owned] = rec
+	} else {
+		records = append(records, rec)
+	}
+	if err := shim.Save(paths.Installs, records); err != nil {
+		return err
+	}
+
+	fmt.Printf("installed %s -> gixt %s\n", rec.Path, strings.Join(rec.Args(), " "))
+	if !dirOnPath(binDir) {
+		fmt.Printf("%s%s is not on PATH; add it to run `%s` directly.%s\n", clrWarn, binDir, name, clrReset)
+	}
+	return nil
+}
+
+func dirOnPath(dir string) bool {
+	for _, p := range filepath.SplitList(os.Getenv("PATH")) {
+		if p == "" {
+			continue
+		}
+		if abs, err := filepath.Abs(p); err == nil && filepath.Clean(abs) == filepath.Clean(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+func handleInstalled() error {
+	paths, err := discoverPaths("")
+	if err != nil {
+		return err
+	}
+	records, err := shim.Load(paths.Installs)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		fmt.Println("no gists installed (add one with `gixt install <gist> --as <cmd>`)")
+		return nil
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Name\tGist\tRef\tFlags\tStatus\tPath")
+	fmt.Fprintln(tw, "----\t----\t---\t-----\t------\t----")
+	for _, r := range records {
+		ref := r.Ref
+		if ref == "" {
+			ref = "latest"
+		}
+		target := cache.Shorten(r.Gist)
+		if r.Command != "" {
+			target += ":" + r.Command
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n", r.Name, target, cache.Shorten(ref), strings.Join(r.Flags, " "), r.Status(), r.Path)
+	}
+	return tw.Flush()
+}
+
+// handleUninstall removes launchers recorded in installs.json. A launcher edited since it
+// was written is left alone unless force is set; unrecorded files are never touched. When
+// binDir is set only launchers in it match; otherwise a name installed in several bin dirs
+// is refused as ambiguous.
+func handleUninstall(names []string, binDir string, force bool) error {
+	if len(names) == 0 {
+		return errors.New("usage: gixt uninstall [--force] [--bin-dir <dir>] <cmd>...")
+	}
+	if binDir != "" {
+		abs, err := filepath.Abs(binDir)
+		if err != nil {
+			return err
+		}
+		binDir = abs
+	}
+	paths, err := ensurePaths("")
+	if err != nil {
+		return err
+	}
+	records, err := shim.Load(paths.Installs)
+	if err != nil {
+		return err
+	}
+	var failed []string
+	for _, name := range names {
+		var matches []int
+		for i, r := range records {
+			if r.Name == name && (binDir == "" || filepath.Dir(r.Path) == binDir) {
+				matches = append(matches, i)
+			}
+		}
+		if len(matches) == 0 {
+			failed = append(failed, fmt.Sprintf("%s: not installed by gixt", name))
+			continue
+		}
+		if len(matches) > 1 {
+			var dirs []string
+			for _, i := range matches {
+				dirs = append(dirs, filepath.Dir(records[i].Path))
+			}
+			failed = append(failed, fmt.Sprintf("%s: installed in %s; choose one with --bin-dir", name, strings.Join(dirs, ", ")))
+			continue
+		}
+		idx := matches[0]
+		rec := records[idx]
+		switch rec.Status() {
+		case "modified":
+			if !force {
+				failed = append(failed, fmt.Sprintf("%s: %s was modified after install (use --force to remove it anyway)", name, rec.Path))
+				continue
+			}
+			fallthrough
+		case "ok":
+			if err := os.Remove(rec.Path); err != nil {
+				failed = append(failed, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+		case "missing":
+		default:
+			failed = append(failed, fmt.Sprintf("%s: cannot verify %s", name, rec.Path))
+			continue
+		}
+		records = append(records[:idx], records[idx+1:]...)
+		fmt.Printf("uninstalled %s (%s)\n", name, rec.Path)
+	}
+	if err := shim.Save(paths.Installs, records); err != nil {
+		return err
+	}
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, "; "))
+	}
+	return nil
+}
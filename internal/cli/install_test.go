@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/leolaurindo/gixt/internal/shim"
+)
+
+func TestParseInstallArgsAcceptsFlagsAfterGist(t *testing.T) {
+	opts := installOpts{}
+	err := parseInstallArgs([]string{"alice/tool", "--as", "t", "--bin-dir=/tmp/bin", "--yes", "--timeout", "30s"}, &opts)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if opts.target != "alice/tool" || opts.as != "t" || opts.binDir != "/tmp/bin" {
+		t.Fatalf("unexpected opts %+v", opts)
+	}
+	if strings.Join(opts.flags, " ") != "--yes --timeout 30s" {
+		t.Fatalf("unexpected run flags %q", opts.flags)
+	}
+	if _, err := shimName(installOpts{target: "deadbeefcafe"}); err == nil {
+		t.Fatalf("expected --as to be required for bare IDs")
+	}
+	if name, _ := shimName(installOpts{target: "alice/tool.py"}); name != "tool" {
+		t.Fatalf("expected name from owner/name, got %q", name)
+	}
+}
+
+func TestInstallAndUninstallOnlyTouchOwnedFiles(t *testing.T) {
+	useTestServer(t, http.NotFound)
+	ctx := context.Background()
+	binDir := t.TempDir()
+	launcher := filepath.Join(binDir, shim.FileName(runtime.GOOS, "hello"))
+
+	if err := os.WriteFile(launcher, []byte("mine"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := handleInstall(ctx, installOpts{target: "deadbeefcafe", as: "hello", binDir: binDir}); err == nil {
+		t.Fatalf("install must not overwrite a file gixt did not create")
+	}
+	os.Remove(launcher)
+
+	if err := handleInstall(ctx, installOpts{target: "deadbeefcafe", as: "hello", binDir: binDir, ref: "rev1", flags: []string{"--yes"}}); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	data, err := os.ReadFile(launcher)
+	if err != nil || !strings.Contains(string(data), "deadbeefcafe") || !strings.Contains(string(data), "rev1") {
+		t.Fatalf("unexpected launcher %q (%v)", data, err)
+	}
+
+	if err := os.WriteFile(launcher, append(data, "# edited\n"...), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := handleUninstall([]string{"hello"}, "", false); err == nil {
+		t.Fatalf("uninstall must keep a launcher edited after install")
+	}
+	if err := handleUninstall([]string{"hello"}, "", true); err != nil {
+		t.Fatalf("forced uninstall: %v", err)
+	}
+	if _, err := os.Stat(launcher); !os.IsNotExist(err) {
+		t.Fatalf("launcher should be removed, stat err=%v", err)
+	}
+	if err := handleUninstall([]string{"hello"}, "", false); err == nil {
+		t.Fatalf("uninstalling an unknown command should fail")
+	}
+}
+
+func TestInstallKeepsNamedCommand(t *testing.T) {
+	useTestServer(t, http.NotFound)
+	binDir := t.TempDir()
+	if err := handleInstall(context.Background(), installOpts{target: "deadbeefcafe:deploy", as: "dep", binDir: binDir}); err != nil {
+		t.Fatalf("install: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(binDir, shim.FileName(runtime.GOOS, "dep")))
+	if err != nil || !strings.Contains(string(data), "deadbeefcafe:deploy") {
+		t.Fatalf("launcher should run the deploy command, got %q (%v)", data, err)
+	}
+	if name, _ := shimName(installOpts{target: "alice/tool:deploy"}); name != "deploy" {
+		t.Fatalf("expected the command as the default name, got %q", name)
+	}
+}
+
+func TestUninstallMatchesBinDirAndInstallChecksRunFlags(t *testing.T) {
+	useTestServer(t, http.NotFound)
+	ctx := context.Background()
+	first, second := t.TempDir(), t.TempDir()
+
+	if err := handleInstall(ctx, installOpts{target: "deadbeefcafe", as: "hello", binDir: first, flags: []string{"--yse"}}); err == nil {
+		t.Fatalf("install should reject unknown run flags")
+	}
+	if err := handleInstall(ctx, installOpts{target: "deadbeefcafe", as: "hello", binDir: first, flags: []string{"--timeout", "soon"}}); err == nil {
+		t.Fatalf("install should reject invalid run flag values")
+	}
+	for _, dir := range []string{first, second} {
+		if err := handleInstall(ctx, installOpts{target: "deadbeefcafe", as: "hello", binDir: dir, flags: []string{"--yes", "--env", "A=1"}}); err != nil {
+			t.Fatalf("install into %s: %v", dir, err)
+		}
+	}
+
+	if err := handleUninstall([]string{"hello"}, "", false); err == nil || !strings.Contains(err.Error(), "--bin-dir") {
+		t.Fatalf("expected an ambiguity error, got %v", err)
+	}
+	if err := handleUninstall([]string{"hello"}, second, false); err != nil {
+		t.Fatalf("uninstall from %s: %v", second, err)
+	}
+	if _, err := os.Stat(filepath.Join(first, shim.FileName(runtime.GOOS, "hello"))); err != nil {
+		t.Fatalf("launcher in the other bin dir should stay: %v", err)
+	}
+	if err := handleUninstall([]string{"hello"}, "", false); err != nil {
+		t.Fatalf("uninstall of the remaining launcher: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/leolaurindo/gixt/internal/config"
+	"github.com/leolaurindo/gixt/internal/rlimit"
+)
+
+// limitsHelperCommand, as gixt's first argument, makes it set resource limits on itself and
+// exec the gist, so the limits hold from the gist's first instruction. It is not a user
+// command.
+const limitsHelperCommand = "__limits"
+
+// limitError reports that the gist ended because of a resource limit, or may have.
+type limitError struct {
+	reason string
+	child  *childExitError
+}
+
+func (e *limitError) Error() string { return e.reason }
+func (e *limitError) Unwrap() error { return e.child }
+
+// gistLimits picks the limits for a run: the lower of the manifest's and the settings'
+// values for each limit, with run flags overriding both.
+func gistLimits(flags rlimit.Spec, manifest, settings *rlimit.Spec) (rlimit.Limits, error) {
+	var fromManifest, fromSettings rlimit.Limits
+	var err error
+	if manifest != nil {
+		if fromManifest, err = manifest.Parse(); err != nil {
+			return rlimit.Limits{}, fmt.Errorf("manifest limits: %w", err)
+		}
+	}
+	if settings != nil {
+		if fromSettings, err = settings.Parse(); err != nil {
+			return rlimit.Limits{}, fmt.Errorf("settings limits: %w (fix them with `gixt config-limits`)", err)
+		}
+	}
+	fromFlags, err := flags.Parse()
+	if err != nil {
+		return rlimit.Limits{}, fmt.Errorf("--limit-* flag: %w", err)
+	}
+	return rlimit.Tightest(fromManifest, fromSettings).Override(fromFlags), nil
+}
+
+// handleConfigLimits updates the default limits. set holds the values given on the command
+// line; "none" clears a limit, as does --procs 0 when procsSet.
+func handleConfigLimits(set rlimit.Spec, procsSet, reset, show bool) error {
+	paths, settings, err := ensurePathsAndSettings("")
+	if err != nil {
+		return err
+	}
+	changed := reset || procsSet || set.Memory != "" || set.CPU != "" || set.FileSize != ""
+	if reset {
+		settings.Limits = nil
+	} else if changed {
+		next := rlimit.Spec{}
+		if settings.Limits != nil {
+			next = *settings.Limits
+		}
+		apply := func(dst *string, v string) {
+			if strings.EqualFold(v, "none") {
+				*dst = ""
+			} else if v != "" {
+				*dst = v
+			}
+		}
+		apply(&next.Memory, set.Memory)
+		apply(&next.CPU, set.CPU)
+		apply(&next.FileSize, set.FileSize)
+		if procsSet {
+			next.Processes = set.Processes
+		}
+		if _, err := next.Parse(); err != nil {
+			return err
+		}
+		settings.Limits = &next
+		if next == (rlimit.Spec{}) {
+			settings.Limits = nil
+		}
+	}
+	if changed {
+		if err := config.SaveSettings(paths.Settings, settings); err != nil {
+			return err
+		}
+	}
+
+	if show || changed {
+		var limits rlimit.Limits
+		if settings.Limits != nil {
+			limits, _ = settings.Limits.Parse()
+		}
+		desc := "none"
+		if !limits.IsZero() {
+			desc = strings.Join(limits.Describe(), ", ")
+		}
+		fmt.Printf("Default limits: %s\n", desc)
+		if !rlimit.Supported {
+			fmt.Printf("%sNot enforced here: %v%s\n", clrWarn, rlimit.ErrUnsupported, clrReset)
+		}
+	}
+	return nil
+}
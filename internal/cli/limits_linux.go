@@ -0,0 +1,128 @@
+//go:build linux
+
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/leolaurindo/gixt/internal/bytesize"
+	"github.com/leolaurindo/gixt/internal/rlimit"
+)
+
+// limitCommand makes c start through the limits helper, which applies l to itself and then
+// execs the original program in its place.
+func limitCommand(c *exec.Cmd, l rlimit.Limits) error {
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("limits: locate gixt: %w", err)
+	}
+	args := []string{self, limitsHelperCommand}
+	if l.Memory != 0 {
+		args = append(args, "--mem", strconv.FormatUint(l.Memory, 10))
+	}
+	if l.CPU != 0 {
+		args = append(args, "--cpu", l.CPU.String())
+	}
+	if l.FileSize != 0 {
+		args = append(args, "--fsize", strconv.FormatUint(l.FileSize, 10))
+	}
+	if l.Processes != 0 {
+		args = append(args, "--procs", strconv.FormatUint(l.Processes, 10))
+	}
+	args = append(args, "--", c.Path)
+	c.Path, c.Args = self, append(args, c.Args...)
+	return nil
+}
+
+// runLimitsHelper reads the arguments written by limitCommand, applies the limits and execs
+// the gist. It only returns on failure.
+func runLimitsHelper(args []string) error {
+	var l rlimit.Limits
+	for len(args) > 0 && args[0] != "--" {
+		if len(args) < 2 {
+			return withExitCode(ExitSetup, fmt.Errorf("limits: missing value for %s", args[0]))
+		}
+		flag, val := args[0], args[1]
+		args = args[2:]
+		var err error
+		switch flag {
+		case "--mem":
+			l.Memory, err = strconv.ParseUint(val, 10, 64)
+		case "--cpu":
+			l.CPU, err = time.ParseDuration(val)
+		case "--fsize":
+			l.FileSize, err = strconv.ParseUint(val, 10, 64)
+		case "--procs":
+			l.Processes, err = strconv.ParseUint(val, 10, 64)
+		default:
+			err = errors.New("unknown helper argument")
+		}
+		if err != nil {
+			return withExitCode(ExitSetup, fmt.Errorf("limits: %s: %w", flag, err))
+		}
+	}
+	if len(args) < 3 {
+		return withExitCode(ExitSetup, errors.New("limits: incomplete helper arguments"))
+	}
+	argv := args[1:]
+	if err := l.Apply(); err != nil {
+		return withExitCode(ExitSetup, fmt.Errorf("limits: %w", err))
+	}
+	if err := syscall.Exec(argv[0], argv[1:], os.Environ()); err != nil {
+		return withExitCode(ExitSetup, fmt.Errorf("limits: start %s: %w", argv[1], err))
+	}
+	return nil
+}
+
+// explainLimit names the limit behind a failed run when it can tell. CPU and file size
+// overruns are certain: the kernel sends SIGXCPU (then SIGKILL) and SIGXFSZ. A failed
+// allocation or fork only shows up as the gist's own error, so memory is reported when the
+// gist crashed or came close to the limit, and otherwise the limits in force are named as a
+// hint. In the sandbox, the helper passes the gist's signal on as exit status 128+n.
+func explainLimit(err error, l rlimit.Limits, sandboxed bool) error {
+	var child *childExitError
+	if l.IsZero() || !errors.As(err, &child) {
+		return err
+	}
+	sig := syscall.Signal(0)
+	if s, ok := childSignal(child.state); ok {
+		sig = s
+	} else if code := child.state.ExitCode(); sandboxed && code > 128 {
+		sig = syscall.Signal(code - 128)
+	}
+	cpu := child.state.UserTime() + child.state.SystemTime()
+	var maxRSS uint64
+	if ru, ok := child.state.SysUsage().(*syscall.Rusage); ok && ru.Maxrss > 0 {
+		maxRSS = uint64(ru.Maxrss) * 1024
+	}
+
+	var reason string
+	switch {
+	case l.CPU != 0 && (sig == syscall.SIGXCPU || sig == syscall.SIGKILL && cpu >= time.Duration(l.CPUSeconds())*time.Second):
+		reason = fmt.Sprintf("gist stopped: it used up its CPU time limit (%s)", l.CPU)
+	case l.FileSize != 0 && sig == syscall.SIGXFSZ:
+		reason = fmt.Sprintf("gist stopped: it tried to write a file larger than its file size limit (%s)", bytesize.Format(int64(l.FileSize)))
+	case l.Memory != 0 && (sig == syscall.SIGSEGV || sig == syscall.SIGABRT || sig == syscall.SIGBUS || maxRSS >= l.Memory/10*9):
+		reason = fmt.Sprintf("gist stopped: it most likely ran out of memory under its memory limit (%s)", bytesize.Format(int64(l.Memory)))
+	default:
+		var maybe []string
+		if l.Memory != 0 {
+			maybe = append(maybe, "memory "+bytesize.Format(int64(l.Memory)))
+		}
+		if l.Processes != 0 {
+			maybe = append(maybe, fmt.Sprintf("processes %d", l.Processes))
+		}
+		if len(maybe) == 0 || sig != 0 {
+			return err
+		}
+		reason = fmt.Sprintf("%s; it ran with limits (%s), so a failed allocation or fork in its output means it hit one", child.Error(), strings.Join(maybe, ", "))
+	}
+	return &limitError{reason: reason, child: child}
+}
@@ -0,0 +1,46 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leolaurindo/gixt/internal/rlimit"
+)
+
+func TestLimitsNameTheLimitThatStoppedTheGist(t *testing.T) {
+	dir := t.TempDir()
+	cases := []struct {
+		name   string
+		cmd    []string
+		limits rlimit.Limits
+		want   string
+	}{
+		{"cpu", []string{"sh", "-c", "while :; do :; done"}, rlimit.Limits{CPU: time.Second}, "CPU time limit (1s)"},
+		{"file size", []string{"dd", "if=/dev/zero", "of=" + filepath.Join(dir, "big"), "bs=1024", "count=64"}, rlimit.Limits{FileSize: 16 << 10}, "file size limit (16KB)"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := confinement{limits: tc.limits}
+			err := explainLimit(execute(context.Background(), dir, tc.cmd, conf, nil, time.Second), tc.limits, false)
+			var limit *limitError
+			if !errors.As(err, &limit) || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("expected a limit error naming %q, got %v", tc.want, err)
+			}
+			if ExitCode(err) <= 128 {
+				t.Fatalf("exit code %d should carry the signal", ExitCode(err))
+			}
+		})
+	}
+}
+
+func TestLimitsLeaveWellBehavedGistsAlone(t *testing.T) {
+	limits := rlimit.Limits{CPU: 10 * time.Second, FileSize: 1 << 20, Memory: 4 << 30}
+	err := execute(context.Background(), t.TempDir(), []string{"sh", "-c", "echo hi > out"}, confinement{limits: limits}, nil, time.Second)
+	if err := explainLimit(err, limits, false); err != nil {
+		t.Fatalf("run under limits: %v", err)
+	}
+}
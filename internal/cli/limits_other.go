@@ -0,0 +1,21 @@
+//go:build !linux
+
+package cli
+
+import (
+	"os/exec"
+
+	"github.com/leolaurindo/gixt/internal/rlimit"
+)
+
+func limitCommand(*exec.Cmd, rlimit.Limits) error {
+	return rlimit.ErrUnsupported
+}
+
+func runLimitsHelper([]string) error {
+	return rlimit.ErrUnsupported
+}
+
+func explainLimit(err error, _ rlimit.Limits, _ bool) error {
+	return err
+}
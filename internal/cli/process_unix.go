@@ -0,0 +1,77 @@
+//go:build !windows
+
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"unsafe"
+)
+
+var forwardedSignals = []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP}
+
+// setProcessGroup starts the gist as the leader of a new process group. When gixt is the
+// terminal's interactive job (stdin and stdout are both the terminal), that group becomes
+// the foreground group so the gist can read stdin and receives Ctrl-C directly; the returned
+// func hands the terminal back afterwards. In a pipeline (gixt run x | less) the terminal
+// stays with the pipeline and the gist's group runs in the background.
+func setProcessGroup(c *exec.Cmd) func() {
+	if c.SysProcAttr == nil {
+		c.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	c.SysProcAttr.Setpgid = true
+	fd := int(os.Stdin.Fd())
+	fg, err := foregroundGroup(fd)
+	if err != nil || fg != syscall.Getpgrp() {
+		return func() {}
+	}
+	if _, err := foregroundGroup(int(os.Stdout.Fd())); err != nil {
+		return func() {}
+	}
+	c.SysProcAttr.Foreground = true
+	c.SysProcAttr.Ctty = fd
+	return func() {
+		// gixt is a background process at this point; ignore SIGTTOU while reclaiming
+		// the terminal.
+		signal.Ignore(syscall.SIGTTOU)
+		defer signal.Reset(syscall.SIGTTOU)
+		pgrp := int32(syscall.Getpgrp())
+		_, _, _ = syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(syscall.TIOCSPGRP), uintptr(unsafe.Pointer(&pgrp)))
+	}
+}
+
+// wakeGroup continues a background gist's group after a signal, so members stopped by
+// reading the terminal from the background still act on it.
+func wakeGroup(c *exec.Cmd) {
+	if !c.SysProcAttr.Foreground {
+		_ = syscall.Kill(-c.Process.Pid, syscall.SIGCONT)
+	}
+}
+
+func foregroundGroup(fd int) (int, error) {
+	var pgrp int32
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), uintptr(syscall.TIOCGPGRP), uintptr(unsafe.Pointer(&pgrp))); errno != 0 {
+		return 0, errno
+	}
+	return int(pgrp), nil
+}
+
+func signalGroup(c *exec.Cmd, sig os.Signal) error {
+	s, ok := sig.(syscall.Signal)
+	if !ok {
+		return c.Process.Signal(sig)
+	}
+	defer wakeGroup(c)
+	return syscall.Kill(-c.Process.Pid, s)
+}
+
+func stopGroup(c *exec.Cmd) error {
+	defer wakeGroup(c)
+	return syscall.Kill(-c.Process.Pid, syscall.SIGTERM)
+}
+
+func killGroup(c *exec.Cmd) error {
+	return syscall.Kill(-c.Process.Pid, syscall.SIGKILL)
+}
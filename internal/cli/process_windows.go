@@ -0,0 +1,34 @@
+//go:build windows
+
+package cli
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+)
+
+// Ctrl-C reaches every process attached to the console, the gist included, so gixt only
+// needs to survive it while the gist decides how to exit.
+var forwardedSignals = []os.Signal{os.Interrupt}
+
+func setProcessGroup(*exec.Cmd) func() {
+	return func() {}
+}
+
+func signalGroup(*exec.Cmd, os.Signal) error {
+	return nil
+}
+
+// Windows has no graceful stop signal for console programs, so a timeout kills the
+// whole process tree straight away.
+func stopGroup(c *exec.Cmd) error {
+	return killGroup(c)
+}
+
+func killGroup(c *exec.Cmd) error {
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(c.Process.Pid)).Run(); err != nil {
+		return c.Process.Kill()
+	}
+	return nil
+}
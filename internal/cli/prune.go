@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leolaurindo/gixt/internal/bytesize"
+	"github.com/leolaurindo/gixt/internal/cache"
+)
+
+type pruneOpts struct {
+	olderThan  string
+	keepLatest int
+	maxSize    string
+	dryRun     bool
+	cacheDir   string
+}
+
+func handleCachePrune(opts pruneOpts) error {
+	now := time.Now()
+	policy := cache.PrunePolicy{KeepLatest: opts.keepLatest, Now: now}
+	if opts.keepLatest < 0 {
+		return fmt.Errorf("--keep-latest must be zero or more")
+	}
+	if opts.olderThan != "" {
+		d, err := parseAge(opts.olderThan)
+		if err != nil {
+			return err
+		}
+		policy.OlderThan = d
+	}
+	if opts.maxSize != "" {
+		n, err := bytesize.Parse(opts.maxSize)
+		if err != nil {
+			return err
+		}
+		policy.MaxSize = n
+	}
+
+	paths, err := discoverPaths(opts.cacheDir)
+	if err != nil {
+		return err
+	}
+	revs, temps, err := cache.Scan(paths.CacheDir)
+	if err != nil {
+		return fmt.Errorf("scan cache: %w", err)
+	}
+	removals := cache.PlanPrune(revs, policy)
+	stale := cache.StaleTemps(temps, now)
+
+	verb := "removed"
+	if opts.dryRun {
+		verb = "would remove"
+	}
+	var freed int64
+	touched := map[string]bool{}
+	for _, r := range removals {
+		fmt.Printf("%s %s@%s (%s, last used %s): %s\n", verb, r.Key, cache.Shorten(r.SHA), bytesize.Format(r.Size), r.LastUsed.Format("2006-01-02"), r.Reason)
+		if !opts.dryRun {
+			if err := os.RemoveAll(r.Dir); err != nil {
+				return fmt.Errorf("remove %s: %w", r.Dir, err)
+			}
+			touched[filepath.Dir(r.Dir)] = true
+		}
+		freed += r.Size
+	}
+	for _, dir := range stale {
+		size := cache.DirSize(dir)
+		fmt.Printf("%s leftover temp dir %s (%s)\n", verb, dir, bytesize.Format(size))
+		if !opts.dryRun {
+			if err := os.RemoveAll(dir); err != nil {
+				return fmt.Errorf("remove %s: %w", dir, err)
+			}
+		}
+		freed += size
+	}
+	for dir := range touched {
+		if cache.IsEmptyDir(dir) {
+			_ = os.Remove(dir)
+		}
+	}
+
+	if len(removals) == 0 && len(stale) == 0 {
+		fmt.Printf("nothing to prune in %s (%d cached revisions)\n", paths.CacheDir, len(revs))
+		return nil
+	}
+	if opts.dryRun {
+		fmt.Printf("dry run: would free %s from %s\n", bytesize.Format(freed), paths.CacheDir)
+		return nil
+	}
+	fmt.Printf("freed %s from %s\n", bytesize.Format(freed), paths.CacheDir)
+	return nil
+}
+
+// parseAge accepts Go durations plus whole days and weeks ("30d", "2w").
+func parseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if strings.HasSuffix(s, suffix) {
+			n, err := strconv.Atoi(strings.TrimSuffix(s, suffix))
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age %q (use e.g. 30d, 2w or 12h)", s)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (use e.g. 30d, 2w or 12h)", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,18 @@
+package cli
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePruneValues(t *testing.T) {
+	if d, err := parseAge("30d"); err != nil || d != 30*24*time.Hour {
+		t.Fatalf("parseAge(30d) = %v, %v", d, err)
+	}
+	if d, err := parseAge("90m"); err != nil || d != 90*time.Minute {
+		t.Fatalf("parseAge(90m) = %v, %v", d, err)
+	}
+	if _, err := parseAge("soon"); err == nil {
+		t.Fatalf("expected error for bad age")
+	}
+}
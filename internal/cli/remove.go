@@ -16,7 +16,7 @@ import (
 	"github.com/leolaurindo/gixt/internal/index"
 )
 
-func handleRemove(ctx context.Context, cacheList, indexList, bothList, owners []string, cacheOverride string) error {
+func handleRemove(ctx context.Context, cacheList, indexList, bothList, owners []string, cacheOverride string, hostFlag string) error {
 	if len(cacheList)+len(indexList)+len(bothList)+len(owners) == 0 {
 		return errors.New("usage: gixt remove [--cache id/name ...] [--index id/name ...] [--cache-index id/name ...] [--owner owner ...]")
 	}
@@ -25,18 +25,22 @@ func handleRemove(ctx context.Context, cacheList, indexList, bothList, owners []
 	if err != nil {
 		return err
 	}
+	client, host, err := clientForPaths(paths, hostFlag)
+	if err != nil {
+		return err
+	}
 	aliases, _ := alias.Load(paths.AliasFile)
 	idx, _ := index.Load(paths.IndexFile)
 
-	cacheIDs, err := resolveTargets(ctx, cacheList, aliases, paths, idx)
+	cacheIDs, err := resolveTargets(ctx, client, host, cacheList, aliases, paths, idx)
 	if err != nil {
 		return err
 	}
-	indexIDs, err := resolveTargets(ctx, indexList, aliases, paths, idx)
+	indexIDs, err := resolveTargets(ctx, client, host, indexList, aliases, paths, idx)
 	if err != nil {
 		return err
 	}
-	bothIDs, err := resolveTargets(ctx, bothList, aliases, paths, idx)
+	bothIDs, err := resolveTargets(ctx, client, host, bothList, aliases, paths, idx)
 	if err != nil {
 		return err
 	}
@@ -57,18 +61,21 @@ func handleRemove(ctx context.Context, cacheList, indexList, bothList, owners []
 	return nil
 }
 
-func resolveTargets(ctx context.Context, items []string, aliases map[string]string, paths config.Paths, idx index.Index) ([]string, error) {
+func resolveTargets(ctx context.Context, client gist.Client, host string, items []string, aliases map[string]string, paths config.Paths, idx index.Index) ([]string, error) {
 	var out []string
 	for _, it := range items {
-		id := resolveAliasTarget(it, idx)
-		if id == "" {
-			resolved, _, _, err := resolveIdentifier(ctx, it, aliases, paths, false, true, normalizeUserPages(0))
+		key := resolveAliasTarget(it, idx)
+		if gist.IsLikelyGistID(strings.TrimSpace(it)) {
+			key = gist.Key(host, strings.TrimSpace(it))
+		}
+		if key == "" {
+			resolved, _, _, err := resolveIdentifier(ctx, client, host, it, aliases, paths, false, true, normalizeUserPages(0))
 			if err != nil {
 				return nil, err
 			}
-			id = resolved
+			key = resolved
 		}
-		out = append(out, gist.ExtractID(id))
+		out = append(out, key)
 	}
 	return out, nil
 }
@@ -78,7 +85,7 @@ func removeFromIndex(paths config.Paths, idx index.Index, ids []string, owners [
 	orig := len(idx.Entries)
 	filtered := make([]index.Entry, 0, len(idx.Entries))
 	for _, e := range idx.Entries {
-		if idSet[strings.ToLower(strings.TrimSpace(e.ID))] {
+		if idSet[strings.ToLower(e.Key())] {
 			continue
 		}
 		if ownerMatch(owners, e.Owner) {
@@ -100,7 +107,11 @@ func removeFromIndex(paths config.Paths, idx index.Index, ids []string, owners [
 }
 
 func removeFromCache(paths config.Paths, ids []string, owners []string) error {
-	idSet := makeIDSet(ids)
+	dirs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		dirs = append(dirs, cache.CleanName(id))
+	}
+	idSet := makeIDSet(dirs)
 	rootEntries, _ := os.ReadDir(paths.CacheDir)
 	if len(rootEntries) == 0 && len(ids) == 0 && len(owners) == 0 {
 		return nil
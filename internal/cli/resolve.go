@@ -11,16 +11,28 @@ import (
 	"github.com/leolaurindo/gixt/internal/config"
 	"github.com/leolaurindo/gixt/internal/gist"
 	"github.com/leolaurindo/gixt/internal/index"
+	"github.com/leolaurindo/gixt/internal/runner"
 )
 
-func resolveIdentifier(ctx context.Context, input string, aliases map[string]string, paths config.Paths, userLookup bool, descLookup bool, userPages int) (string, string, bool, error) {
+// resolveIdentifier maps user input to a gist key (see gist.Key). host applies to bare IDs
+// and live lookups; URLs, aliases, and index entries carry their own host.
+func resolveIdentifier(ctx context.Context, client gist.Client, host string, input string, aliases map[string]string, paths config.Paths, userLookup bool, descLookup bool, userPages int) (string, string, bool, error) {
 	if val, ok := aliases[input]; ok {
-		return val, "", false, nil
+		return aliasKey(val), "", false, nil
 	}
 
-	id := gist.ExtractID(input)
+	urlHost, id := gist.ExtractHostID(input)
+	if urlHost == "" {
+		// "host/id" is the printed form of a gist key; owner logins cannot contain dots.
+		if strings.Contains(input, "/") {
+			if keyHost, keyID := gist.SplitKey(strings.TrimSpace(input)); strings.Contains(keyHost, ".") && gist.IsLikelyGistID(keyID) {
+				return gist.Key(keyHost, keyID), "", false, nil
+			}
+		}
+		urlHost = host
+	}
 	if gist.IsLikelyGistID(id) {
-		return id, "", false, nil
+		return gist.Key(urlHost, id), "", false, nil
 	}
 
 	idx, err := index.Load(paths.IndexFile)
@@ -38,6 +50,10 @@ func resolveIdentifier(ctx context.Context, input string, aliases map[string]str
 					matches = append(matches, e)
 					continue
 				}
+				if _, ok := e.Command(namePart); ok {
+					matches = append(matches, e)
+					continue
+				}
 				for _, f := range e.Filenames {
 					if filenameMatches(namePart, f) {
 						matches = append(matches, e)
@@ -47,10 +63,10 @@ func resolveIdentifier(ctx context.Context, input string, aliases map[string]str
 			}
 			matches = preferPlatform(matches, namePart)
 			if len(matches) == 1 {
-				return matches[0].ID, matches[0].Owner, true, nil
+				return matches[0].Key(), matches[0].Owner, true, nil
 			}
 			if len(matches) > 1 {
-				return "", "", false, fmt.Errorf("owner/name matches multiple gists for %s: %d candidates (try owner/fullname.ext or add an alias)", ownerPart, len(matches))
+				return "", "", false, withExitCode(ExitResolve, fmt.Errorf("owner/name matches multiple gists for %s: %d candidates (try owner/fullname.ext or add an alias)", ownerPart, len(matches)))
 			}
 		}
 
@@ -60,14 +76,14 @@ func resolveIdentifier(ctx context.Context, input string, aliases map[string]str
 		}
 		matches = preferPlatform(matches, strings.ToLower(strings.TrimSpace(input)))
 		if len(matches) == 1 {
-			return matches[0].ID, matches[0].Owner, true, nil
+			return matches[0].Key(), matches[0].Owner, true, nil
 		}
 		if len(matches) > 1 {
 			var opts []string
 			for _, m := range matches {
-				opts = append(opts, fmt.Sprintf("%s (%s)", m.ID, m.Description))
+				opts = append(opts, fmt.Sprintf("%s (%s)", m.Key(), m.Description))
 			}
-			return "", "", false, fmt.Errorf("friendly name matches multiple gists: %s (disambiguate with owner/name, full filename like name.ext, or an alias)", strings.Join(opts, "; "))
+			return "", "", false, withExitCode(ExitResolve, fmt.Errorf("friendly name matches multiple gists: %s (disambiguate with owner/name, full filename like name.ext, or an alias)", strings.Join(opts, "; ")))
 		}
 	}
 
@@ -75,31 +91,95 @@ func resolveIdentifier(ctx context.Context, input string, aliases map[string]str
 		parts := strings.SplitN(input, "/", 2)
 		ownerPart := parts[0]
 		namePart := strings.ToLower(parts[1])
-		matches, err := findOwnerNameLive(ctx, ownerPart, namePart, userPages, descLookup)
+		matches, err := findOwnerNameLive(ctx, client, host, ownerPart, namePart, userPages, descLookup)
 		if err != nil {
 			return "", "", false, err
 		}
 		matches = preferPlatform(matches, namePart)
 		if len(matches) == 1 {
-			return matches[0].ID, matches[0].Owner, false, nil
+			return matches[0].Key(), matches[0].Owner, false, nil
 		}
 		if len(matches) > 1 {
-			return "", "", false, fmt.Errorf("owner/name matches multiple gists for %s: %d candidates (try owner/fullname.ext or add an alias)", ownerPart, len(matches))
+			return "", "", false, withExitCode(ExitResolve, fmt.Errorf("owner/name matches multiple gists for %s: %d candidates (try owner/fullname.ext or add an alias)", ownerPart, len(matches)))
 		}
 	}
 
 	if id == "" || !gist.IsLikelyGistID(id) {
-		return "", "", false, fmt.Errorf(
-			"could not resolve %q as alias, gist id, URL, indexed name, or owner/name (try `gixt index-mine`, `gixt index-owner`, or `owner/name` with -u)",
-			input,
-		)
+		hint := "try `gixt index-mine`, `gixt index-owner`, or `owner/name` with -u"
+		if suggestions := suggestNames(paths, input); len(suggestions) > 0 {
+			hint = "did you mean " + strings.Join(suggestions, ", ") + "?"
+		}
+		return "", "", false, withExitCode(ExitResolve, fmt.Errorf(
+			"could not resolve %q as alias, gist id, URL, indexed name, or owner/name (%s)",
+			input, hint,
+		))
+	}
+
+	return gist.Key(urlHost, id), "", false, nil
+}
+
+// splitCommand separates a manifest command from "gist:command". URLs, "host:port" forms,
+// and aliases containing ":" are left whole.
+func splitCommand(input string, aliases map[string]string) (string, string) {
+	if _, ok := aliases[input]; ok {
+		return input, ""
+	}
+	i := strings.LastIndex(input, ":")
+	if i <= 0 || !runner.IsCommandName(input[i+1:]) {
+		return input, ""
+	}
+	return input[:i], input[i+1:]
+}
+
+// indexedCommand returns the manifest command of the indexed gist key that input (a bare
+// name or owner/name) named, or "" when input matched a filename instead.
+func indexedCommand(paths config.Paths, key string, input string) string {
+	idx, err := index.Load(paths.IndexFile)
+	if err != nil {
+		return ""
+	}
+	name := input[strings.LastIndex(input, "/")+1:]
+	for _, e := range idx.Entries {
+		if e.Key() != key {
+			continue
+		}
+		if c, ok := e.Command(name); ok {
+			return c
+		}
+	}
+	return ""
+}
+
+// syncIndexCommands records a gist's manifest command names on its index entry, if the gist
+// is indexed, so the commands resolve by name.
+func syncIndexCommands(paths config.Paths, key string, commands []string) error {
+	idx, err := index.Load(paths.IndexFile)
+	if err != nil {
+		return err
+	}
+	for i, e := range idx.Entries {
+		if e.Key() != key {
+			continue
+		}
+		if strings.Join(e.Commands, "\x00") == strings.Join(commands, "\x00") {
+			return nil
+		}
+		idx.Entries[i].Commands = commands
+		return index.Save(paths.IndexFile, idx)
 	}
+	return nil
+}
 
-	return id, "", false, nil
+// aliasKey interprets a stored alias target: a gist key, or a gist URL saved by older versions.
+func aliasKey(val string) string {
+	if host, id := gist.ExtractHostID(val); host != "" {
+		return gist.Key(host, id)
+	}
+	return val
 }
 
-func findOwnerNameLive(ctx context.Context, owner string, nameLower string, pages int, descLookup bool) ([]index.Entry, error) {
-	items, err := gist.ListForOwner(ctx, owner, 100, pages)
+func findOwnerNameLive(ctx context.Context, client gist.Client, host string, owner string, nameLower string, pages int, descLookup bool) ([]index.Entry, error) {
+	items, err := client.ListForOwner(ctx, owner, 100, pages)
 	if err != nil {
 		return nil, err
 	}
@@ -113,6 +193,7 @@ func findOwnerNameLive(ctx context.Context, owner string, nameLower string, page
 				Filenames:   mapFileNames(it.Files),
 				UpdatedAt:   it.UpdatedAt,
 				Owner:       it.Owner.Login,
+				Host:        hostField(host),
 			})
 			continue
 		}
@@ -124,6 +205,7 @@ func findOwnerNameLive(ctx context.Context, owner string, nameLower string, page
 					Filenames:   mapFileNames(it.Files),
 					UpdatedAt:   it.UpdatedAt,
 					Owner:       it.Owner.Login,
+					Host:        hostField(host),
 				})
 				break
 			}
@@ -132,6 +214,15 @@ func findOwnerNameLive(ctx context.Context, owner string, nameLower string, page
 	return matches, nil
 }
 
+// hostField returns the value stored in index entries and cache manifests for host:
+// empty for github.com so existing files keep their shape.
+func hostField(host string) string {
+	if gist.IsDefaultHost(host) {
+		return ""
+	}
+	return gist.NormalizeHost(host)
+}
+
 func mapFileNames(m map[string]gist.File) []string {
 	var out []string
 	for k := range m {
@@ -193,9 +284,9 @@ func preferPlatform(matches []index.Entry, targetLower string) []index.Entry {
 	for _, c := range candidates {
 		for _, ext := range c.exts {
 			if preferred[ext] {
-				if !seen[c.entry.ID] {
+				if !seen[c.entry.Key()] {
 					preferredEntries = append(preferredEntries, c.entry)
-					seen[c.entry.ID] = true
+					seen[c.entry.Key()] = true
 				}
 				break
 			}
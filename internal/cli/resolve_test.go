@@ -4,6 +4,7 @@ import (
 	"context"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/leolaurindo/gixt/internal/config"
@@ -13,7 +14,7 @@ import (
 func TestResolveIdentifierPrefersAliasThenID(t *testing.T) {
 	paths := config.Paths{IndexFile: filepath.Join(t.TempDir(), "index.json")}
 
-	id, owner, fromIndex, err := resolveIdentifier(context.Background(), "cool", map[string]string{"cool": "alias-id"}, paths, false, false, 1)
+	id, owner, fromIndex, err := resolveIdentifier(context.Background(), nil, "", "cool", map[string]string{"cool": "alias-id"}, paths, false, false, 1)
 	if err != nil {
 		t.Fatalf("alias resolution error: %v", err)
 	}
@@ -22,7 +23,7 @@ func TestResolveIdentifierPrefersAliasThenID(t *testing.T) {
 	}
 
 	rawID := "deadbeefcafebabe"
-	id, owner, fromIndex, err = resolveIdentifier(context.Background(), rawID, nil, paths, false, false, 1)
+	id, owner, fromIndex, err = resolveIdentifier(context.Background(), nil, "", rawID, nil, paths, false, false, 1)
 	if err != nil {
 		t.Fatalf("id resolution error: %v", err)
 	}
@@ -50,7 +51,7 @@ func TestResolveIdentifierUsesIndexAndHandlesAmbiguity(t *testing.T) {
 	}
 
 	// Bare name lookup should hit the index.
-	id, owner, fromIndex, err := resolveIdentifier(context.Background(), "tool", nil, paths, false, false, 1)
+	id, owner, fromIndex, err := resolveIdentifier(context.Background(), nil, "", "tool", nil, paths, false, false, 1)
 	if err != nil {
 		t.Fatalf("index resolution error: %v", err)
 	}
@@ -59,14 +60,20 @@ func TestResolveIdentifierUsesIndexAndHandlesAmbiguity(t *testing.T) {
 	}
 
 	// Ambiguous owner/name should error.
-	if _, _, _, err := resolveIdentifier(context.Background(), "charlie/same", nil, paths, false, false, 1); err == nil {
+	if _, _, _, err := resolveIdentifier(context.Background(), nil, "", "charlie/same", nil, paths, false, false, 1); err == nil {
 		t.Fatalf("expected ambiguity error for charlie/same")
 	}
 
 	// Unknown input should error.
-	if _, _, _, err := resolveIdentifier(context.Background(), "missing", nil, paths, false, false, 1); err == nil {
+	if _, _, _, err := resolveIdentifier(context.Background(), nil, "", "missing", nil, paths, false, false, 1); err == nil {
 		t.Fatalf("expected error for missing identifier")
 	}
+
+	// Near misses suggest indexed names.
+	_, _, _, err = resolveIdentifier(context.Background(), nil, "", "othr", nil, paths, false, false, 1)
+	if err == nil || !strings.Contains(err.Error(), "did you mean bob/other?") {
+		t.Fatalf("expected suggestion for othr, got %v", err)
+	}
 }
 
 func TestResolveIdentifierMatchesFullFilename(t *testing.T) {
@@ -84,7 +91,7 @@ func TestResolveIdentifierMatchesFullFilename(t *testing.T) {
 		t.Fatalf("write index: %v", err)
 	}
 
-	id, owner, fromIndex, err := resolveIdentifier(context.Background(), "hello-world.bat", nil, paths, false, false, 1)
+	id, owner, fromIndex, err := resolveIdentifier(context.Background(), nil, "", "hello-world.bat", nil, paths, false, false, 1)
 	if err != nil {
 		t.Fatalf("full filename resolution error: %v", err)
 	}
@@ -92,7 +99,7 @@ func TestResolveIdentifierMatchesFullFilename(t *testing.T) {
 		t.Fatalf("unexpected resolution for full filename: id=%s owner=%s fromIndex=%v", id, owner, fromIndex)
 	}
 
-	id, owner, fromIndex, err = resolveIdentifier(context.Background(), "erin/util.js", nil, paths, false, false, 1)
+	id, owner, fromIndex, err = resolveIdentifier(context.Background(), nil, "", "erin/util.js", nil, paths, false, false, 1)
 	if err != nil {
 		t.Fatalf("owner/full filename resolution error: %v", err)
 	}
@@ -101,7 +108,7 @@ func TestResolveIdentifierMatchesFullFilename(t *testing.T) {
 	}
 
 	// Still resolves without the extension.
-	id, owner, fromIndex, err = resolveIdentifier(context.Background(), "util", nil, paths, false, false, 1)
+	id, owner, fromIndex, err = resolveIdentifier(context.Background(), nil, "", "util", nil, paths, false, false, 1)
 	if err != nil {
 		t.Fatalf("basename resolution error: %v", err)
 	}
@@ -125,7 +132,7 @@ func TestResolveIdentifierPrefersPlatformWhenOnlyPlatformExtensions(t *testing.T
 		t.Fatalf("write index: %v", err)
 	}
 
-	id, _, fromIndex, err := resolveIdentifier(context.Background(), "tool", nil, paths, false, false, 1)
+	id, _, fromIndex, err := resolveIdentifier(context.Background(), nil, "", "tool", nil, paths, false, false, 1)
 	if err != nil {
 		t.Fatalf("platform preference resolution error: %v", err)
 	}
@@ -157,7 +164,67 @@ func TestResolveIdentifierKeepsAmbiguityWhenMixedPlatformAndNeutral(t *testing.T
 		t.Fatalf("write index: %v", err)
 	}
 
-	if _, _, _, err := resolveIdentifier(context.Background(), "tool", nil, paths, false, false, 1); err == nil {
+	if _, _, _, err := resolveIdentifier(context.Background(), nil, "", "tool", nil, paths, false, false, 1); err == nil {
 		t.Fatalf("expected ambiguity when mixed platform + neutral extensions")
 	}
 }
+
+func TestResolveIdentifierQualifiesEnterpriseHost(t *testing.T) {
+	paths := config.Paths{IndexFile: filepath.Join(t.TempDir(), "index.json")}
+	ctx := context.Background()
+
+	cases := map[string]struct{ host, input string }{
+		"ghe.example.com/deadbeefcafe": {"ghe.example.com", "deadbeefcafe"},
+		"deadbeefcafe":                 {"ghe.example.com", "https://gist.github.com/alice/deadbeefcafe"},
+		"ghe.other.com/deadbeefcafe":   {"", "https://ghe.other.com/gist/alice/deadbeefcafe"},
+	}
+	for want, tc := range cases {
+		key, _, _, err := resolveIdentifier(ctx, nil, tc.host, tc.input, nil, paths, false, false, 1)
+		if err != nil {
+			t.Fatalf("resolve %q: %v", tc.input, err)
+		}
+		if key != want {
+			t.Fatalf("resolve %q on %q = %q, want %q", tc.input, tc.host, key, want)
+		}
+	}
+
+	key, _, _, err := resolveIdentifier(ctx, nil, "", "ghe.example.com/deadbeefcafe", nil, paths, false, false, 1)
+	if err != nil || key != "ghe.example.com/deadbeefcafe" {
+		t.Fatalf("printed key should resolve to itself, got %q (%v)", key, err)
+	}
+}
+
+func TestSplitCommandAndIndexedCommands(t *testing.T) {
+	cases := []struct{ in, gist, command string }{
+		{"tool:deploy", "tool", "deploy"},
+		{"alice/tool:deploy", "alice/tool", "deploy"},
+		{"https://gist.github.com/alice/abc123", "https://gist.github.com/alice/abc123", ""},
+		{"ghe.example.com/abc123", "ghe.example.com/abc123", ""},
+		{"my:alias", "my:alias", ""},
+	}
+	for _, c := range cases {
+		g, cmd := splitCommand(c.in, map[string]string{"my:alias": "id"})
+		if g != c.gist || cmd != c.command {
+			t.Errorf("splitCommand(%q) = %q, %q; want %q, %q", c.in, g, cmd, c.gist, c.command)
+		}
+	}
+
+	paths := config.Paths{IndexFile: filepath.Join(t.TempDir(), "index.json")}
+	idx := index.Index{Entries: []index.Entry{{ID: "id1", Owner: "alice", Filenames: []string{"tools.sh", "gixt.json"}}}}
+	if err := index.Save(paths.IndexFile, idx); err != nil {
+		t.Fatalf("write index: %v", err)
+	}
+	if err := syncIndexCommands(paths, "id1", []string{"deploy", "rollback"}); err != nil {
+		t.Fatalf("sync commands: %v", err)
+	}
+	id, _, fromIndex, err := resolveIdentifier(context.Background(), nil, "", "alice/deploy", nil, paths, false, false, 1)
+	if err != nil || id != "id1" || !fromIndex {
+		t.Fatalf("command name should resolve through the index: id=%s fromIndex=%v err=%v", id, fromIndex, err)
+	}
+	if got := indexedCommand(paths, id, "alice/deploy"); got != "deploy" {
+		t.Fatalf("indexedCommand = %q", got)
+	}
+	if got := indexedCommand(paths, id, "tools"); got != "" {
+		t.Fatalf("filename match should not select a command, got %q", got)
+	}
+}
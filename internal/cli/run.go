@@ -5,16 +5,21 @@ import (
 	"errors"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"runtime"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/leolaurindo/gixt/internal/alias"
 	"github.com/leolaurindo/gixt/internal/cache"
 	"github.com/leolaurindo/gixt/internal/config"
+	"github.com/leolaurindo/gixt/internal/deps"
 	"github.com/leolaurindo/gixt/internal/gist"
+	"github.com/leolaurindo/gixt/internal/lock"
+	"github.com/leolaurindo/gixt/internal/rlimit"
 	"github.com/leolaurindo/gixt/internal/runner"
+	"github.com/leolaurindo/gixt/internal/scan"
 )
 
 type runOptions struct {
@@ -35,16 +40,31 @@ type runOptions struct {
 	isolate        bool
 	cwd            bool
 	timeout        time.Duration
+	killGrace      time.Duration
 	yes            bool
 	trustAlways    bool
 	trustAll       bool
 	ignoreManifest bool
+	host           string
+	noLock         bool
+	offline        bool
+	noDeps         bool
+	rebuild        bool
+	file           string
+	env            []string
+	envFiles       []string
+	cleanEnv       bool
+	sandbox        bool
+	limits         rlimit.Spec
+
+	redact  redactor    // masks secret values in printed commands
+	confine confinement // environment and sandbox for the gist's processes
 }
 
 var errViewAborted = errors.New("aborted after view")
 
-func handleRegister(ctx context.Context, id string, ref string, cacheOverride string, update bool) error {
-	id = gist.ExtractID(id)
+func handleRegister(ctx context.Context, target string, ref string, cacheOverride string, update bool, hostFlag string) error {
+	urlHost, id := gist.ExtractHostID(target)
 	if id == "" {
 		return errors.New("usage: gixt register <gist-id|url> [--ref <sha>]")
 	}
@@ -52,9 +72,20 @@ func handleRegister(ctx context.Context, id string, ref string, cacheOverride st
 	if err != nil {
 		return err
 	}
+	client, host, err := clientForPaths(paths, hostFlag)
+	if err != nil {
+		return err
+	}
+	if urlHost != "" {
+		if client, _, err = clientForKey(paths, client, host, gist.Key(urlHost, id)); err != nil {
+			return err
+		}
+		host = urlHost
+	}
+	key := gist.Key(host, id)
 
-	fmt.Printf("fetching gist %s via gh...\n", id)
-	g, err := gist.Fetch(ctx, id, ref)
+	fmt.Printf("fetching gist %s...\n", id)
+	g, err := client.Fetch(ctx, id, ref)
 	if err != nil {
 		return err
 	}
@@ -66,7 +97,7 @@ func handleRegister(ctx context.Context, id string, ref string, cacheOverride st
 		return errors.New("could not determine gist version")
 	}
 	owner := gist.GuessOwner(g)
-	workDir := cache.Dir(paths.CacheDir, id, sha)
+	workDir := cache.Dir(paths.CacheDir, key, sha)
 	if err := cache.EnsureDir(workDir); err != nil {
 		return err
 	}
@@ -76,6 +107,7 @@ func handleRegister(ctx context.Context, id string, ref string, cacheOverride st
 	}
 	manifest := cache.Manifest{
 		GistID:      id,
+		Host:        hostField(host),
 		SHA:         sha,
 		Description: g.Description,
 		Owner:       owner,
@@ -86,7 +118,8 @@ func handleRegister(ctx context.Context, id string, ref string, cacheOverride st
 	if err := cache.SaveManifest(cache.ManifestPath(workDir), manifest); err != nil {
 		return err
 	}
-	fmt.Printf("cached gist %s (%s) at %s\n", cache.Shorten(id), sha, workDir)
+	_ = syncIndexCommands(paths, key, gistCommands(g))
+	fmt.Printf("cached gist %s (%s) at %s\n", cache.Shorten(key), sha, workDir)
 	return nil
 }
 
@@ -97,6 +130,10 @@ func runWithOptions(ctx context.Context, opts runOptions, identifier string, for
 	if err != nil {
 		return err
 	}
+	overrides, err := loadEnvOverrides(opts.envFiles, opts.env, originalCWD)
+	if err != nil {
+		return err
+	}
 
 	if opts.trustAll {
 		settings.Mode = config.TrustAll
@@ -117,6 +154,19 @@ func runWithOptions(ctx context.Context, opts runOptions, identifier string, for
 		fmt.Printf("%scache mode 'never': using temp dir; cache untouched%s\n", clrInfo, clrReset)
 	}
 
+	if opts.offline && (opts.update || opts.updateIndex || opts.clearCache) {
+		return errors.New("--offline cannot be combined with --update, --update-index, or --clear-cache")
+	}
+	if opts.offline && opts.userLookup {
+		fmt.Printf("%s--offline: skipping live user lookup%s\n", clrWarn, clrReset)
+		opts.userLookup = false
+	}
+
+	host := activeHost(settings, opts.host)
+	client, err := newGistClient(settings, host)
+	if err != nil {
+		return err
+	}
 	aliases, err := alias.Load(paths.AliasFile)
 	if err != nil {
 		return err
@@ -128,21 +178,63 @@ func runWithOptions(ctx context.Context, opts runOptions, identifier string, for
 		}
 	}
 
-	resolvedID, owner, resolvedFromIndex, err := resolveIdentifier(ctx, identifier, aliases, paths, opts.userLookup, opts.descLookup, opts.userPages)
+	var lockFile lock.File
+	var lockPath string
+	if !opts.noLock {
+		if path, ok := lock.Find(originalCWD); ok {
+			if lockFile, err = lock.Load(path); err != nil {
+				return err
+			}
+			lockPath = path
+		}
+	}
+
+	identifier, command := splitCommand(identifier, aliases)
+
+	var resolvedKey, owner string
+	var resolvedFromIndex bool
+	lockName, locked, isLocked := lockFile.Lookup(identifier, "")
+	if isLocked {
+		resolvedKey = locked.Gist
+	} else {
+		resolvedKey, owner, resolvedFromIndex, err = resolveIdentifier(ctx, client, host, identifier, aliases, paths, opts.userLookup, opts.descLookup, opts.userPages)
+		if err != nil {
+			return err
+		}
+		lockName, locked, isLocked = lockFile.Lookup(identifier, resolvedKey)
+		if command == "" && resolvedFromIndex {
+			command = indexedCommand(paths, resolvedKey, identifier)
+		}
+	}
+	if isLocked {
+		switch {
+		case opts.ref == "":
+			opts.ref = locked.SHA
+			if opts.verbose {
+				fmt.Printf("%susing %s from %s (%s)%s\n", clrInfo, cache.Shorten(locked.SHA), lockPath, lockName, clrReset)
+			}
+		case opts.ref != locked.SHA:
+			fmt.Printf("%s--ref %s overrides %s pinned in %s%s\n", clrWarn, cache.Shorten(opts.ref), cache.Shorten(locked.SHA), lockPath, clrReset)
+			isLocked = false
+		}
+	}
+	client, resolvedID, err := clientForKey(paths, client, host, resolvedKey)
 	if err != nil {
 		return err
 	}
+	gistHost, _ := gist.SplitKey(resolvedKey)
 
 	if settings.TrustedGists == nil {
 		settings.TrustedGists = map[string]bool{}
 	}
-	if opts.verbose {
-		fmt.Printf("fetching gist %s via gh...\n", resolvedID)
-	}
-	g, err := gist.Fetch(ctx, resolvedID, opts.ref)
+	g, cachedOwner, offline, err := fetchGist(ctx, paths, settings, client, resolvedKey, resolvedID, opts.ref, opts.offline, opts.verbose)
 	if err != nil {
 		return err
 	}
+	// Trust follows the owner recorded when the revision was cached.
+	if cachedOwner != "" {
+		owner = cachedOwner
+	}
 	sha := g.LatestVersion()
 	if sha == "" {
 		sha = opts.ref
@@ -154,8 +246,9 @@ func runWithOptions(ctx context.Context, opts runOptions, identifier string, for
 		owner = gist.GuessOwner(g)
 	}
 
-	effectiveNoCache := opts.noCache || settings.CacheMode == config.CacheModeDefault
-	workDir, cleanup, err := prepareWorkDir(paths.CacheDir, resolvedID, sha, effectiveNoCache, opts.verbose)
+	// Offline runs execute straight from the cached revision.
+	effectiveNoCache := !offline && (opts.noCache || settings.CacheMode == config.CacheModeDefault)
+	workDir, cleanup, err := prepareWorkDir(paths.CacheDir, resolvedKey, sha, effectiveNoCache, opts.verbose)
 	if err != nil {
 		return err
 	}
@@ -196,15 +289,25 @@ func runWithOptions(ctx context.Context, opts runOptions, identifier string, for
 	if err != nil {
 		return err
 	}
+	if isLocked {
+		if problems := locked.Verify(workDir, files); len(problems) > 0 {
+			return fmt.Errorf("gist %s does not match %s: %s (run `gixt lock verify` or `gixt lock update %s`)", lockName, lockPath, strings.Join(problems, "; "), lockName)
+		}
+	}
 
 	manifest := cache.Manifest{
 		GistID:      resolvedID,
+		Host:        hostField(gistHost),
 		SHA:         sha,
 		Description: g.Description,
 		Owner:       owner,
 		Files:       files,
 		Source:      g.HTMLURL,
 		CreatedAt:   time.Now(),
+		LastUsed:    time.Now(),
+	}
+	if prev, err := cache.LoadManifest(cache.ManifestPath(workDir)); err == nil && prev.SHA == sha && !prev.CreatedAt.IsZero() {
+		manifest.CreatedAt = prev.CreatedAt
 	}
 	if !opts.noCache {
 		if err := cache.SaveManifest(cache.ManifestPath(workDir), manifest); err != nil {
@@ -231,34 +334,257 @@ func runWithOptions(ctx context.Context, opts runOptions, identifier string, for
 		return nil
 	}
 
-	trusted := trustDecision(ctx, settings, owner, resolvedID, opts.yes || opts.trustAlways)
-	if !trusted {
-		if err := promptTrust(manifest, workDir); err != nil {
+	// The manifest command and declared args are checked before the trust prompt so usage
+	// mistakes and --help never need an approval.
+	rm, hasManifest, err := loadRunManifest(workDir, manifestFile)
+	if err != nil {
+		return err
+	}
+	file := opts.file
+	if _, isCommand := rm.Commands[command]; command != "" && !isCommand && slices.Contains(files, command) {
+		// gixt <gist>:<name> names a file when the manifest has no command by that name.
+		if file != "" {
+			return withExitCode(ExitResolve, fmt.Errorf("%s:%s already names a file; drop --file %s", identifier, command, file))
+		}
+		file, command = command, ""
+	}
+	if file != "" && command != "" {
+		return withExitCode(ExitResolve, fmt.Errorf("use either %s:%s or --file %s, not both", identifier, command, file))
+	}
+	if file != "" && !slices.Contains(files, file) {
+		return withExitCode(ExitResolve, fmt.Errorf("gist %s has no file %q (files: %s)", resolvedKey, file, strings.Join(files, ", ")))
+	}
+	switch {
+	case file != "":
+		// The file runs by shebang or extension; the manifest run and args do not apply.
+	case hasManifest:
+		if helped, err := preflightManifest(rm, identifier, command, files, forwarded, originalCWD); err != nil || helped {
+			return err
+		}
+	case command != "":
+		return withExitCode(ExitResolve, fmt.Errorf("gist %s has no %s manifest and no file named %q, so %q names nothing to run", resolvedKey, manifestFile, command, command))
+	}
+
+	limits, err := gistLimits(opts.limits, rm.Limits, settings.Limits)
+	if err != nil {
+		return err
+	}
+	if !limits.IsZero() && !rlimit.Supported {
+		fmt.Printf("%sresource limits are not enforced on %s; running without them%s\n", clrWarn, runtime.GOOS, clrReset)
+		limits = rlimit.Limits{}
+	}
+
+	trustClient := client
+	if offline {
+		trustClient = nil // "mine" needs the authenticated login, which is a network call
+	}
+	trust := trustReasonFor(ctx, trustClient, settings, owner, resolvedKey, opts.yes || opts.trustAlways)
+	policy := policyTrustReason(ctx, trustClient, settings, owner, resolvedKey, trust, opts.trustAlways)
+	var findings []scan.Finding
+	if trust == untrusted || inScope(settings.ScanBlock, policy) {
+		if findings, err = scan.Dir(workDir, files); err != nil {
+			return err
+		}
+		if err := blockedByScan(findings, settings.ScanBlock, policy); err != nil {
 			return err
 		}
 	}
+	if trust == untrusted {
+		details := trustDetails{
+			findings:    findings,
+			secrets:     rm.SecretNames(),
+			permissions: rm.Permissions.List(),
+			declared:    rm.Permissions != nil,
+			sandboxed:   opts.sandbox || inScope(settings.Sandbox, untrusted),
+		}
+		if err := promptTrust(manifest, workDir, details); err != nil {
+			return withExitCode(ExitTrust, err)
+		}
+	}
+	if pinsRevision(settings.TrustPin, trust) {
+		prevSHA := settings.TrustedRevisions[resolvedKey]
+		if prevSHA != "" && prevSHA != sha {
+			var prev gist.Gist
+			if offline {
+				prev, _, err = cachedRevision(paths.CacheDir, resolvedKey, prevSHA)
+			} else {
+				prev, err = client.Fetch(ctx, resolvedID, prevSHA)
+			}
+			if err != nil {
+				return fmt.Errorf("fetch last trusted revision %s: %w", cache.Shorten(prevSHA), err)
+			}
+			if err := promptRevision(resolvedKey, prevSHA, sha, revisionDiff(prev, g)); err != nil {
+				return withExitCode(ExitTrust, err)
+			}
+		}
+		if prevSHA != sha {
+			recordTrustedRevision(&settings, resolvedKey, sha)
+			if err := config.SaveSettings(paths.Settings, settings); err != nil {
+				return err
+			}
+		}
+	}
 	if opts.trustAlways {
-		settings.TrustedGists[resolvedID] = true
+		settings.TrustedGists[resolvedKey] = true
+		if settings.TrustPin != config.TrustPinOff {
+			recordTrustedRevision(&settings, resolvedKey, sha)
+		}
 		if err := config.SaveSettings(paths.Settings, settings); err != nil {
 			return err
 		}
-		fmt.Printf("trusted gist %s permanently.\n", resolvedID)
+		fmt.Printf("trusted gist %s permanently.\n", resolvedKey)
+	}
+	// Command names reach the index only once the gist is trusted, so a declined gist cannot
+	// make its names resolve.
+	if hasManifest {
+		if err := syncIndexCommands(paths, resolvedKey, rm.CommandNames()); err != nil && opts.verbose {
+			fmt.Printf("%scould not record manifest commands in the index: %v%s\n", clrWarn, err, clrReset)
+		}
+	}
+
+	opts.confine.baseEnv = childEnviron()
+	if opts.cleanEnv || inScope(settings.CleanEnv, policy) {
+		kept, withheld := scrubEnv(opts.confine.baseEnv, envAllowlist(settings))
+		opts.confine.baseEnv = kept
+		if opts.verbose {
+			if len(withheld) == 0 {
+				fmt.Printf("%sclean env: nothing to withhold%s\n", clrInfo, clrReset)
+			} else {
+				fmt.Printf("%sclean env: withheld %d %s: %s%s\n", clrInfo, len(withheld), pluralize(len(withheld), "variable", "variables"), strings.Join(withheld, ", "), clrReset)
+			}
+		}
+	}
+	if opts.sandbox || inScope(settings.Sandbox, policy) {
+		if err := sandboxAvailable(); err != nil {
+			return withExitCode(ExitSetup, fmt.Errorf("%w (drop --sandbox, or turn it off with `gixt config-sandbox --mode off`)", err))
+		}
+		scratch, err := cache.MkdirTemp(paths.CacheDir, "sandbox-")
+		if err != nil {
+			return fmt.Errorf("create sandbox temp dir: %w", err)
+		}
+		defer os.RemoveAll(scratch)
+		home, _ := os.UserHomeDir()
+		spec := newSandboxSpec(rm.Permissions, workDir, originalCWD, scratch, home, paths.ConfigDir)
+		opts.confine.sandbox = &spec
+		if opts.verbose && len(spec.hidden) > 0 {
+			fmt.Printf("%ssandbox: hiding %s%s\n", clrInfo, strings.Join(spec.hidden, ", "), clrReset)
+		}
 	}
 
-	resolvedArgs := resolveUserArgs(forwarded, originalCWD)
-	cmd, envAdd, reason, err := runner.BuildCommand(workDir, manifestFile, files, resolvedArgs, execDir)
+	var envs []deps.Env
+	if !opts.noDeps {
+		if envs, err = deps.Detect(workDir, files); err != nil {
+			return fmt.Errorf("detect dependencies: %w (use --no-deps to skip)", err)
+		}
+	}
+	interpreters, envVars := envRequest(envs)
+	req := runner.Request{
+		Dir:          workDir,
+		ManifestPath: manifestFile,
+		Files:        files,
+		Args:         forwarded,
+		ExecDir:      execDir,
+		CallerDir:    originalCWD,
+		Command:      command,
+		File:         file,
+		Interpreters: interpreters,
+		EnvVars:      envVars,
+		BuildDir:     filepath.Join(workDir, buildDirName),
+
+		EnvOverrides: overrides,
+		LookupEnv:    environLookup(opts.confine.baseEnv),
+
+		InterpreterCandidates: interpreterCandidates(settings),
+	}
+	resolved, err := runner.Resolve(req)
+	if err != nil {
+		return err
+	}
+	// Secrets are read only now, after the trust check, and only those the manifest declares.
+	if len(resolved.Secrets) > 0 && !opts.dryRun {
+		if req.Secrets, err = gistSecrets(paths, resolvedKey, resolved.Secrets, overrides); err != nil {
+			return err
+		}
+		opts.redact = newRedactor(req.Secrets)
+		if resolved, err = runner.Resolve(req); err != nil {
+			return err
+		}
+	}
+	missingEnv := runner.MissingEnv(resolved, req.LookupEnv)
+	if len(missingEnv) > 0 && !opts.dryRun {
+		values, err := askRequiredEnv(resolvedKey, missingEnv)
+		if err != nil {
+			return err
+		}
+		if req.EnvOverrides == nil {
+			req.EnvOverrides = map[string]string{}
+		}
+		for k, v := range values {
+			req.EnvOverrides[k] = v
+		}
+		if resolved, err = runner.Resolve(req); err != nil {
+			return err
+		}
+	}
+	setup, hasSetup, err := runner.SetupCommand(req)
 	if err != nil {
 		return err
 	}
+	cmd, envAdd, reason := resolved.Argv, resolved.Env, resolved.Reason
 	if opts.printCmd || opts.dryRun {
 		if opts.ignoreManifest {
 			reason = reason + " (manifest ignored)"
 		}
-		fmt.Printf("command (%s): %s\n", reason, strings.Join(cmd, " "))
+		for _, e := range envs {
+			fmt.Printf("environment: %s in %s\n", e.Describe(), e.Dir)
+		}
+		if opts.dryRun && len(missingEnv) > 0 {
+			fmt.Printf("%srequired env not set: %s%s\n", clrWarn, strings.Join(missingEnv, ", "), clrReset)
+		}
+		if len(resolved.Secrets) > 0 {
+			note := ""
+			if opts.dryRun {
+				note = " (read from the secret store at run time)"
+			}
+			fmt.Printf("secrets: %s%s\n", strings.Join(resolved.Secrets, ", "), note)
+		}
+		if sb := opts.confine.sandbox; sb != nil {
+			fmt.Printf("sandbox: %s\n", sb.describe())
+		}
+		if !limits.IsZero() {
+			fmt.Printf("limits: %s\n", strings.Join(limits.Describe(), ", "))
+		}
+		if hasSetup {
+			fmt.Printf("%s: %s\n", setup.Reason, opts.redact.apply(runner.FormatArgv(setup.Argv)))
+		}
+		if b := resolved.Build; b != nil {
+			state := "not built yet"
+			if b.Built() && !opts.rebuild {
+				state = "cached"
+			}
+			fmt.Printf("build (%s, %s): %s\n", b.Toolchain, state, runner.FormatArgv(b.Argv))
+		}
+		if resolved.Entry != "" {
+			fmt.Printf("file: %s (%s)\n", resolved.Entry, resolved.EntryRule)
+		}
+		fmt.Printf("command (%s): %s\n", reason, opts.redact.apply(runner.FormatArgv(cmd)))
 	}
 	if opts.dryRun {
 		return nil
 	}
+	if err := installEnvs(ctx, workDir, envs, opts.update, opts); err != nil {
+		return err
+	}
+	if hasSetup {
+		if err := runSetup(ctx, workDir, files, setup, opts.update, opts); err != nil {
+			return err
+		}
+	}
+	if resolved.Build != nil {
+		if err := ensureBuilt(ctx, *resolved.Build, opts.rebuild, opts.verbose, opts.confine.toolchain()); err != nil {
+			return err
+		}
+	}
 
 	runCtx := ctx
 	var cancel context.CancelFunc
@@ -267,12 +593,22 @@ func runWithOptions(ctx context.Context, opts runOptions, identifier string, for
 		defer cancel()
 	}
 
-	return execute(runCtx, execDir, cmd, envAdd)
+	conf := opts.confine
+	conf.limits = limits
+	err = execute(runCtx, execDir, cmd, conf, envAdd, opts.killGrace)
+	var timeout *timeoutError
+	if errors.As(err, &timeout) {
+		if timeout.killed {
+			return withExitCode(ExitTimeout, fmt.Errorf("gist timed out after %s and was killed after a %s grace period", opts.timeout, opts.killGrace))
+		}
+		return withExitCode(ExitTimeout, fmt.Errorf("gist timed out after %s and was stopped", opts.timeout))
+	}
+	return explainLimit(err, limits, conf.sandbox != nil)
 }
 
-func prepareWorkDir(cacheRoot, gistID, sha string, temp bool, verbose bool) (string, func(), error) {
+func prepareWorkDir(cacheRoot, gistKey, sha string, temp bool, verbose bool) (string, func(), error) {
 	if temp {
-		tmpDir, err := os.MkdirTemp(cacheRoot, "gixt-")
+		tmpDir, err := cache.MkdirTemp(cacheRoot, "")
 		if err != nil {
 			return "", nil, fmt.Errorf("create temp dir: %w", err)
 		}
@@ -286,35 +622,46 @@ func prepareWorkDir(cacheRoot, gistID, sha string, temp bool, verbose bool) (str
 		return tmpDir, func() { _ = os.RemoveAll(tmpDir) }, nil
 	}
 
-	workDir := cache.Dir(cacheRoot, gistID, sha)
+	workDir := cache.Dir(cacheRoot, gistKey, sha)
 	if err := cache.EnsureDir(workDir); err != nil {
 		return "", nil, fmt.Errorf("prepare work dir: %w", err)
 	}
 	return workDir, nil, nil
 }
 
-func resolveUserArgs(args []string, originalCWD string) []string {
-	resolved := make([]string, 0, len(args))
-	for _, a := range args {
-		if filepath.IsAbs(a) {
-			resolved = append(resolved, a)
-			continue
-		}
-		candidate := filepath.Clean(filepath.Join(originalCWD, a))
-		if _, err := os.Stat(candidate); err == nil {
-			resolved = append(resolved, candidate)
-			continue
-		}
-		resolved = append(resolved, a)
-	}
-	return resolved
+// trustDetails is what the trust prompt shows from the gist's run manifest.
+type trustDetails struct {
+	secrets     []string // secret names the gist may receive
+	permissions []string // sandbox permissions the manifest asks for
+	declared    bool     // the manifest has a permissions block
+	sandboxed   bool     // the run will be sandboxed if approved
+
+	findings []scan.Finding // risk scan results, most severe first
 }
 
-func promptTrust(m cache.Manifest, dir string) error {
-	fmt.Printf("%sAbout to run gist %s (owner: %s)%s\n", clrTitle, cache.Shorten(m.GistID), m.Owner, clrReset)
+func promptTrust(m cache.Manifest, dir string, details trustDetails) error {
+	fmt.Printf("%sAbout to run gist %s (owner: %s)%s\n", clrTitle, cache.Shorten(m.Key()), m.Owner, clrReset)
 	fmt.Printf("Description: %s\n", strings.TrimSpace(m.Description))
 	fmt.Printf("Commit: %s\n", cache.Shorten(m.SHA))
 	fmt.Printf("Files: %s\n", strings.Join(m.Files, ", "))
+	if len(details.secrets) > 0 {
+		fmt.Printf("%sSecrets: %s (from your secret store)%s\n", clrWarn, strings.Join(details.secrets, ", "), clrReset)
+	}
+	if details.declared || details.sandboxed {
+		perms := "none"
+		if len(details.permissions) > 0 {
+			perms = strings.Join(details.permissions, ", ")
+		}
+		enforced := "enforced by the sandbox"
+		if !details.sandboxed {
+			enforced = "not enforced; the sandbox is off"
+		}
+		fmt.Printf("%sPermissions: %s (%s)%s\n", clrWarn, perms, enforced, clrReset)
+	}
+	if len(details.findings) > 0 {
+		fmt.Printf("%sRisk scan: %s%s\n", clrWarn, scan.Summary(details.findings), clrReset)
+		printFindings(details.findings, promptFindings)
+	}
 	fmt.Printf("%sTip: manage trust defaults with `gixt config-trust --mode mine|all --owner <name>`.%s\n", clrInfo, clrReset)
 	fmt.Printf("%sProceed? [y/N/v]: %s", clrPrompt, clrReset)
 	var resp string
@@ -343,16 +690,64 @@ func viewFiles(m cache.Manifest, dir string) error {
 	return errViewAborted
 }
 
-func execute(ctx context.Context, dir string, cmd []string, envAdd map[string]string) error {
-	c := exec.CommandContext(ctx, cmd[0], cmd[1:]...)
-	c.Dir = dir
-	c.Stdin = os.Stdin
-	c.Stdout = os.Stdout
-	c.Stderr = os.Stderr
-	c.Env = os.Environ()
-	for k, v := range envAdd {
-		c.Env = append(c.Env, fmt.Sprintf("%s=%s", k, v))
+// loadRunManifest loads the run manifest named name from dir, reporting false when the gist
+// has none.
+func loadRunManifest(dir, name string) (runner.RunManifest, bool, error) {
+	if name == "" {
+		return runner.RunManifest{}, false, nil
+	}
+	path := filepath.Join(dir, name)
+	if !cache.PathExists(path) {
+		return runner.RunManifest{}, false, nil
+	}
+	m, err := runner.LoadRunManifest(path)
+	if err != nil {
+		return runner.RunManifest{}, false, err
+	}
+	return m, true, nil
+}
+
+// preflightManifest selects the manifest command and validates its entry file and declared
+// args. It prints the generated usage and reports true when the args ask for help.
+func preflightManifest(rm runner.RunManifest, prog string, command string, files []string, forwarded []string, callerDir string) (bool, error) {
+	sel, name, args, err := rm.Select(command, forwarded)
+	if err != nil {
+		if wantsHelp(forwarded) {
+			fmt.Print(runner.Usage(prog, rm))
+			return true, nil
+		}
+		return false, withExitCode(ExitResolve, fmt.Errorf("%w (see `gixt %s --help`)", err, prog))
 	}
+	// "gixt <gist> --help" on a gist with commands lists them, even when a default exists.
+	if command == "" && len(args) == len(forwarded) && len(rm.Commands) > 0 && wantsHelp(args) {
+		fmt.Print(runner.Usage(prog, rm))
+		return true, nil
+	}
+	if _, _, err := sel.PlatformRun(runtime.GOOS, runtime.GOARCH); err != nil && !wantsHelp(args) {
+		return false, err
+	}
+	if sel.Entry != "" && !slices.Contains(files, sel.Entry) {
+		return false, withExitCode(ExitResolve, fmt.Errorf("manifest entry %q is not a file in the gist (files: %s)", sel.Entry, strings.Join(files, ", ")))
+	}
+	if sel.Args == nil {
+		return false, nil
+	}
+	if name != "" {
+		prog = prog + ":" + name
+	}
+	if _, err := runner.ParseArgs(*sel.Args, args, callerDir); err != nil {
+		if errors.Is(err, runner.ErrHelp) {
+			fmt.Print(runner.Usage(prog, sel))
+			return true, nil
+		}
+		return false, fmt.Errorf("%w (see `gixt %s --help`)", err, prog)
+	}
+	return false, nil
+}
 
-	return c.Run()
+func wantsHelp(args []string) bool {
+	if len(args) > 0 && args[0] == "--" {
+		args = args[1:]
+	}
+	return len(args) > 0 && (args[0] == "--help" || args[0] == "-h")
 }
@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/leolaurindo/gixt/internal/index"
+	"github.com/leolaurindo/gixt/internal/secret"
+)
+
+func TestDeclaredArgsAreCheckedBeforeTrust(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"deadbeefcafe","owner":{"login":"alice"},"files":{
+			"gixt.json":{"filename":"gixt.json","content":"{\"run\":\"echo {{name}}\",\"args\":{\"positional\":[{\"name\":\"name\",\"required\":true}]}}"},
+			"hello.sh":{"filename":"hello.sh","content":"echo hi"}},"history":[{"version":"rev1"}]}`)
+	})
+	ctx := context.Background()
+	// No --yes: reaching the trust prompt would fail on the test's empty stdin.
+	opts := runOptions{cacheDir: t.TempDir(), manifestFile: "gixt.json", isolate: true}
+
+	if err := runWithOptions(ctx, opts, "deadbeefcafe", []string{"--help"}); err != nil {
+		t.Fatalf("--help should print usage without prompting: %v", err)
+	}
+	err := runWithOptions(ctx, opts, "deadbeefcafe", nil)
+	if err == nil || !strings.Contains(err.Error(), "missing required argument <name>") {
+		t.Fatalf("expected usage error before trust, got %v", err)
+	}
+}
+
+func TestUnsupportedPlatformIsRefusedBeforeTrust(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"deadbeefcafe","owner":{"login":"alice"},"files":{
+			"gixt.json":{"filename":"gixt.json","content":"{\"run\":{\"plan9\":\"rc tool.rc\"}}"},
+			"tool.rc":{"filename":"tool.rc","content":"echo hi"}},"history":[{"version":"rev1"}]}`)
+	})
+	opts := runOptions{cacheDir: t.TempDir(), manifestFile: "gixt.json", isolate: true}
+
+	err := runWithOptions(context.Background(), opts, "deadbeefcafe", nil)
+	if err == nil || !strings.Contains(err.Error(), "unsupported platform: run has commands for plan9") {
+		t.Fatalf("expected platform error before trust, got %v", err)
+	}
+}
+
+func TestFileSelectorRunsNamedFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"deadbeefcafe","owner":{"login":"alice"},"files":{
+			"gixt.json":{"filename":"gixt.json","content":"{\"run\":\"exit 0\",\"commands\":{\"lint\":{\"run\":\"exit 4\"}}}"},
+			"lint":{"filename":"lint","content":"exit 9"},
+			"helper.sh":{"filename":"helper.sh","content":"exit 7"}},"history":[{"version":"rev1"}]}`)
+	})
+	ctx := context.Background()
+	opts := runOptions{cacheDir: t.TempDir(), manifestFile: "gixt.json", isolate: true, yes: true}
+
+	cases := map[string]int{
+		"deadbeefcafe:helper.sh": 7, // a file, since no command has that name
+		"deadbeefcafe:lint":      4, // the command wins over the file of the same name
+	}
+	for identifier, want := range cases {
+		if got := ExitCode(runWithOptions(ctx, opts, identifier, nil)); got != want {
+			t.Fatalf("%s: expected exit %d, got %d", identifier, want, got)
+		}
+	}
+	opts.file = "helper.sh"
+	if got := ExitCode(runWithOptions(ctx, opts, "deadbeefcafe", nil)); got != 7 {
+		t.Fatalf("--file helper.sh: expected exit 7, got %d", got)
+	}
+
+	opts.file = "missing.sh"
+	err := runWithOptions(ctx, opts, "deadbeefcafe", nil)
+	if ExitCode(err) != ExitResolve || !strings.Contains(err.Error(), `no file "missing.sh"`) {
+		t.Fatalf("expected missing file error, got %v", err)
+	}
+}
+
+func TestRequiredEnvFailsWithoutTerminal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"deadbeefcafe","owner":{"login":"alice"},"files":{
+			"gixt.json":{"filename":"gixt.json","content":"{\"run\":\"test \\\"$GIXT_TEST_TOKEN\\\" = from-file\",\"required_env\":[\"GIXT_TEST_TOKEN\"]}"}},"history":[{"version":"rev1"}]}`)
+	})
+	orig := stdinIsTerminal
+	stdinIsTerminal = func() bool { return false }
+	t.Cleanup(func() { stdinIsTerminal = orig })
+	ctx := context.Background()
+	opts := runOptions{cacheDir: t.TempDir(), manifestFile: "gixt.json", isolate: true, yes: true}
+
+	err := runWithOptions(ctx, opts, "deadbeefcafe", nil)
+	if err == nil || !strings.Contains(err.Error(), "requires GIXT_TEST_TOKEN") {
+		t.Fatalf("expected required env error, got %v", err)
+	}
+
+	envFile := filepath.Join(t.TempDir(), "test.env")
+	if err := os.WriteFile(envFile, []byte("GIXT_TEST_TOKEN=from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	opts.envFiles = []string{envFile}
+	if err := runWithOptions(ctx, opts, "deadbeefcafe", nil); err != nil {
+		t.Fatalf("run with --env-file: %v", err)
+	}
+	opts.env = []string{"GIXT_TEST_TOKEN=from-flag"}
+	if got := ExitCode(runWithOptions(ctx, opts, "deadbeefcafe", nil)); got != 1 {
+		t.Fatalf("--env should override --env-file, got exit %d", got)
+	}
+}
+
+func TestDeclaredSecretsAreInjectedAfterTrust(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"deadbeefcafe","owner":{"login":"alice"},"files":{
+			"gixt.json":{"filename":"gixt.json","content":"{\"run\":\"test \\\"$GIXT_TEST_SECRET\\\" = s3cret && test -z \\\"$GIXT_SECRET_PASSPHRASE$GIXT_OTHER_SECRET\\\"\",\"secrets\":[\"GIXT_TEST_SECRET\"]}"}},"history":[{"version":"rev1"}]}`)
+	})
+	origIterations := secret.Iterations
+	secret.Iterations = 1000
+	t.Cleanup(func() { secret.Iterations = origIterations })
+	t.Setenv(secret.PassphraseEnv, "correct horse")
+	origTerminal := stdinIsTerminal
+	stdinIsTerminal = func() bool { return false }
+	t.Cleanup(func() { stdinIsTerminal = origTerminal })
+	ctx := context.Background()
+	opts := runOptions{cacheDir: t.TempDir(), manifestFile: "gixt.json", isolate: true, yes: true}
+
+	err := runWithOptions(ctx, opts, "deadbeefcafe", nil)
+	if err == nil || !strings.Contains(err.Error(), "gixt secret set GIXT_TEST_SECRET") {
+		t.Fatalf("expected missing secret error, got %v", err)
+	}
+
+	if err := handleSecretSet("GIXT_TEST_SECRET", "s3cret", true); err != nil {
+		t.Fatalf("secret set: %v", err)
+	}
+	if err := handleSecretSet("GIXT_OTHER_SECRET", "undeclared", true); err != nil {
+		t.Fatalf("secret set: %v", err)
+	}
+	if err := handleSecretEncrypt(); err != nil {
+		t.Fatalf("secret encrypt: %v", err)
+	}
+	if err := runWithOptions(ctx, opts, "deadbeefcafe", nil); err != nil {
+		t.Fatalf("run with declared secret: %v", err)
+	}
+
+	if got := newRedactor(map[string]string{"A": "s3cret", "B": "s3cret-long"}).apply("x=s3cret-long y=s3cret"); got != "x=*** y=***" {
+		t.Fatalf("unexpected redaction: %q", got)
+	}
+}
+
+func TestDeclinedGistDoesNotIndexCommands(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"deadbeefcafe","owner":{"login":"alice"},"files":{
+			"gixt.json":{"filename":"gixt.json","content":"{\"run\":\"exit 0\",\"commands\":{\"ls\":{\"run\":\"exit 0\"}}}"}},"history":[{"version":"rev1"}]}`)
+	})
+	ctx := context.Background()
+	paths, err := ensurePaths("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := index.Save(paths.IndexFile, index.Index{Entries: []index.Entry{{ID: "deadbeefcafe", Owner: "alice", Filenames: []string{"gixt.json"}}}}); err != nil {
+		t.Fatal(err)
+	}
+	// No --yes: the trust prompt reads the test's empty stdin and declines.
+	opts := runOptions{cacheDir: t.TempDir(), manifestFile: "gixt.json", isolate: true}
+
+	if err := runWithOptions(ctx, opts, "deadbeefcafe", nil); ExitCode(err) != ExitTrust {
+		t.Fatalf("expected the trust prompt to decline, got %v", err)
+	}
+	if got := indexedCommand(paths, "deadbeefcafe", "alice/ls"); got != "" {
+		t.Fatalf("a declined gist recorded command %q in the index", got)
+	}
+	opts.yes = true
+	if err := runWithOptions(ctx, opts, "deadbeefcafe", nil); err != nil {
+		t.Fatalf("trusted run: %v", err)
+	}
+	if got := indexedCommand(paths, "deadbeefcafe", "alice/ls"); got != "ls" {
+		t.Fatalf("a trusted gist should record its commands, got %q", got)
+	}
+}
@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/leolaurindo/gixt/internal/config"
+	"github.com/leolaurindo/gixt/internal/envvar"
+	"github.com/leolaurindo/gixt/internal/rlimit"
+	"github.com/leolaurindo/gixt/internal/runner"
+)
+
+// sandboxHelperCommand, as gixt's first argument, makes it act as the helper that sets up
+// the sandbox from inside its namespaces and then starts the gist. It is not a user command.
+const sandboxHelperCommand = "__sandbox"
+
+// confinement is how gist processes are restricted: the environment they start from and,
+// on Linux, the sandbox and resource limits.
+type confinement struct {
+	baseEnv []string      // nil means gixt's own environment
+	sandbox *sandboxSpec  // nil runs the gist unsandboxed
+	limits  rlimit.Limits // set only for the gist itself, not setup or dependency installs
+}
+
+// environ is the environment gist processes start from.
+func (c confinement) environ() []string {
+	if c.baseEnv == nil {
+		return childEnviron()
+	}
+	return append([]string(nil), c.baseEnv...)
+}
+
+// toolchain returns the confinement for dependency installs and compiles. They run gist
+// code too (npm install scripts, setup.py, build.rs), so they stay in the sandbox, but with
+// network access to fetch packages, the toolchain variables a clean environment withholds
+// from the gist, and without the gist's resource limits.
+func (c confinement) toolchain() confinement {
+	out := confinement{baseEnv: withToolchainEnv(c.baseEnv)}
+	if c.sandbox != nil {
+		sb := *c.sandbox
+		sb.network = true
+		sb.toolchain = true
+		out.sandbox = &sb
+	}
+	return out
+}
+
+// sandboxSpec describes a sandboxed run. Paths must be absolute.
+type sandboxSpec struct {
+	writable  []string // dirs the gist may write; the rest of the file system is read-only
+	hidden    []string // credential files and dirs replaced by empty ones
+	network   bool     // share gixt's network instead of an empty network namespace
+	tmpDir    string   // scratch dir passed as TMPDIR; also in writable
+	toolchain bool     // an install or compile: package caches move into tmpDir
+}
+
+// toolchainCacheEnv points the npm, pip, Go and Cargo caches, which normally live in the
+// read-only home directory, into the sandbox scratch dir. Go's module cache is made
+// writable so the scratch dir can be removed afterwards.
+func toolchainCacheEnv(scratch string, lookup envvar.Lookup) []string {
+	goflags := "-modcacherw"
+	if v, ok := lookup("GOFLAGS"); ok && strings.TrimSpace(v) != "" {
+		goflags = v + " " + goflags
+	}
+	return []string{
+		"npm_config_cache=" + filepath.Join(scratch, "npm"),
+		"PIP_CACHE_DIR=" + filepath.Join(scratch, "pip"),
+		"GOCACHE=" + filepath.Join(scratch, "go-build"),
+		"GOMODCACHE=" + filepath.Join(scratch, "go-mod"),
+		"GOFLAGS=" + goflags,
+		"CARGO_HOME=" + filepath.Join(scratch, "cargo"),
+	}
+}
+
+// credentialPaths lists files and dirs under home that commonly hold credentials.
+func credentialPaths(home string) []string {
+	names := []string{
+		".ssh", ".gnupg", ".aws", ".azure", ".kube", ".docker", ".netrc", ".git-credentials",
+		".npmrc", ".pypirc", ".config/gh", ".config/gcloud", ".config/hub",
+	}
+	paths := make([]string, len(names))
+	for i, n := range names {
+		paths[i] = filepath.Join(home, filepath.FromSlash(n))
+	}
+	return paths
+}
+
+// newSandboxSpec builds the sandbox for a gist declaring perms. The work dir and scratch dir
+// are writable, and the caller's directory too when the gist asks for write_cwd. Credential
+// paths in home and gixt's config dir, which holds the secret store, are hidden unless a
+// writable dir lies inside them.
+func newSandboxSpec(perms *runner.Permissions, workDir, callerDir, scratch, home, configDir string) sandboxSpec {
+	spec := sandboxSpec{writable: []string{workDir, scratch}, tmpDir: scratch}
+	if perms != nil {
+		spec.network = perms.Network
+		if perms.WriteCWD && callerDir != "" && !pathWithin(callerDir, spec.writable) {
+			spec.writable = append(spec.writable, callerDir)
+		}
+	}
+	var candidates []string
+	if home != "" {
+		candidates = credentialPaths(home)
+	}
+	if configDir != "" {
+		candidates = append(candidates, configDir)
+	}
+	for _, p := range candidates {
+		if _, err := os.Stat(p); err != nil {
+			continue
+		}
+		covers := false
+		for _, w := range spec.writable {
+			if pathWithin(w, []string{p}) {
+				covers = true
+			}
+		}
+		if !covers {
+			spec.hidden = append(spec.hidden, p)
+		}
+	}
+	return spec
+}
+
+// pathWithin reports whether path is one of dirs or inside one of them.
+func pathWithin(path string, dirs []string) bool {
+	for _, d := range dirs {
+		if path == d || strings.HasPrefix(path, strings.TrimSuffix(d, string(filepath.Separator))+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s sandboxSpec) describe() string {
+	network := "no network"
+	if s.network {
+		network = "network allowed"
+	}
+	return fmt.Sprintf("%s; writable: %s", network, strings.Join(s.writable, ", "))
+}
+
+func handleConfigSandbox(mode string, show bool) error {
+	paths, settings, err := ensurePathsAndSettings("")
+	if err != nil {
+		return err
+	}
+	if mode != "" {
+		scope, err := parseTrustScope(mode)
+		if err != nil {
+			return fmt.Errorf("unknown sandbox mode %s (expected off|untrusted|all)", mode)
+		}
+		settings.Sandbox = scope
+		if err := config.SaveSettings(paths.Settings, settings); err != nil {
+			return err
+		}
+	}
+	if show || mode != "" {
+		scope := settings.Sandbox
+		if scope == "" {
+			scope = config.ScopeOff
+		}
+		fmt.Printf("Sandbox: %s\n", scope)
+		if err := sandboxAvailable(); err != nil {
+			fmt.Printf("%sNot available here: %v%s\n", clrWarn, err, clrReset)
+		}
+	}
+	return nil
+}
+
+// parseTrustScope parses off|untrusted|all.
+func parseTrustScope(s string) (config.TrustScope, error) {
+	switch scope := config.TrustScope(strings.ToLower(s)); scope {
+	case config.ScopeOff, config.ScopeUntrusted, config.ScopeAll:
+		return scope, nil
+	}
+	return "", fmt.Errorf("unknown scope %s (expected off|untrusted|all)", s)
+}
@@ -0,0 +1,278 @@
+//go:build linux
+
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// sandboxAvailable reports why the sandbox cannot run here, if it cannot.
+func sandboxAvailable() error {
+	if data, err := os.ReadFile("/proc/sys/user/max_user_namespaces"); err == nil && strings.TrimSpace(string(data)) == "0" {
+		return errors.New("the sandbox needs user namespaces, which are disabled (sysctl user.max_user_namespaces=0)")
+	}
+	if data, err := os.ReadFile("/proc/sys/kernel/unprivileged_userns_clone"); err == nil && strings.TrimSpace(string(data)) == "0" && os.Geteuid() != 0 {
+		return errors.New("the sandbox needs unprivileged user namespaces, which are disabled (sysctl kernel.unprivileged_userns_clone=0)")
+	}
+	return nil
+}
+
+// sandboxCommand makes c start through the sandbox helper, in new user, mount and PID
+// namespaces and, unless the spec allows network, a new network namespace. Inside, the
+// helper is root of its user namespace, which maps to the caller's uid, so no real
+// privileges are needed.
+func sandboxCommand(c *exec.Cmd, spec sandboxSpec) error {
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("sandbox: locate gixt: %w", err)
+	}
+	uid, gid := os.Getuid(), os.Getgid()
+	args := []string{self, sandboxHelperCommand, "--uid", strconv.Itoa(uid), "--gid", strconv.Itoa(gid)}
+	if spec.network {
+		args = append(args, "--network")
+	}
+	for _, w := range spec.writable {
+		args = append(args, "--write", w)
+	}
+	for _, h := range spec.hidden {
+		args = append(args, "--hide", h)
+	}
+	args = append(args, "--", c.Path)
+	c.Path, c.Args = self, append(args, c.Args...)
+
+	flags := uintptr(syscall.CLONE_NEWUSER | syscall.CLONE_NEWNS | syscall.CLONE_NEWPID)
+	if !spec.network {
+		flags |= syscall.CLONE_NEWNET
+	}
+	c.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:  flags,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: uid, Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: 0, HostID: gid, Size: 1}},
+	}
+	return nil
+}
+
+// runSandboxHelper runs as root of the sandbox's namespaces. It makes every mount read-only
+// except the writable dirs, hides credential paths, mounts a /proc for the new PID
+// namespace and brings up loopback, then runs the gist in a nested user namespace as the
+// caller's own uid, so it holds no capabilities and cannot undo the mounts.
+func runSandboxHelper(args []string) error {
+	spec, uid, gid, argv, err := parseSandboxArgs(args)
+	if err != nil {
+		return withExitCode(ExitSetup, fmt.Errorf("sandbox: %w", err))
+	}
+	// The working directory still refers to the mount underneath any bind made below, so the
+	// gist is started in it again by path.
+	wd, err := os.Getwd()
+	if err != nil {
+		return withExitCode(ExitSetup, fmt.Errorf("sandbox: %w", err))
+	}
+	if err := confineMounts(spec); err != nil {
+		return withExitCode(ExitSetup, fmt.Errorf("sandbox: %w (unprivileged user namespaces may be restricted on this system)", err))
+	}
+	if !spec.network {
+		_ = loopbackUp()
+	}
+
+	c := &exec.Cmd{Path: argv[0], Args: argv[1:], Dir: wd, Stdin: os.Stdin, Stdout: os.Stdout, Stderr: os.Stderr}
+	c.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags:  syscall.CLONE_NEWUSER,
+		UidMappings: []syscall.SysProcIDMap{{ContainerID: uid, HostID: 0, Size: 1}},
+		GidMappings: []syscall.SysProcIDMap{{ContainerID: gid, HostID: 0, Size: 1}},
+	}
+	// Signals reach the gist through the process group; the helper only has to outlive it.
+	signal.Notify(make(chan os.Signal, 1), forwardedSignals...)
+	if err := c.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return &childExitError{state: exitErr.ProcessState}
+		}
+		return withExitCode(ExitSetup, fmt.Errorf("sandbox: start %s: %w", argv[1], err))
+	}
+	return nil
+}
+
+// parseSandboxArgs reads the helper arguments written by sandboxCommand. argv is the program
+// path followed by its argv.
+func parseSandboxArgs(args []string) (sandboxSpec, int, int, []string, error) {
+	var spec sandboxSpec
+	uid, gid := -1, -1
+	for len(args) > 0 {
+		arg := args[0]
+		args = args[1:]
+		if arg == "--" {
+			break
+		}
+		if arg == "--network" {
+			spec.network = true
+			continue
+		}
+		if len(args) == 0 {
+			return spec, 0, 0, nil, fmt.Errorf("missing value for %s", arg)
+		}
+		val := args[0]
+		args = args[1:]
+		var err error
+		switch arg {
+		case "--uid":
+			uid, err = strconv.Atoi(val)
+		case "--gid":
+			gid, err = strconv.Atoi(val)
+		case "--write":
+			spec.writable = append(spec.writable, val)
+		case "--hide":
+			spec.hidden = append(spec.hidden, val)
+		default:
+			return spec, 0, 0, nil, fmt.Errorf("unknown helper argument %s", arg)
+		}
+		if err != nil {
+			return spec, 0, 0, nil, fmt.Errorf("%s: %w", arg, err)
+		}
+	}
+	if uid < 0 || gid < 0 || len(args) < 2 {
+		return spec, 0, 0, nil, errors.New("incomplete helper arguments")
+	}
+	return spec, uid, gid, args, nil
+}
+
+func confineMounts(spec sandboxSpec) error {
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("make mounts private: %w", err)
+	}
+	// Binding each writable dir onto itself gives it a mount of its own that stays writable
+	// when everything else is remounted read-only below.
+	for _, dir := range spec.writable {
+		if err := syscall.Mount(dir, dir, "", syscall.MS_BIND|syscall.MS_REC, ""); err != nil {
+			return fmt.Errorf("bind %s: %w", dir, err)
+		}
+	}
+	mounts, err := mountPoints()
+	if err != nil {
+		return err
+	}
+	for _, m := range mounts {
+		if pathWithin(m, spec.writable) {
+			continue
+		}
+		if err := remountReadOnly(m); err != nil {
+			return err
+		}
+	}
+	for _, p := range spec.hidden {
+		if err := hidePath(p); err != nil {
+			return err
+		}
+	}
+	if err := syscall.Mount("proc", "/proc", "proc", syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, ""); err != nil {
+		return fmt.Errorf("mount /proc: %w", err)
+	}
+	return nil
+}
+
+// mountPoints lists the mount points from /proc/self/mountinfo.
+func mountPoints() ([]string, error) {
+	fh, err := os.Open("/proc/self/mountinfo")
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+	var points []string
+	scanner := bufio.NewScanner(fh)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 5 {
+			continue
+		}
+		points = append(points, unescapeMountPath(fields[4]))
+	}
+	return points, scanner.Err()
+}
+
+// unescapeMountPath decodes the \ooo octal escapes mountinfo uses for spaces and the like.
+func unescapeMountPath(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+3 < len(s) {
+			if n, err := strconv.ParseUint(s[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(n))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+// remountReadOnly makes the mount at path read-only. nosuid, nodev and noexec are kept, as
+// a user namespace may not clear them; atime flags are left out so they are preserved.
+func remountReadOnly(path string) error {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		if errors.Is(err, syscall.EACCES) || errors.Is(err, syscall.ENOENT) {
+			return nil // out of reach from here, so also out of the gist's reach
+		}
+		return fmt.Errorf("inspect mount %s: %w", path, err)
+	}
+	const stNoSuid, stNoDev, stNoExec = 0x2, 0x4, 0x8
+	flags := uintptr(syscall.MS_BIND | syscall.MS_REMOUNT | syscall.MS_RDONLY)
+	for bit, ms := range map[int64]uintptr{stNoSuid: syscall.MS_NOSUID, stNoDev: syscall.MS_NODEV, stNoExec: syscall.MS_NOEXEC} {
+		if int64(st.Flags)&bit != 0 {
+			flags |= ms
+		}
+	}
+	if err := syscall.Mount("", path, "", flags, ""); err != nil && !errors.Is(err, syscall.ENOENT) {
+		return fmt.Errorf("make %s read-only: %w", path, err)
+	}
+	return nil
+}
+
+// hidePath covers a credential dir with an empty read-only tmpfs, or a file with /dev/null.
+func hidePath(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	if info.IsDir() {
+		err = syscall.Mount("tmpfs", path, "tmpfs", syscall.MS_RDONLY|syscall.MS_NOSUID|syscall.MS_NODEV|syscall.MS_NOEXEC, "size=4k,mode=0755")
+	} else {
+		err = syscall.Mount("/dev/null", path, "", syscall.MS_BIND, "")
+	}
+	if err != nil {
+		return fmt.Errorf("hide %s: %w", path, err)
+	}
+	return nil
+}
+
+// loopbackUp brings up lo in the new network namespace, so gists can still talk to
+// themselves over localhost.
+func loopbackUp() error {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return err
+	}
+	defer syscall.Close(fd)
+	var ifr [40]byte // struct ifreq: name, then the flags union
+	copy(ifr[:syscall.IFNAMSIZ], "lo")
+	flags := (*uint16)(unsafe.Pointer(&ifr[syscall.IFNAMSIZ]))
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.SIOCGIFFLAGS, uintptr(unsafe.Pointer(&ifr))); errno != 0 {
+		return errno
+	}
+	*flags |= syscall.IFF_UP | syscall.IFF_RUNNING
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), syscall.SIOCSIFFLAGS, uintptr(unsafe.Pointer(&ifr))); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/leolaurindo/gixt/internal/runner"
+)
+
+func TestSandboxConfinesWritesAndNetwork(t *testing.T) {
+	if err := sandboxAvailable(); err != nil {
+		t.Skip(err)
+	}
+	if err := exec.Command("unshare", "-U", "-r", "-m", "true").Run(); err != nil {
+		t.Skipf("user namespaces are not usable here: %v", err)
+	}
+	outside := t.TempDir()
+	workDir := t.TempDir()
+	home := t.TempDir()
+	if err := os.WriteFile(filepath.Join(home, ".netrc"), []byte("machine example.com password hunter2\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	spec := newSandboxSpec(nil, workDir, "", t.TempDir(), home, "")
+
+	run := func(script string) error {
+		return execute(context.Background(), workDir, []string{"sh", "-c", script}, confinement{sandbox: &spec}, nil, time.Second)
+	}
+	if err := run("echo ok > inside && touch \"$TMPDIR/scratch\""); err != nil {
+		t.Fatalf("writing the work dir failed: %v", err)
+	}
+	if err := run("touch " + filepath.Join(outside, "escaped")); err == nil {
+		t.Fatalf("write outside the work dir succeeded")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "escaped")); err == nil {
+		t.Fatalf("file created outside the work dir")
+	}
+	if err := run("! grep -q hunter2 " + filepath.Join(home, ".netrc")); err != nil {
+		t.Fatalf("credential file was readable: %v", err)
+	}
+	if err := run("test \"$(tail -n +3 /proc/net/dev | cut -d: -f1 | tr -d ' ')\" = lo"); err != nil {
+		t.Fatalf("the sandbox has network interfaces besides lo: %v", err)
+	}
+
+	// A timeout's SIGTERM must reach the gist, not just the sandbox helper.
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	err := execute(ctx, workDir, []string{"sh", "-c", "trap 'exit 0' TERM; while :; do sleep 0.05; done"}, confinement{sandbox: &spec}, nil, 5*time.Second)
+	var timeout *timeoutError
+	if !errors.As(err, &timeout) || timeout.killed {
+		t.Fatalf("expected the sandboxed gist to stop on SIGTERM, got %v", err)
+	}
+}
+
+func TestToolchainStepsStaySandboxed(t *testing.T) {
+	if err := sandboxAvailable(); err != nil {
+		t.Skip(err)
+	}
+	if err := exec.Command("unshare", "-U", "-r", "-m", "true").Run(); err != nil {
+		t.Skipf("user namespaces are not usable here: %v", err)
+	}
+	outside := t.TempDir()
+	workDir := t.TempDir()
+	scratch := t.TempDir()
+	spec := newSandboxSpec(nil, workDir, "", scratch, t.TempDir(), "")
+	conf := confinement{sandbox: &spec}.toolchain()
+	if !conf.sandbox.network || spec.network {
+		t.Fatalf("installs get network without granting it to the gist")
+	}
+
+	// An install hook such as npm's postinstall runs here.
+	hook := "test \"$npm_config_cache\" = " + filepath.Join(scratch, "npm") + " && touch " + filepath.Join(outside, "hooked")
+	if err := execute(context.Background(), workDir, []string{"sh", "-c", hook}, conf, nil, time.Second); err == nil {
+		t.Fatalf("install hook wrote outside the work dir")
+	}
+
+	if _, err := exec.LookPath("go"); err != nil || testing.Short() {
+		return
+	}
+	if err := os.WriteFile(filepath.Join(workDir, "hello.go"), []byte("package main\n\nfunc main() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cmd, err := runner.Resolve(runner.Request{Dir: workDir, Files: []string{"hello.go"}, ExecDir: workDir, BuildDir: filepath.Join(workDir, buildDirName)})
+	if err != nil || cmd.Build == nil {
+		t.Fatalf("expected a build step: %+v %v", cmd, err)
+	}
+	if err := ensureBuilt(context.Background(), *cmd.Build, false, false, conf); err != nil {
+		t.Fatalf("sandboxed build: %v", err)
+	}
+	if err := os.RemoveAll(scratch); err != nil {
+		t.Fatalf("scratch dir should be removable after a build: %v", err)
+	}
+}
@@ -0,0 +1,22 @@
+//go:build !linux
+
+package cli
+
+import (
+	"errors"
+	"os/exec"
+)
+
+var errSandboxUnsupported = errors.New("the sandbox uses Linux namespaces and is only available on Linux")
+
+func sandboxAvailable() error {
+	return errSandboxUnsupported
+}
+
+func sandboxCommand(*exec.Cmd, sandboxSpec) error {
+	return errSandboxUnsupported
+}
+
+func runSandboxHelper([]string) error {
+	return errSandboxUnsupported
+}
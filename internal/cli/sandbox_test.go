@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/leolaurindo/gixt/internal/runner"
+)
+
+// TestMain lets the test binary act as the sandbox and limits helpers, as gixt does when it
+// re-executes itself for a sandboxed or limited run.
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && (os.Args[1] == sandboxHelperCommand || os.Args[1] == limitsHelperCommand) {
+		if err := Execute(context.Background(), os.Args[1:]); err != nil {
+			Exit(err)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func TestNewSandboxSpecHidesCredentialsOutsideWritableDirs(t *testing.T) {
+	home := t.TempDir()
+	for _, p := range []string{".ssh", ".aws", "project"} {
+		if err := os.Mkdir(filepath.Join(home, p), 0o700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	configDir := filepath.Join(home, ".config", "gixt")
+	if err := os.MkdirAll(configDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	workDir := filepath.Join(configDir, "work")
+	caller := filepath.Join(home, "project")
+
+	spec := newSandboxSpec(&runner.Permissions{WriteCWD: true}, workDir, caller, filepath.Join(home, "tmp"), home, configDir)
+	if want := []string{filepath.Join(home, ".ssh"), filepath.Join(home, ".aws")}; !slices.Equal(spec.hidden, want) {
+		t.Fatalf("hidden = %v, want %v (config dir holds the work dir, so it stays visible)", spec.hidden, want)
+	}
+	if !slices.Contains(spec.writable, caller) || spec.network {
+		t.Fatalf("unexpected spec %+v", spec)
+	}
+
+	spec = newSandboxSpec(nil, workDir, caller, filepath.Join(home, "tmp"), home, configDir)
+	if slices.Contains(spec.writable, caller) {
+		t.Fatalf("caller dir writable without write_cwd: %v", spec.writable)
+	}
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/leolaurindo/gixt/internal/alias"
+	"github.com/leolaurindo/gixt/internal/cache"
+	"github.com/leolaurindo/gixt/internal/config"
+	"github.com/leolaurindo/gixt/internal/scan"
+)
+
+// promptFindings is how many scan findings the trust prompt lists before pointing to
+// `gixt scan`.
+const promptFindings = 5
+
+// handleScan fetches a gist into a scratch dir and lists its risk scan findings. It shares
+// only resolution and fetching with runs, so scanning never prompts, records trust, or
+// touches the cache.
+func handleScan(ctx context.Context, identifier, ref, cacheDir, hostFlag string, offline bool) error {
+	paths, settings, err := ensurePathsAndSettings(cacheDir)
+	if err != nil {
+		return err
+	}
+	host := activeHost(settings, hostFlag)
+	client, err := newGistClient(settings, host)
+	if err != nil {
+		return err
+	}
+	aliases, err := alias.Load(paths.AliasFile)
+	if err != nil {
+		return err
+	}
+	identifier, _ = splitCommand(identifier, aliases)
+	key, _, _, err := resolveIdentifier(ctx, client, host, identifier, aliases, paths, false, false, normalizeUserPages(0))
+	if err != nil {
+		return err
+	}
+	client, id, err := clientForKey(paths, client, host, key)
+	if err != nil {
+		return err
+	}
+	g, _, _, err := fetchGist(ctx, paths, settings, client, key, id, ref, offline, false)
+	if err != nil {
+		return err
+	}
+
+	dir, err := cache.MkdirTemp(paths.CacheDir, "")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	files, _, err := materializeFiles(g, dir, true)
+	if err != nil {
+		return err
+	}
+	findings, err := scan.Dir(dir, files)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Scanned %d %s of gist %s: %s\n", len(files), pluralize(len(files), "file", "files"), cache.Shorten(key), scan.Summary(findings))
+	printFindings(findings, 0)
+	return nil
+}
+
+// printFindings lists up to limit findings (all when limit is 0) with the matching line
+// under each.
+func printFindings(findings []scan.Finding, limit int) {
+	for i, f := range findings {
+		if limit > 0 && i == limit {
+			fmt.Printf("  %s... and %d more; `gixt scan <gist>` lists them all%s\n", clrDim, len(findings)-limit, clrReset)
+			return
+		}
+		color := clrDim
+		switch f.Severity {
+		case scan.High:
+			color = clrError
+		case scan.Medium:
+			color = clrWarn
+		}
+		fmt.Printf("  %s%-6s%s %s  %s: %s\n", color, strings.ToUpper(f.Severity.String()), clrReset, f.Location(), f.Rule, f.Message)
+		fmt.Printf("         %s%s%s\n", clrDim, f.Text, clrReset)
+	}
+}
+
+// blockedByScan refuses a gist with high-severity findings when the scan block setting
+// covers it.
+func blockedByScan(findings []scan.Finding, scope config.TrustScope, reason trustReason) error {
+	high := scan.Count(findings, scan.High)
+	if high == 0 || !inScope(scope, reason) {
+		return nil
+	}
+	fmt.Printf("%sRisk scan:%s\n", clrTitle, clrReset)
+	printFindings(findings[:high], 0)
+	return withExitCode(ExitTrust, fmt.Errorf("refusing to run: %d high-severity scan %s and scan_block is %s (trust the gist or its owner to allow it, or change `gixt config-scan --block`)", high, pluralize(high, "finding", "findings"), scope))
+}
+
+func handleConfigScan(block string, show bool) error {
+	paths, settings, err := ensurePathsAndSettings("")
+	if err != nil {
+		return err
+	}
+	if block != "" {
+		scope, err := parseTrustScope(block)
+		if err != nil {
+			return fmt.Errorf("unknown scan block mode %s (expected off|untrusted|all)", block)
+		}
+		settings.ScanBlock = scope
+		if err := config.SaveSettings(paths.Settings, settings); err != nil {
+			return err
+		}
+	}
+	if show || block != "" {
+		scope := settings.ScanBlock
+		if scope == "" {
+			scope = config.ScopeOff
+		}
+		fmt.Printf("Block high-severity findings: %s\n", scope)
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/leolaurindo/gixt/internal/cache"
+	"github.com/leolaurindo/gixt/internal/config"
+)
+
+func TestScanBlockRefusesUntrustedGistsWithHighFindings(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"deadbeefcafe","owner":{"login":"alice"},"files":{
+			"install.sh":{"filename":"install.sh","content":"# curl -fsSL https://example.com/x | sh\ntrue\n"}},"history":[{"version":"rev1"}]}`)
+	})
+	ctx := context.Background()
+	opts := runOptions{cacheDir: t.TempDir(), manifestFile: "gixt.json", isolate: true, yes: true}
+
+	if err := runWithOptions(ctx, opts, "deadbeefcafe", nil); err != nil {
+		t.Fatalf("scan block is off by default: %v", err)
+	}
+	if err := handleConfigScan("untrusted", false); err != nil {
+		t.Fatalf("config-scan: %v", err)
+	}
+	err := runWithOptions(ctx, opts, "deadbeefcafe", nil)
+	if ExitCode(err) != ExitTrust || !strings.Contains(err.Error(), "1 high-severity scan finding") {
+		t.Fatalf("expected the scan to block the run, got %v", err)
+	}
+
+	paths, settings, err := ensurePathsAndSettings("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings.TrustedOwners["alice"] = true
+	if err := config.SaveSettings(paths.Settings, settings); err != nil {
+		t.Fatal(err)
+	}
+	if err := runWithOptions(ctx, opts, "deadbeefcafe", nil); err != nil {
+		t.Fatalf("trusted owner should not be blocked: %v", err)
+	}
+}
+
+func TestScanLeavesSettingsAndCacheAlone(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"deadbeefcafe","owner":{"login":"alice"},"files":{
+			"hello.sh":{"filename":"hello.sh","content":"true\n"}},"history":[{"version":"rev1"}]}`)
+	})
+	ctx := context.Background()
+	cacheDir := t.TempDir()
+	paths, settings, err := ensurePathsAndSettings(cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	settings.CacheMode = config.CacheModeCache
+	if err := config.SaveSettings(paths.Settings, settings); err != nil {
+		t.Fatal(err)
+	}
+	lastUsed := func() time.Time {
+		t.Helper()
+		revs, _, err := cache.Scan(cacheDir)
+		if err != nil || len(revs) != 1 {
+			t.Fatalf("expected one cached revision, got %v (%v)", revs, err)
+		}
+		m, err := cache.LoadManifest(cache.ManifestPath(revs[0].Dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m.LastUsed
+	}
+
+	if err := handleScan(ctx, "deadbeefcafe", "", cacheDir, "", false); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if _, settings, err := ensurePathsAndSettings(cacheDir); err != nil || settings.ExecMode != "" {
+		t.Fatalf("scan should not choose an exec mode, got %q (%v)", settings.ExecMode, err)
+	}
+	if revs, temps, err := cache.Scan(cacheDir); err != nil || len(revs)+len(temps) != 0 {
+		t.Fatalf("scan should leave nothing in the cache, got %v %v (%v)", revs, temps, err)
+	}
+
+	if err := runWithOptions(ctx, runOptions{cacheDir: cacheDir, manifestFile: "gixt.json", isolate: true, yes: true}, "deadbeefcafe", nil); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	used := lastUsed()
+	if err := handleScan(ctx, "deadbeefcafe", "", cacheDir, "", true); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if !lastUsed().Equal(used) {
+		t.Fatalf("scan moved LastUsed from %v to %v", used, lastUsed())
+	}
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/leolaurindo/gixt/internal/cache"
+	"github.com/leolaurindo/gixt/internal/config"
+	"github.com/leolaurindo/gixt/internal/gist"
+	"github.com/leolaurindo/gixt/internal/index"
+	"github.com/leolaurindo/gixt/internal/runner"
+)
+
+// suggestMinScore keeps "did you mean" hints to reasonably close matches.
+const suggestMinScore = 20
+
+func handleSearch(query string, owner string, limit int, asJSON bool) error {
+	if strings.TrimSpace(query) == "" {
+		return errors.New("usage: gixt search <query> [--owner <login>] [--limit N] [--json]")
+	}
+	paths, err := ensurePaths("")
+	if err != nil {
+		return err
+	}
+	idx, err := index.Load(paths.IndexFile)
+	if err != nil {
+		return err
+	}
+	if len(idx.Entries) == 0 {
+		return errors.New("index is empty (run `gixt index-mine` or `gixt index-owner <login>` first)")
+	}
+
+	var results []index.Result
+	for _, r := range index.Search(searchDocuments(paths, idx), query) {
+		if owner != "" && !strings.EqualFold(r.Entry.Owner, owner) {
+			continue
+		}
+		results = append(results, r)
+	}
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+
+	if asJSON {
+		if results == nil {
+			results = []index.Result{}
+		}
+		out, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(out))
+		return nil
+	}
+	if len(results) == 0 {
+		fmt.Printf("no indexed gists match %q\n", query)
+		return nil
+	}
+	printSearchTable(results)
+	return nil
+}
+
+// searchDocuments pairs index entries with the details of their newest cached run manifest.
+func searchDocuments(paths config.Paths, idx index.Index) []index.Document {
+	docs := make([]index.Document, 0, len(idx.Entries))
+	for _, e := range idx.Entries {
+		doc := index.Document{Entry: e}
+		if m, dir, ok := latestManifest(paths.CacheDir, e.Key()); ok {
+			if path := findManifestFile(dir, m.Files); path != "" {
+				if rm, err := runner.LoadRunManifest(path); err == nil && rm.Details != runner.DefaultDetails {
+					doc.Details = rm.Details
+				}
+			}
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+// suggestNames returns up to three "did you mean" candidates for input from the index.
+func suggestNames(paths config.Paths, input string) []string {
+	idx, err := index.Load(paths.IndexFile)
+	if err != nil || len(idx.Entries) == 0 {
+		return nil
+	}
+	var out []string
+	for _, r := range index.Search(searchDocuments(paths, idx), input) {
+		if r.Score < suggestMinScore || len(out) == 3 {
+			break
+		}
+		name := r.Name
+		if r.Entry.Owner != "" {
+			name = r.Entry.Owner + "/" + name
+		}
+		out = append(out, name)
+	}
+	return out
+}
+
+func printSearchTable(results []index.Result) {
+	const (
+		idMax    = 12
+		ownerMax = 18
+		filesMax = 28
+		descMax  = 40
+	)
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Score\tID\tOwner\tFiles\tDescription")
+	fmt.Fprintln(tw, "-----\t--\t-----\t-----\t-----------")
+	for _, r := range results {
+		id := trimCell(cache.Shorten(r.Entry.ID), idMax)
+		if !gist.IsDefaultHost(r.Entry.Host) {
+			id = gist.Key(r.Entry.Host, cache.Shorten(r.Entry.ID))
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n",
+			r.Score,
+			id,
+			trimCell(r.Entry.Owner, ownerMax),
+			trimCell(strings.Join(r.Entry.Filenames, ","), filesMax),
+			trimCell(r.Entry.Description, descMax),
+		)
+	}
+	_ = tw.Flush()
+}
@@ -0,0 +1,290 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/leolaurindo/gixt/internal/config"
+	"github.com/leolaurindo/gixt/internal/envvar"
+	"github.com/leolaurindo/gixt/internal/secret"
+)
+
+// openSecrets loads the secret store, asking for the passphrase when it is encrypted.
+func openSecrets(paths config.Paths) (*secret.Store, error) {
+	return secret.Load(paths.Secrets, secretPassphrase)
+}
+
+// secretPassphrase reads the store passphrase from GIXT_SECRET_PASSPHRASE or, on a terminal,
+// from a hidden prompt.
+func secretPassphrase() (string, error) {
+	if p := os.Getenv(secret.PassphraseEnv); p != "" {
+		return p, nil
+	}
+	if !stdinIsTerminal() {
+		return "", fmt.Errorf("the secret store is encrypted; set %s or run gixt in a terminal", secret.PassphraseEnv)
+	}
+	p, err := readHidden("Secret store passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if p == "" {
+		return "", errors.New("no passphrase given")
+	}
+	return p, nil
+}
+
+// newSecretPassphrase asks twice for a new passphrase; without a terminal it takes
+// GIXT_SECRET_PASSPHRASE.
+func newSecretPassphrase() (string, error) {
+	if !stdinIsTerminal() {
+		if p := os.Getenv(secret.PassphraseEnv); p != "" {
+			return p, nil
+		}
+		return "", fmt.Errorf("set %s or run gixt in a terminal to choose a passphrase", secret.PassphraseEnv)
+	}
+	p, err := readHidden("New passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if p == "" {
+		return "", errors.New("the passphrase cannot be empty (use `gixt secret decrypt` to store secrets unencrypted)")
+	}
+	again, err := readHidden("Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if again != p {
+		return "", errors.New("passphrases do not match")
+	}
+	return p, nil
+}
+
+// readHidden prompts on the terminal and reads a line without echo.
+func readHidden(prompt string) (string, error) {
+	fmt.Printf("%s%s%s", clrPrompt, prompt, clrReset)
+	hidden := echoOff()
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if hidden {
+		echoOn()
+		fmt.Println()
+	}
+	if err != nil && line == "" {
+		return "", fmt.Errorf("read input: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func handleSecretSet(name string, value string, hasValue bool) error {
+	if !envvar.ValidName(name) {
+		return fmt.Errorf("secret name %q must be a valid environment variable name", name)
+	}
+	paths, err := ensurePaths("")
+	if err != nil {
+		return err
+	}
+	store, err := openSecrets(paths)
+	if err != nil {
+		return err
+	}
+	if !hasValue {
+		if stdinIsTerminal() {
+			value, err = readHidden(fmt.Sprintf("Value for %s: ", name))
+		} else {
+			var data []byte
+			data, err = io.ReadAll(os.Stdin)
+			value = strings.TrimRight(string(data), "\r\n")
+		}
+		if err != nil {
+			return fmt.Errorf("read secret value: %w", err)
+		}
+	}
+	if value == "" {
+		return fmt.Errorf("refusing to store an empty value for %s", name)
+	}
+	store.Secrets[name] = value
+	if err := secret.Save(paths.Secrets, store); err != nil {
+		return err
+	}
+	fmt.Printf("stored secret %s\n", name)
+	return nil
+}
+
+func handleSecretGet(name string) error {
+	paths, err := ensurePaths("")
+	if err != nil {
+		return err
+	}
+	store, err := openSecrets(paths)
+	if err != nil {
+		return err
+	}
+	value, ok := store.Secrets[name]
+	if !ok {
+		return withExitCode(ExitResolve, fmt.Errorf("no secret named %s", name))
+	}
+	fmt.Println(value)
+	return nil
+}
+
+func handleSecretList() error {
+	paths, err := ensurePaths("")
+	if err != nil {
+		return err
+	}
+	store, err := openSecrets(paths)
+	if err != nil {
+		return err
+	}
+	names := store.Names()
+	if len(names) == 0 {
+		fmt.Println("no secrets stored (add one with `gixt secret set NAME`)")
+		return nil
+	}
+	for _, name := range names {
+		fmt.Println(name)
+	}
+	if store.Encrypted() {
+		fmt.Printf("%s%d %s, encrypted%s\n", clrDim, len(names), pluralize(len(names), "secret", "secrets"), clrReset)
+	}
+	return nil
+}
+
+func handleSecretRemove(names []string) error {
+	if len(names) == 0 {
+		return errors.New("usage: gixt secret rm NAME...")
+	}
+	paths, err := ensurePaths("")
+	if err != nil {
+		return err
+	}
+	store, err := openSecrets(paths)
+	if err != nil {
+		return err
+	}
+	var unknown []string
+	for _, name := range names {
+		if _, ok := store.Secrets[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		return withExitCode(ExitResolve, fmt.Errorf("no secret named %s", strings.Join(unknown, ", ")))
+	}
+	for _, name := range names {
+		delete(store.Secrets, name)
+	}
+	if err := secret.Save(paths.Secrets, store); err != nil {
+		return err
+	}
+	fmt.Printf("removed %s\n", strings.Join(names, ", "))
+	return nil
+}
+
+// handleSecretEncrypt encrypts the store with a new passphrase, or changes the passphrase of
+// an encrypted store.
+func handleSecretEncrypt() error {
+	paths, err := ensurePaths("")
+	if err != nil {
+		return err
+	}
+	store, err := openSecrets(paths)
+	if err != nil {
+		return err
+	}
+	pass, err := newSecretPassphrase()
+	if err != nil {
+		return err
+	}
+	was := store.Encrypted()
+	store.SetPassphrase(pass)
+	if err := secret.Save(paths.Secrets, store); err != nil {
+		return err
+	}
+	if was {
+		fmt.Println("changed the secret store passphrase")
+	} else {
+		fmt.Println("encrypted the secret store")
+	}
+	return nil
+}
+
+func handleSecretDecrypt() error {
+	paths, err := ensurePaths("")
+	if err != nil {
+		return err
+	}
+	store, err := openSecrets(paths)
+	if err != nil {
+		return err
+	}
+	if !store.Encrypted() {
+		fmt.Println("the secret store is not encrypted")
+		return nil
+	}
+	store.SetPassphrase("")
+	if err := secret.Save(paths.Secrets, store); err != nil {
+		return err
+	}
+	fmt.Println("decrypted the secret store; it is now protected by file permissions only")
+	return nil
+}
+
+// gistSecrets loads the secrets a gist declares from the store. Names already given with
+// --env or --env-file are skipped, and the store is not opened when nothing is left.
+func gistSecrets(paths config.Paths, gistKey string, names []string, overrides map[string]string) (map[string]string, error) {
+	var wanted []string
+	for _, name := range names {
+		if _, ok := overrides[name]; !ok {
+			wanted = append(wanted, name)
+		}
+	}
+	if len(wanted) == 0 {
+		return nil, nil
+	}
+	store, err := openSecrets(paths)
+	if err != nil {
+		return nil, err
+	}
+	values := map[string]string{}
+	var missing []string
+	for _, name := range wanted {
+		v, ok := store.Secrets[name]
+		if !ok {
+			missing = append(missing, name)
+			continue
+		}
+		values[name] = v
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("gist %s uses %s %s, not in the secret store; add %s with `gixt secret set %s`",
+			gistKey, pluralize(len(missing), "secret", "secrets"), strings.Join(missing, ", "), pluralize(len(missing), "it", "them"), missing[0])
+	}
+	return values, nil
+}
+
+// redactor masks secret values in text printed by gixt.
+type redactor []string
+
+func newRedactor(values map[string]string) redactor {
+	var r redactor
+	for _, v := range values {
+		if v != "" {
+			r = append(r, v)
+		}
+	}
+	// Longer values first, so a secret containing another is masked whole.
+	sort.Slice(r, func(i, j int) bool { return len(r[i]) > len(r[j]) })
+	return r
+}
+
+func (r redactor) apply(s string) string {
+	for _, v := range r {
+		s = strings.ReplaceAll(s, v, "***")
+	}
+	return s
+}
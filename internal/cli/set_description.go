@@ -11,7 +11,7 @@ import (
 	"github.com/leolaurindo/gixt/internal/gist"
 )
 
-func handleSetDescription(ctx context.Context, target string, desc string) error {
+func handleSetDescription(ctx context.Context, target string, desc string, hostFlag string) error {
 	desc = strings.TrimSpace(desc)
 	if desc == "" {
 		return errors.New("description cannot be empty")
@@ -21,17 +21,26 @@ func handleSetDescription(ctx context.Context, target string, desc string) error
 	if err != nil {
 		return err
 	}
+	client, activeHost, err := clientForPaths(paths, hostFlag)
+	if err != nil {
+		return err
+	}
 	aliases, err := alias.Load(paths.AliasFile)
 	if err != nil {
 		return err
 	}
 
-	id, ownerHint, _, err := resolveIdentifier(ctx, target, aliases, paths, false, true, normalizeUserPages(0))
+	key, ownerHint, _, err := resolveIdentifier(ctx, client, activeHost, target, aliases, paths, false, true, normalizeUserPages(0))
+	if err != nil {
+		return err
+	}
+	client, id, err := clientForKey(paths, client, activeHost, key)
 	if err != nil {
 		return err
 	}
+	host, _ := gist.SplitKey(key)
 
-	g, err := gist.Fetch(ctx, id, "")
+	g, err := client.Fetch(ctx, id, "")
 	if err != nil {
 		return err
 	}
@@ -44,7 +53,7 @@ func handleSetDescription(ctx context.Context, target string, desc string) error
 		return errors.New("could not determine gist owner")
 	}
 
-	currentUser, err := gist.CurrentUser(ctx)
+	currentUser, err := client.CurrentUser(ctx)
 	if err != nil {
 		return fmt.Errorf("detect current user: %w", err)
 	}
@@ -52,12 +61,12 @@ func handleSetDescription(ctx context.Context, target string, desc string) error
 		return fmt.Errorf("gist %s is owned by %s (you are %s)", cache.Shorten(id), owner, currentUser)
 	}
 
-	updated, err := gist.UpdateDescription(ctx, id, desc)
+	updated, err := client.UpdateDescription(ctx, id, desc)
 	if err != nil {
 		return err
 	}
 
-	if err := refreshIndexAndCache(ctx, paths, updated, false); err != nil {
+	if err := refreshIndexAndCache(ctx, paths, host, updated, false); err != nil {
 		return fmt.Errorf("refresh local cache/index: %w", err)
 	}
 	fmt.Printf("updated description for gist %s\n", cache.Shorten(id))
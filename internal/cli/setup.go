@@ -0,0 +1,113 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/leolaurindo/gixt/internal/cache"
+	"github.com/leolaurindo/gixt/internal/runner"
+)
+
+// setupMarkerName is the file, next to the cache manifest, recording that the manifest's
+// setup step succeeded for the files in the work dir.
+const setupMarkerName = "setup.json"
+
+type setupMarker struct {
+	Fingerprint string    `json:"fingerprint"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// setupError reports a failed setup step or dependency install. It does not wrap the
+// child's exit error, so the failure is printed and exits with ExitSetup instead of passing
+// the setup's status through.
+type setupError struct {
+	step  string // "setup step", "python venv install", ...
+	cause error
+}
+
+func (e *setupError) Error() string {
+	cause := e.cause.Error()
+	var child *childExitError
+	if errors.As(e.cause, &child) {
+		cause = strings.TrimPrefix(cause, "gist ")
+	}
+	return fmt.Sprintf("%s failed: %s; the gist was not run (fix the setup or retry with --update)", e.step, cause)
+}
+
+// runSetup runs the manifest setup step in workDir unless a marker shows it already
+// succeeded for the same command and files. force (--update) always reruns it.
+func runSetup(ctx context.Context, workDir string, files []string, setup runner.Command, force bool, opts runOptions) error {
+	fingerprint, err := setupFingerprint(workDir, files, setup.Argv)
+	if err != nil {
+		return err
+	}
+	markerPath := filepath.Join(workDir, setupMarkerName)
+	if !force {
+		if m, err := loadSetupMarker(markerPath); err == nil && m.Fingerprint == fingerprint {
+			if opts.verbose {
+				fmt.Printf("%ssetup already done at %s%s\n", clrInfo, m.CompletedAt.Format(time.RFC3339), clrReset)
+			}
+			return nil
+		}
+	}
+	fmt.Printf("%srunning setup: %s%s\n", clrInfo, opts.redact.apply(runner.FormatArgv(setup.Argv)), clrReset)
+	if err := execute(ctx, workDir, setup.Argv, opts.confine, setup.Env, opts.killGrace); err != nil {
+		_ = os.Remove(markerPath)
+		return withExitCode(ExitSetup, &setupError{step: "setup step", cause: err})
+	}
+	buf, err := json.MarshalIndent(setupMarker{Fingerprint: fingerprint, CompletedAt: time.Now()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode setup marker: %w", err)
+	}
+	if err := os.WriteFile(markerPath, buf, 0o644); err != nil {
+		return fmt.Errorf("write setup marker: %w", err)
+	}
+	return nil
+}
+
+func loadSetupMarker(path string) (setupMarker, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return setupMarker{}, err
+	}
+	var m setupMarker
+	if err := json.Unmarshal(data, &m); err != nil {
+		return setupMarker{}, fmt.Errorf("parse setup marker: %w", err)
+	}
+	return m, nil
+}
+
+// setupFingerprint hashes the setup argv and the name and content of every gist file, so
+// an edited setup command or changed files trigger the setup again.
+func setupFingerprint(dir string, files []string, argv []string) (string, error) {
+	h := sha256.New()
+	for _, a := range argv {
+		fmt.Fprintf(h, "arg %d:%s\n", len(a), a)
+	}
+	sorted := append([]string(nil), files...)
+	sort.Strings(sorted)
+	for _, f := range sorted {
+		fh, err := os.Open(cache.JoinPath(dir, f))
+		if err != nil {
+			return "", fmt.Errorf("hash %s for setup: %w", f, err)
+		}
+		fileHash := sha256.New()
+		_, err = io.Copy(fileHash, fh)
+		fh.Close()
+		if err != nil {
+			return "", fmt.Errorf("hash %s for setup: %w", f, err)
+		}
+		fmt.Fprintf(h, "file %d:%s %x\n", len(f), f, fileHash.Sum(nil))
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/leolaurindo/gixt/internal/config"
+)
+
+func TestSetupRunsOncePerRevision(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
+	}
+	logPath := filepath.Join(t.TempDir(), "setup.log")
+	manifest := strconv.Quote(fmt.Sprintf(`{"setup": "echo ran >> %s", "run": "true"}`, logPath))
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gists/deadbeefcafe":
+			fmt.Fprintf(w, `{"id":"deadbeefcafe","owner":{"login":"alice"},"files":{"gixt.json":{"filename":"gixt.json","content":%s}},"history":[{"version":"rev1"}]}`, manifest)
+		case "/gists/0123456789ab":
+			fmt.Fprint(w, `{"id":"0123456789ab","owner":{"login":"alice"},"files":{"gixt.json":{"filename":"gixt.json","content":"{\"setup\":\"exit 3\",\"run\":\"true\"}"}},"history":[{"version":"rev1"}]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	cacheDir := t.TempDir()
+	paths, err := ensurePaths(cacheDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := config.SaveSettings(paths.Settings, config.Settings{CacheMode: config.CacheModeCache, ExecMode: config.ExecModeIsolate}); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	opts := runOptions{cacheDir: cacheDir, manifestFile: "gixt.json", yes: true}
+
+	runs := func() int {
+		data, _ := os.ReadFile(logPath)
+		return strings.Count(string(data), "ran")
+	}
+	for i := 0; i < 2; i++ {
+		if err := runWithOptions(ctx, opts, "deadbeefcafe", nil); err != nil {
+			t.Fatalf("run %d: %v", i, err)
+		}
+	}
+	if runs() != 1 {
+		t.Fatalf("setup should run once per revision, ran %d times", runs())
+	}
+	opts.update = true
+	if err := runWithOptions(ctx, opts, "deadbeefcafe", nil); err != nil {
+		t.Fatalf("run with --update: %v", err)
+	}
+	if runs() != 2 {
+		t.Fatalf("--update should rerun setup, ran %d times", runs())
+	}
+
+	err = runWithOptions(ctx, opts, "0123456789ab", nil)
+	if ExitCode(err) != ExitSetup || !strings.Contains(err.Error(), "setup step failed: exited with status 3; the gist was not run") {
+		t.Fatalf("expected setup failure, got %v (exit %d)", err, ExitCode(err))
+	}
+}
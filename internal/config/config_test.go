@@ -0,0 +1,34 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadSettingsValidatesScopes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "settings.json")
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"sandbox":" Untrusted "}`)
+	s, err := LoadSettings(path)
+	if err != nil || s.Sandbox != ScopeUntrusted {
+		t.Fatalf("expected sandbox=untrusted, got %q (%v)", s.Sandbox, err)
+	}
+
+	for _, field := range []string{"clean_env", "sandbox", "scan_block"} {
+		write(`{"` + field + `":"untrustd"}`)
+		if _, err := LoadSettings(path); err == nil || !strings.Contains(err.Error(), "untrustd") {
+			t.Fatalf("expected a typo in %s to be rejected, got %v", field, err)
+		}
+	}
+	if err := SaveSettings(path, Settings{Sandbox: "sometimes"}); err == nil {
+		t.Fatalf("expected an unknown scope to be refused on save")
+	}
+}
@@ -0,0 +1,245 @@
+package deps
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Kind names a language environment gixt can provision for a gist.
+type Kind string
+
+const (
+	KindPython Kind = "python"
+	KindNode   Kind = "node"
+)
+
+const (
+	// VenvDir is the virtualenv directory created inside a gist's work dir.
+	VenvDir = ".gixt-venv"
+	// NodeModulesDir is where npm installs a gist's packages.
+	NodeModulesDir = "node_modules"
+	// RecordFile sits next to the cache manifest and records the environments installed in
+	// that work dir.
+	RecordFile = "envs.json"
+)
+
+// Env is a dependency environment detected from a gist's files.
+type Env struct {
+	Kind     Kind
+	Dir      string   // absolute environment dir (VenvDir or NodeModulesDir in the work dir)
+	Sources  []string // gist files the dependencies come from
+	Packages []string // inline requirements from PEP 723 script metadata
+	Lockfile bool     // package-lock.json is present, so npm ci is used
+
+	fingerprint string
+}
+
+// Record describes an installed environment.
+type Record struct {
+	Fingerprint string    `json:"fingerprint"`
+	Sources     []string  `json:"sources"`
+	Installer   string    `json:"installer"` // interpreter or tool that created it
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Detect inspects the gist files in dir and returns the environments they need:
+// a Python venv for requirements.txt or PEP 723 script metadata, and node_modules for
+// package.json.
+func Detect(dir string, files []string) ([]Env, error) {
+	var envs []Env
+	sorted := append([]string(nil), files...)
+	sort.Strings(sorted)
+
+	py := Env{Kind: KindPython, Dir: filepath.Join(dir, VenvDir)}
+	h := sha256.New()
+	for _, f := range sorted {
+		base := strings.ToLower(filepath.Base(f))
+		switch {
+		case base == "requirements.txt":
+			data, err := os.ReadFile(filepath.Join(dir, f))
+			if err != nil {
+				return nil, fmt.Errorf("read %s: %w", f, err)
+			}
+			py.Sources = append(py.Sources, f)
+			fmt.Fprintf(h, "requirements %s\n%s\n", f, data)
+		case strings.HasSuffix(base, ".py"):
+			data, err := os.ReadFile(filepath.Join(dir, f))
+			if err != nil {
+				return nil, fmt.Errorf("read %s: %w", f, err)
+			}
+			meta, ok, err := ParseScriptMetadata(data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", f, err)
+			}
+			if !ok || len(meta.Dependencies) == 0 {
+				continue
+			}
+			py.Sources = append(py.Sources, f)
+			py.Packages = append(py.Packages, meta.Dependencies...)
+			fmt.Fprintf(h, "script %s\n%s\n", f, strings.Join(meta.Dependencies, "\n"))
+		}
+	}
+	if len(py.Sources) > 0 {
+		py.fingerprint = hex.EncodeToString(h.Sum(nil))
+		envs = append(envs, py)
+	}
+
+	for _, f := range sorted {
+		if f != "package.json" {
+			continue
+		}
+		node := Env{Kind: KindNode, Dir: filepath.Join(dir, NodeModulesDir), Sources: []string{f}}
+		h := sha256.New()
+		for _, name := range []string{"package.json", "package-lock.json"} {
+			data, err := os.ReadFile(filepath.Join(dir, name))
+			if err != nil {
+				if os.IsNotExist(err) {
+					continue
+				}
+				return nil, fmt.Errorf("read %s: %w", name, err)
+			}
+			if name == "package-lock.json" {
+				node.Lockfile = true
+				node.Sources = append(node.Sources, name)
+			}
+			fmt.Fprintf(h, "%s\n%s\n", name, data)
+		}
+		node.fingerprint = hex.EncodeToString(h.Sum(nil))
+		envs = append(envs, node)
+	}
+	return envs, nil
+}
+
+// Fingerprint identifies the dependency declarations the environment was built from.
+func (e Env) Fingerprint() string {
+	return e.fingerprint
+}
+
+// Describe is a one-line summary such as "python venv from requirements.txt".
+func (e Env) Describe() string {
+	what := "python venv"
+	if e.Kind == KindNode {
+		what = "node_modules"
+	}
+	return what + " from " + strings.Join(e.Sources, ", ")
+}
+
+// Installs returns the commands that build the environment, run from the work dir. python
+// is the interpreter used to create the venv.
+func (e Env) Installs(python string) [][]string {
+	switch e.Kind {
+	case KindPython:
+		venvPython := e.Python()
+		cmds := [][]string{{python, "-m", "venv", e.Dir}}
+		for _, src := range e.Sources {
+			if strings.EqualFold(filepath.Base(src), "requirements.txt") {
+				cmds = append(cmds, []string{venvPython, "-m", "pip", "install", "--disable-pip-version-check", "-r", src})
+			}
+		}
+		if len(e.Packages) > 0 {
+			cmds = append(cmds, append([]string{venvPython, "-m", "pip", "install", "--disable-pip-version-check"}, e.Packages...))
+		}
+		return cmds
+	case KindNode:
+		if e.Lockfile {
+			return [][]string{{"npm", "ci", "--no-audit", "--no-fund"}}
+		}
+		return [][]string{{"npm", "install", "--no-audit", "--no-fund"}}
+	}
+	return nil
+}
+
+// Python is the venv's interpreter.
+func (e Env) Python() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(e.Dir, "Scripts", "python.exe")
+	}
+	return filepath.Join(e.Dir, "bin", "python")
+}
+
+// BinDir is prepended to PATH so commands run through the shell find the environment's tools.
+func (e Env) BinDir() string {
+	switch {
+	case e.Kind == KindNode:
+		return filepath.Join(e.Dir, ".bin")
+	case runtime.GOOS == "windows":
+		return filepath.Join(e.Dir, "Scripts")
+	}
+	return filepath.Join(e.Dir, "bin")
+}
+
+// Interpreters maps program names to the environment's binaries, so a gist resolved to
+// `python app.py` runs with the venv's python.
+func (e Env) Interpreters() map[string]string {
+	if e.Kind != KindPython {
+		return nil
+	}
+	return map[string]string{"python": e.Python(), "python3": e.Python()}
+}
+
+// Vars returns environment variables that activate the environment for the gist. path is
+// the PATH to extend.
+func Vars(envs []Env, path string) map[string]string {
+	if len(envs) == 0 {
+		return nil
+	}
+	vars := map[string]string{}
+	dirs := make([]string, 0, len(envs)+1)
+	for _, e := range envs {
+		dirs = append(dirs, e.BinDir())
+		if e.Kind == KindPython {
+			vars["VIRTUAL_ENV"] = e.Dir
+		}
+	}
+	if path != "" {
+		dirs = append(dirs, path)
+	}
+	vars["PATH"] = strings.Join(dirs, string(os.PathListSeparator))
+	return vars
+}
+
+// LoadRecords reads the environments recorded in a work dir. A missing file is empty.
+func LoadRecords(workDir string) (map[Kind]Record, error) {
+	data, err := os.ReadFile(filepath.Join(workDir, RecordFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return map[Kind]Record{}, nil
+		}
+		return nil, err
+	}
+	records := map[Kind]Record{}
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", RecordFile, err)
+	}
+	return records, nil
+}
+
+// SaveRecords writes the environments recorded in a work dir.
+func SaveRecords(workDir string, records map[Kind]Record) error {
+	buf, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode %s: %w", RecordFile, err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, RecordFile), buf, 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", RecordFile, err)
+	}
+	return nil
+}
+
+// Ready reports whether e is already installed from the same declarations.
+func Ready(e Env, records map[Kind]Record) bool {
+	r, ok := records[e.Kind]
+	if !ok || r.Fingerprint != e.fingerprint {
+		return false
+	}
+	_, err := os.Stat(e.Dir)
+	return err == nil
+}
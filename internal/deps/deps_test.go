@@ -0,0 +1,101 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseScriptMetadata(t *testing.T) {
+	src := `#!/usr/bin/env python3
+# /// script
+# requires-python = ">=3.11"
+# dependencies = [
+#   "requests<3",  # http
+#   'rich',
+# ]
+# [tool.uv]
+# exclude-newer = "2024-01-01"
+# ///
+import requests
+`
+	meta, ok, err := ParseScriptMetadata([]byte(src))
+	if err != nil || !ok {
+		t.Fatalf("parse: ok=%v err=%v", ok, err)
+	}
+	if !reflect.DeepEqual(meta.Dependencies, []string{"requests<3", "rich"}) || meta.RequiresPython != ">=3.11" {
+		t.Fatalf("unexpected metadata %+v", meta)
+	}
+
+	if _, ok, err := ParseScriptMetadata([]byte("print('hi')\n")); ok || err != nil {
+		t.Fatalf("plain script: ok=%v err=%v", ok, err)
+	}
+	for _, bad := range []string{
+		"# /// script\n# dependencies = [\"a\"]\n",
+		"# /// script\n# dependencies = [\"a\" \"b\"]\n# ///\n",
+		"# /// script\n# ///\n# /// script\n# ///\n",
+	} {
+		if _, _, err := ParseScriptMetadata([]byte(bad)); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestDetectAndReady(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("requirements.txt", "requests\n")
+	write("tool.py", "# /// script\n# dependencies = [\"rich\"]\n# ///\n")
+	write("package.json", `{"name":"x"}`)
+	write("main.sh", "echo hi")
+
+	envs, err := Detect(dir, []string{"main.sh", "package.json", "requirements.txt", "tool.py"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(envs) != 2 || envs[0].Kind != KindPython || envs[1].Kind != KindNode {
+		t.Fatalf("unexpected envs %+v", envs)
+	}
+	py := envs[0]
+	if py.Describe() != "python venv from requirements.txt, tool.py" || !reflect.DeepEqual(py.Packages, []string{"rich"}) {
+		t.Fatalf("unexpected python env %+v", py)
+	}
+	installs := py.Installs("python3")
+	if len(installs) != 3 || installs[0][0] != "python3" || installs[2][len(installs[2])-1] != "rich" {
+		t.Fatalf("unexpected install commands %q", installs)
+	}
+	if envs[1].Installs("")[0][1] != "install" {
+		t.Fatalf("package.json without a lockfile should use npm install")
+	}
+
+	vars := Vars(envs, "/usr/bin")
+	if vars["VIRTUAL_ENV"] != py.Dir || !strings.HasPrefix(vars["PATH"], py.BinDir()) || !strings.HasSuffix(vars["PATH"], "/usr/bin") {
+		t.Fatalf("unexpected vars %v", vars)
+	}
+
+	records := map[Kind]Record{KindPython: {Fingerprint: py.Fingerprint()}}
+	if Ready(py, records) {
+		t.Fatalf("env dir does not exist yet")
+	}
+	if err := os.MkdirAll(py.Dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveRecords(dir, records); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadRecords(dir)
+	if err != nil || !Ready(py, loaded) {
+		t.Fatalf("expected ready env after saving record: %v", err)
+	}
+	write("requirements.txt", "requests\nflask\n")
+	changed, _ := Detect(dir, []string{"requirements.txt"})
+	if Ready(changed[0], loaded) {
+		t.Fatalf("changed requirements should need a new env")
+	}
+}
@@ -0,0 +1,258 @@
+package deps
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ScriptMetadata is the part of a PEP 723 "script" block gixt uses.
+type ScriptMetadata struct {
+	Dependencies   []string
+	RequiresPython string
+}
+
+// ParseScriptMetadata reads PEP 723 inline metadata:
+//
+//	# /// script
+//	# requires-python = ">=3.11"
+//	# dependencies = ["requests<3", "rich"]
+//	# ///
+//
+// Only the top-level dependencies and requires-python keys are read; other keys and
+// [tool] tables are ignored. It reports false when src has no script block.
+func ParseScriptMetadata(src []byte) (ScriptMetadata, bool, error) {
+	var body []string
+	found, inBlock := false, false
+	scanner := bufio.NewScanner(bytes.NewReader(src))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		switch {
+		case !inBlock && line == "# /// script":
+			if found {
+				return ScriptMetadata{}, false, fmt.Errorf("multiple `# /// script` blocks")
+			}
+			found, inBlock = true, true
+		case inBlock && line == "# ///":
+			inBlock = false
+		case inBlock && (line == "#" || strings.HasPrefix(line, "# ")):
+			body = append(body, strings.TrimPrefix(strings.TrimPrefix(line, "#"), " "))
+		case inBlock:
+			return ScriptMetadata{}, false, fmt.Errorf("script metadata line %q must start with '# '", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return ScriptMetadata{}, false, err
+	}
+	if !found {
+		return ScriptMetadata{}, false, nil
+	}
+	if inBlock {
+		return ScriptMetadata{}, false, fmt.Errorf("unterminated `# /// script` block")
+	}
+	meta, err := parseMetadataTOML(strings.Join(body, "\n"))
+	if err != nil {
+		return ScriptMetadata{}, false, fmt.Errorf("script metadata: %w", err)
+	}
+	return meta, true, nil
+}
+
+// parseMetadataTOML understands the small TOML subset script blocks use: top-level
+// `key = "string"` and `key = [strings]` (arrays may span lines), comments, and tables,
+// whose contents are skipped.
+func parseMetadataTOML(doc string) (ScriptMetadata, error) {
+	var meta ScriptMetadata
+	p := &tomlScanner{src: doc}
+	for {
+		p.skipSpaceAndComments()
+		if p.done() {
+			return meta, nil
+		}
+		if p.peek() == '[' {
+			// A [table] header: nothing after it is top-level.
+			return meta, nil
+		}
+		key := p.key()
+		if key == "" {
+			return meta, fmt.Errorf("expected a key at %q", p.rest())
+		}
+		p.skipInlineSpace()
+		if !p.consume('=') {
+			return meta, fmt.Errorf("expected = after %s", key)
+		}
+		p.skipInlineSpace()
+		switch key {
+		case "dependencies":
+			list, err := p.stringArray()
+			if err != nil {
+				return meta, fmt.Errorf("dependencies: %w", err)
+			}
+			meta.Dependencies = list
+		case "requires-python":
+			s, err := p.str()
+			if err != nil {
+				return meta, fmt.Errorf("requires-python: %w", err)
+			}
+			meta.RequiresPython = s
+		default:
+			if err := p.skipValue(); err != nil {
+				return meta, fmt.Errorf("%s: %w", key, err)
+			}
+		}
+	}
+}
+
+type tomlScanner struct {
+	src string
+	pos int
+}
+
+func (p *tomlScanner) done() bool   { return p.pos >= len(p.src) }
+func (p *tomlScanner) peek() byte   { return p.src[p.pos] }
+func (p *tomlScanner) rest() string { return strings.SplitN(p.src[p.pos:], "\n", 2)[0] }
+
+func (p *tomlScanner) consume(c byte) bool {
+	if !p.done() && p.peek() == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *tomlScanner) skipInlineSpace() {
+	for !p.done() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+func (p *tomlScanner) skipSpaceAndComments() {
+	for !p.done() {
+		switch p.peek() {
+		case ' ', '\t', '\r', '\n':
+			p.pos++
+		case '#':
+			for !p.done() && p.peek() != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlScanner) key() string {
+	if !p.done() && (p.peek() == '"' || p.peek() == '\'') {
+		s, _ := p.str()
+		return s
+	}
+	start := p.pos
+	for !p.done() {
+		c := p.peek()
+		if c == '-' || c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') {
+			p.pos++
+			continue
+		}
+		break
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *tomlScanner) str() (string, error) {
+	if p.done() {
+		return "", fmt.Errorf("expected a string")
+	}
+	switch p.peek() {
+	case '\'':
+		end := strings.IndexAny(p.src[p.pos+1:], "'\n")
+		if end < 0 || p.src[p.pos+1+end] != '\'' {
+			return "", fmt.Errorf("unterminated string")
+		}
+		s := p.src[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return s, nil
+	case '"':
+		i := p.pos + 1
+		for i < len(p.src) && p.src[i] != '"' && p.src[i] != '\n' {
+			if p.src[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(p.src) || p.src[i] != '"' {
+			return "", fmt.Errorf("unterminated string")
+		}
+		s, err := strconv.Unquote(p.src[p.pos : i+1])
+		if err != nil {
+			return "", fmt.Errorf("bad string %s", p.src[p.pos:i+1])
+		}
+		p.pos = i + 1
+		return s, nil
+	}
+	return "", fmt.Errorf("expected a string at %q", p.rest())
+}
+
+func (p *tomlScanner) stringArray() ([]string, error) {
+	if !p.consume('[') {
+		return nil, fmt.Errorf("expected an array of strings")
+	}
+	list := []string{}
+	for {
+		p.skipSpaceAndComments()
+		if p.consume(']') {
+			return list, nil
+		}
+		s, err := p.str()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, s)
+		p.skipSpaceAndComments()
+		if p.consume(',') {
+			continue
+		}
+		if p.consume(']') {
+			return list, nil
+		}
+		return nil, fmt.Errorf("expected , or ] at %q", p.rest())
+	}
+}
+
+// skipValue skips a value this parser does not read: a string, an array, or a bare value
+// such as a number, boolean, or inline table on one line.
+func (p *tomlScanner) skipValue() error {
+	if p.done() {
+		return fmt.Errorf("missing value")
+	}
+	switch p.peek() {
+	case '"', '\'':
+		_, err := p.str()
+		return err
+	case '[':
+		depth := 0
+		for !p.done() {
+			switch p.peek() {
+			case '[':
+				depth++
+			case ']':
+				depth--
+			case '"', '\'':
+				if _, err := p.str(); err != nil {
+					return err
+				}
+				continue
+			}
+			p.pos++
+			if depth == 0 {
+				return nil
+			}
+		}
+		return fmt.Errorf("unterminated array")
+	}
+	for !p.done() && p.peek() != '\n' {
+		p.pos++
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+// Package diff renders line-based unified diffs for reviewing gist revisions.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxCells bounds the LCS table; larger inputs are shown as a full replacement.
+const maxCells = 4_000_000
+
+type op struct {
+	kind byte // ' ', '-', '+'
+	text string
+}
+
+// Unified returns a unified diff from a to b with the given number of context lines, or ""
+// when the inputs are identical.
+func Unified(aName, bName, a, b string, context int) string {
+	if a == b {
+		return ""
+	}
+	ops := lineOps(splitLines(a), splitLines(b))
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", aName, bName)
+	for _, h := range hunks(ops, context) {
+		out.WriteString(h)
+	}
+	return out.String()
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	s = strings.TrimSuffix(s, "\n")
+	return strings.Split(s, "\n")
+}
+
+func lineOps(a, b []string) []op {
+	n, m := len(a), len(b)
+	if n*m > maxCells {
+		ops := make([]op, 0, n+m)
+		for _, l := range a {
+			ops = append(ops, op{'-', l})
+		}
+		for _, l := range b {
+			ops = append(ops, op{'+', l})
+		}
+		return ops
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:].
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]op, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, op{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, op{'+', b[j]})
+	}
+	return ops
+}
+
+// hunks groups changes with up to context unchanged lines around them.
+func hunks(ops []op, context int) []string {
+	var out []string
+	for start := 0; start < len(ops); {
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start == len(ops) {
+			break
+		}
+		from := start - context
+		if from < 0 {
+			from = 0
+		}
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*context {
+				break
+			}
+			end = run
+		}
+		to := end + context
+		if to > len(ops) {
+			to = len(ops)
+		}
+		out = append(out, renderHunk(ops, from, to))
+		start = to
+	}
+	return out
+}
+
+func renderHunk(ops []op, from, to int) string {
+	aStart, bStart := 1, 1
+	for _, o := range ops[:from] {
+		if o.kind != '+' {
+			aStart++
+		}
+		if o.kind != '-' {
+			bStart++
+		}
+	}
+	var body strings.Builder
+	aLen, bLen := 0, 0
+	for _, o := range ops[from:to] {
+		if o.kind != '+' {
+			aLen++
+		}
+		if o.kind != '-' {
+			bLen++
+		}
+		body.WriteByte(o.kind)
+		body.WriteString(o.text)
+		body.WriteByte('\n')
+	}
+	if aLen == 0 {
+		aStart--
+	}
+	if bLen == 0 {
+		bStart--
+	}
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@\n%s", aStart, aLen, bStart, bLen, body.String())
+}
@@ -0,0 +1,27 @@
+package diff
+
+import "testing"
+
+func TestUnifiedIdenticalIsEmpty(t *testing.T) {
+	if got := Unified("a", "b", "x\ny\n", "x\ny\n", 3); got != "" {
+		t.Fatalf("expected empty diff, got %q", got)
+	}
+}
+
+func TestUnifiedHunks(t *testing.T) {
+	a := "one\ntwo\nthree\nfour\nfive\nsix\nseven\neight\n"
+	b := "one\ntwo\nTHREE\nfour\nfive\nsix\nseven\neight\nnine\n"
+	want := "--- old\n+++ new\n" +
+		"@@ -2,3 +2,3 @@\n two\n-three\n+THREE\n four\n" +
+		"@@ -8,1 +8,2 @@\n eight\n+nine\n"
+	if got := Unified("old", "new", a, b, 1); got != want {
+		t.Fatalf("unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnifiedNewFile(t *testing.T) {
+	want := "--- /dev/null\n+++ run.sh\n@@ -0,0 +1,2 @@\n+echo hi\n+exit 0\n"
+	if got := Unified("/dev/null", "run.sh", "", "echo hi\nexit 0\n", 3); got != want {
+		t.Fatalf("unexpected diff:\n%s", got)
+	}
+}
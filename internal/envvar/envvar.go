@@ -0,0 +1,225 @@
+// Package envvar expands ${VAR} references and reads KEY=VALUE env files.
+package envvar
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Lookup returns the value of a variable and whether it is set.
+type Lookup func(name string) (string, bool)
+
+var nameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidName reports whether name can be used as an environment variable name.
+func ValidName(name string) bool {
+	return nameRe.MatchString(name)
+}
+
+// Expand replaces ${NAME} with the variable's value and ${NAME:-default} with the value, or
+// with default (itself expanded) when the variable is unset or empty. Other shell forms such
+// as $NAME, ${#NAME} or ${NAME%suffix} are left as written. An unset ${NAME} expands to ""
+// unless keepUnset is true, in which case it is left for a shell to expand later.
+func Expand(s string, lookup Lookup, keepUnset bool) (string, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(s, "${")
+		if i < 0 {
+			b.WriteString(s)
+			return b.String(), nil
+		}
+		b.WriteString(s[:i])
+		end := closingBrace(s, i+2)
+		if end < 0 {
+			return "", fmt.Errorf("unterminated ${ in %q", s)
+		}
+		ref := s[i : end+1]
+		body := s[i+2 : end]
+		s = s[end+1:]
+
+		name, def, hasDefault := strings.Cut(body, ":-")
+		if !ValidName(name) {
+			b.WriteString(ref)
+			continue
+		}
+		val, ok := lookup(name)
+		switch {
+		case hasDefault && val == "":
+			expanded, err := Expand(def, lookup, keepUnset)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(expanded)
+		case !ok && keepUnset:
+			b.WriteString(ref)
+		default:
+			b.WriteString(val)
+		}
+	}
+}
+
+// closingBrace returns the index of the } that closes a ${ whose body starts at from.
+func closingBrace(s string, from int) int {
+	depth := 1
+	for i := from; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "${"):
+			depth++
+			i++
+		case s[i] == '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// Resolve expands the values of env. ${NAME} in a value refers to another key of env, or to
+// outer when NAME is not a key or is the key being defined, so "PATH": "bin:${PATH}" extends
+// the outer PATH. Circular references are an error.
+func Resolve(env map[string]string, outer Lookup) (map[string]string, error) {
+	out := make(map[string]string, len(env))
+	active := map[string]bool{}
+	var resolve func(key string) (string, error)
+	resolve = func(key string) (string, error) {
+		if v, ok := out[key]; ok {
+			return v, nil
+		}
+		active[key] = true
+		defer delete(active, key)
+		var failure error
+		v, err := Expand(env[key], func(name string) (string, bool) {
+			if _, inEnv := env[name]; !inEnv || name == key {
+				return outer(name)
+			}
+			if active[name] {
+				if failure == nil {
+					failure = fmt.Errorf("env %s and %s refer to each other", key, name)
+				}
+				return "", false
+			}
+			v, err := resolve(name)
+			if err != nil && failure == nil {
+				failure = err
+			}
+			return v, true
+		}, false)
+		if err == nil {
+			err = failure
+		}
+		if err != nil {
+			return "", fmt.Errorf("env %s: %w", key, err)
+		}
+		out[key] = v
+		return v, nil
+	}
+	for key := range env {
+		if _, err := resolve(key); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// ParseAssignment splits a KEY=VALUE pair as given to --env.
+func ParseAssignment(s string) (string, string, error) {
+	key, val, ok := strings.Cut(s, "=")
+	if !ok || !ValidName(key) {
+		return "", "", fmt.Errorf("invalid env assignment %q (expected KEY=VALUE)", s)
+	}
+	return key, val, nil
+}
+
+// ParseFile reads a .env file: KEY=VALUE lines, optionally prefixed with "export", with #
+// comments and blank lines ignored. Single-quoted values are literal; double-quoted values
+// understand \n, \t, \" and \\ escapes; unquoted values end at " #". ${NAME} references in
+// double-quoted and unquoted values are expanded from earlier keys in the file, then lookup.
+func ParseFile(data []byte, lookup Lookup) (map[string]string, error) {
+	vars := map[string]string{}
+	scoped := func(name string) (string, bool) {
+		if v, ok := vars[name]; ok {
+			return v, true
+		}
+		return lookup(name)
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		key, raw, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || !ValidName(key) {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", n)
+		}
+		raw = strings.TrimSpace(raw)
+		var val string
+		switch {
+		case strings.HasPrefix(raw, "'"):
+			end := strings.Index(raw[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated single quote", n)
+			}
+			vars[key] = raw[1 : end+1]
+			continue
+		case strings.HasPrefix(raw, `"`):
+			s, err := unquoteDouble(raw)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			val = s
+		default:
+			if i := strings.Index(raw, " #"); i >= 0 {
+				raw = strings.TrimSpace(raw[:i])
+			}
+			val = raw
+		}
+		expanded, err := Expand(val, scoped, false)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n, err)
+		}
+		vars[key] = expanded
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
+
+func unquoteDouble(raw string) (string, error) {
+	var b strings.Builder
+	for i := 1; i < len(raw); i++ {
+		c := raw[i]
+		switch {
+		case c == '"':
+			return b.String(), nil
+		case c == '\\' && i+1 < len(raw):
+			i++
+			switch raw[i] {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case '"', '\\':
+				b.WriteByte(raw[i])
+			default:
+				b.WriteByte('\\')
+				b.WriteByte(raw[i])
+			}
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", fmt.Errorf("unterminated double quote")
+}
@@ -0,0 +1,95 @@
+package envvar
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func lookupIn(vars map[string]string) Lookup {
+	return func(name string) (string, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+}
+
+func TestExpand(t *testing.T) {
+	lookup := lookupIn(map[string]string{"HOME": "/home/me", "EMPTY": ""})
+	cases := []struct {
+		in, want  string
+		keepUnset bool
+	}{
+		{"${HOME}/bin", "/home/me/bin", false},
+		{"${MISSING}x", "x", false},
+		{"${MISSING}x", "${MISSING}x", true},
+		{"${EMPTY:-fallback}", "fallback", true},
+		{"${MISSING:-${HOME}/data}", "/home/me/data", false},
+		{"$HOME ${#HOME} ${HOME%/me}", "$HOME ${#HOME} ${HOME%/me}", false},
+	}
+	for _, c := range cases {
+		got, err := Expand(c.in, lookup, c.keepUnset)
+		if err != nil || got != c.want {
+			t.Errorf("Expand(%q, keep=%v) = %q, %v; want %q", c.in, c.keepUnset, got, err, c.want)
+		}
+	}
+	if _, err := Expand("${HOME", lookup, false); err == nil {
+		t.Fatalf("expected unterminated reference error")
+	}
+}
+
+func TestResolveReferencesOtherKeysAndOuter(t *testing.T) {
+	env := map[string]string{
+		"PATH":     "/opt/tool/bin:${PATH}",
+		"DATA":     "${BASE}/data",
+		"BASE":     "${HOME:-/tmp}/app",
+		"URL":      "${API_BASE:-https://api.example.com}/v1",
+		"API_BASE": "",
+	}
+	got, err := Resolve(env, lookupIn(map[string]string{"PATH": "/usr/bin", "HOME": "/home/me"}))
+	if err != nil {
+		t.Fatalf("Resolve error: %v", err)
+	}
+	want := map[string]string{
+		"PATH":     "/opt/tool/bin:/usr/bin",
+		"DATA":     "/home/me/app/data",
+		"BASE":     "/home/me/app",
+		"URL":      "https://api.example.com/v1",
+		"API_BASE": "",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("Resolve = %v, want %v", got, want)
+	}
+
+	_, err = Resolve(map[string]string{"A": "${B}", "B": "${A}"}, lookupIn(nil))
+	if err == nil || !strings.Contains(err.Error(), "refer to each other") {
+		t.Fatalf("expected cycle error, got %v", err)
+	}
+}
+
+func TestParseFile(t *testing.T) {
+	data := `# comment
+export TOKEN=abc123
+NAME = "Ada \"L\"\nLovelace"
+LITERAL='${TOKEN} stays'
+URL=https://example.com/${TOKEN} # trailing comment
+HOME_DIR=${HOME}
+
+`
+	got, err := ParseFile([]byte(data), lookupIn(map[string]string{"HOME": "/home/me"}))
+	if err != nil {
+		t.Fatalf("ParseFile error: %v", err)
+	}
+	want := map[string]string{
+		"TOKEN":    "abc123",
+		"NAME":     "Ada \"L\"\nLovelace",
+		"LITERAL":  "${TOKEN} stays",
+		"URL":      "https://example.com/abc123",
+		"HOME_DIR": "/home/me",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("ParseFile = %q, want %q", got, want)
+	}
+	if _, err := ParseFile([]byte("not an assignment"), lookupIn(nil)); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Fatalf("expected line error, got %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package gist
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Client is the set of GitHub gist API operations gixt relies on. GHClient shells out to
+// the `gh` CLI; HTTPClient talks to the REST API directly with net/http.
+type Client interface {
+	Fetch(ctx context.Context, id string, ref string) (Gist, error)
+	List(ctx context.Context, perPage, maxPages int) ([]ListItem, error)
+	ListForOwner(ctx context.Context, owner string, perPage, maxPages int) ([]ListItem, error)
+	UpdateFiles(ctx context.Context, id string, files map[string]string) (Gist, error)
+	UpdateDescription(ctx context.Context, id string, description string) (Gist, error)
+	Create(ctx context.Context, files map[string]string, description string, public bool) (Gist, error)
+	CurrentUser(ctx context.Context) (string, error)
+}
+
+// APIError reports a failed API call. Status is the HTTP status when known (0 otherwise).
+type APIError struct {
+	Status      int
+	Message     string
+	Unreachable bool // the host could not be contacted at all
+}
+
+func (e *APIError) Error() string {
+	return e.Message
+}
+
+// StatusCode returns the HTTP status carried by err, or 0 when err is not an APIError.
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Status
+	}
+	return 0
+}
+
+func gistPath(id string, ref string) string {
+	if ref != "" {
+		return fmt.Sprintf("/gists/%s/%s", id, ref)
+	}
+	return fmt.Sprintf("/gists/%s", id)
+}
+
+func decodeGist(data []byte) (Gist, error) {
+	var g Gist
+	if err := json.Unmarshal(data, &g); err != nil {
+		return Gist{}, fmt.Errorf("parse gist response: %w", err)
+	}
+	g.Raw = map[string]any{}
+	if err := json.Unmarshal(data, &g.Raw); err != nil {
+		// ignore secondary parse failure
+	}
+	return g, nil
+}
+
+func decodeLogin(data []byte) (string, error) {
+	var resp struct {
+		Login string `json:"login"`
+	}
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return "", fmt.Errorf("parse current user: %w", err)
+	}
+	return resp.Login, nil
+}
+
+type filePayload struct {
+	Content string `json:"content"`
+}
+
+func filesPayload(files map[string]string) ([]byte, error) {
+	payload := struct {
+		Files map[string]filePayload `json:"files"`
+	}{
+		Files: map[string]filePayload{},
+	}
+	for name, content := range files {
+		payload.Files[name] = filePayload{Content: content}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode gist payload: %w", err)
+	}
+	return body, nil
+}
+
+func descriptionPayload(description string) ([]byte, error) {
+	payload := struct {
+		Description string `json:"description"`
+	}{
+		Description: description,
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode gist payload: %w", err)
+	}
+	return body, nil
+}
+
+func createPayload(files map[string]string, description string, public bool) ([]byte, error) {
+	payload := struct {
+		Files       map[string]filePayload `json:"files"`
+		Description string                 `json:"description,omitempty"`
+		Public      bool                   `json:"public"`
+	}{
+		Files:       map[string]filePayload{},
+		Description: description,
+		Public:      public,
+	}
+	for name, content := range files {
+		payload.Files[name] = filePayload{Content: content}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode gist payload: %w", err)
+	}
+	return body, nil
+}
+
+func paginate(perPage, maxPages int, get func(page, perPage int) ([]byte, error), parseMsg string) ([]ListItem, error) {
+	if perPage <= 0 {
+		perPage = 50
+	}
+	if maxPages <= 0 {
+		maxPages = 1
+	}
+	var all []ListItem
+	for page := 1; page <= maxPages; page++ {
+		out, err := get(page, perPage)
+		if err != nil {
+			return nil, err
+		}
+		var batch []ListItem
+		if err := json.Unmarshal(out, &batch); err != nil {
+			return nil, fmt.Errorf("%s: %w", parseMsg, err)
+		}
+		all = append(all, batch...)
+		if len(batch) < perPage {
+			break
+		}
+	}
+	return all, nil
+}
@@ -0,0 +1,113 @@
+package gist
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/url"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// GHClient talks to the GitHub API by shelling out to an authenticated `gh` CLI.
+type GHClient struct {
+	Host string
+}
+
+func NewGHClient(host string) *GHClient {
+	return &GHClient{Host: NormalizeHost(host)}
+}
+
+var ghStatusRe = regexp.MustCompile(`HTTP (\d{3})`)
+
+func (c *GHClient) call(ctx context.Context, body []byte, args ...string) ([]byte, error) {
+	if len(args) > 0 && args[0] == "api" && !IsDefaultHost(c.Host) {
+		args = append([]string{"api", "--hostname", c.Host}, args[1:]...)
+	}
+	cmd := exec.CommandContext(ctx, "gh", args...)
+	if body != nil {
+		cmd.Stdin = bytes.NewReader(body)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		status := 0
+		if m := ghStatusRe.FindStringSubmatch(msg); len(m) == 2 {
+			status, _ = strconv.Atoi(m[1])
+		}
+		return nil, &APIError{
+			Status:      status,
+			Message:     fmt.Sprintf("gh %v failed: %v: %s", args, err, msg),
+			Unreachable: strings.Contains(msg, "error connecting to"),
+		}
+	}
+	return out, nil
+}
+
+func (c *GHClient) Fetch(ctx context.Context, id string, ref string) (Gist, error) {
+	out, err := c.call(ctx, nil, "api", gistPath(id, ref))
+	if err != nil {
+		return Gist{}, err
+	}
+	return decodeGist(out)
+}
+
+func (c *GHClient) UpdateFiles(ctx context.Context, id string, files map[string]string) (Gist, error) {
+	body, err := filesPayload(files)
+	if err != nil {
+		return Gist{}, err
+	}
+	out, err := c.call(ctx, body, "api", "-X", "PATCH", gistPath(id, ""), "--input", "-")
+	if err != nil {
+		return Gist{}, err
+	}
+	return decodeGist(out)
+}
+
+func (c *GHClient) UpdateDescription(ctx context.Context, id string, description string) (Gist, error) {
+	body, err := descriptionPayload(description)
+	if err != nil {
+		return Gist{}, err
+	}
+	out, err := c.call(ctx, body, "api", "-X", "PATCH", gistPath(id, ""), "--input", "-")
+	if err != nil {
+		return Gist{}, err
+	}
+	return decodeGist(out)
+}
+
+func (c *GHClient) Create(ctx context.Context, files map[string]string, description string, public bool) (Gist, error) {
+	body, err := createPayload(files, description, public)
+	if err != nil {
+		return Gist{}, err
+	}
+	out, err := c.call(ctx, body, "api", "-X", "POST", "/gists", "--input", "-")
+	if err != nil {
+		return Gist{}, err
+	}
+	return decodeGist(out)
+}
+
+func (c *GHClient) List(ctx context.Context, perPage, maxPages int) ([]ListItem, error) {
+	return paginate(perPage, maxPages, func(page, per int) ([]byte, error) {
+		return c.call(ctx, nil, "api", fmt.Sprintf("/gists?per_page=%d&page=%d", per, page))
+	}, "parse gist list")
+}
+
+func (c *GHClient) ListForOwner(ctx context.Context, owner string, perPage, maxPages int) ([]ListItem, error) {
+	return paginate(perPage, maxPages, func(page, per int) ([]byte, error) {
+		return c.call(ctx, nil, "api", fmt.Sprintf("/users/%s/gists?per_page=%d&page=%d", url.PathEscape(owner), per, page))
+	}, fmt.Sprintf("parse gist list for owner %s", owner))
+}
+
+func (c *GHClient) CurrentUser(ctx context.Context) (string, error) {
+	out, err := c.call(ctx, nil, "api", "/user")
+	if err != nil {
+		return "", err
+	}
+	return decodeLogin(out)
+}
@@ -1,13 +1,8 @@
 package gist
 
 import (
-	"bytes"
-	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
 	"net/url"
-	"os/exec"
 	"regexp"
 	"strings"
 	"time"
@@ -83,210 +78,6 @@ func (g Gist) LatestVersion() string {
 	return ""
 }
 
-func callGH(ctx context.Context, args ...string) ([]byte, error) {
-	cmd := exec.CommandContext(ctx, "gh", args...)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	out, err := cmd.Output()
-	if err != nil {
-		return nil, fmt.Errorf("gh %v failed: %v: %s", args, err, strings.TrimSpace(stderr.String()))
-	}
-	return out, nil
-}
-
-func Fetch(ctx context.Context, id string, ref string) (Gist, error) {
-	path := fmt.Sprintf("/gists/%s", id)
-	if ref != "" {
-		path = fmt.Sprintf("/gists/%s/%s", id, ref)
-	}
-	out, err := callGH(ctx, "api", path)
-	if err != nil {
-		return Gist{}, err
-	}
-	var g Gist
-	if err := json.Unmarshal(out, &g); err != nil {
-		return Gist{}, fmt.Errorf("parse gist response: %w", err)
-	}
-	g.Raw = map[string]any{}
-	if err := json.Unmarshal(out, &g.Raw); err != nil {
-		// ignore secondary parse failure
-	}
-	return g, nil
-}
-
-func UpdateFiles(ctx context.Context, id string, files map[string]string) (Gist, error) {
-	type filePayload struct {
-		Content string `json:"content"`
-	}
-	payload := struct {
-		Files map[string]filePayload `json:"files"`
-	}{
-		Files: map[string]filePayload{},
-	}
-	for name, content := range files {
-		payload.Files[name] = filePayload{Content: content}
-	}
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return Gist{}, fmt.Errorf("encode gist payload: %w", err)
-	}
-
-	path := fmt.Sprintf("/gists/%s", id)
-	cmd := exec.CommandContext(ctx, "gh", "api", "-X", "PATCH", path, "--input", "-")
-	cmd.Stdin = bytes.NewReader(body)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	out, err := cmd.Output()
-	if err != nil {
-		return Gist{}, fmt.Errorf("gh api patch %s failed: %v: %s", id, err, strings.TrimSpace(stderr.String()))
-	}
-	var g Gist
-	if err := json.Unmarshal(out, &g); err != nil {
-		return Gist{}, fmt.Errorf("parse gist response: %w", err)
-	}
-	g.Raw = map[string]any{}
-	if err := json.Unmarshal(out, &g.Raw); err != nil {
-		// ignore secondary parse failure
-	}
-	return g, nil
-}
-
-func UpdateDescription(ctx context.Context, id string, description string) (Gist, error) {
-	payload := struct {
-		Description string `json:"description"`
-	}{
-		Description: description,
-	}
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return Gist{}, fmt.Errorf("encode gist payload: %w", err)
-	}
-	path := fmt.Sprintf("/gists/%s", id)
-	cmd := exec.CommandContext(ctx, "gh", "api", "-X", "PATCH", path, "--input", "-")
-	cmd.Stdin = bytes.NewReader(body)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	out, err := cmd.Output()
-	if err != nil {
-		return Gist{}, fmt.Errorf("gh api patch %s failed: %v: %s", id, err, strings.TrimSpace(stderr.String()))
-	}
-	var g Gist
-	if err := json.Unmarshal(out, &g); err != nil {
-		return Gist{}, fmt.Errorf("parse gist response: %w", err)
-	}
-	g.Raw = map[string]any{}
-	if err := json.Unmarshal(out, &g.Raw); err != nil {
-		// ignore secondary parse failure
-	}
-	return g, nil
-}
-
-func Create(ctx context.Context, files map[string]string, description string, public bool) (Gist, error) {
-	type filePayload struct {
-		Content string `json:"content"`
-	}
-	payload := struct {
-		Files       map[string]filePayload `json:"files"`
-		Description string                 `json:"description,omitempty"`
-		Public      bool                   `json:"public"`
-	}{
-		Files:  map[string]filePayload{},
-		Public: public,
-	}
-	for name, content := range files {
-		payload.Files[name] = filePayload{Content: content}
-	}
-	payload.Description = description
-
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return Gist{}, fmt.Errorf("encode gist payload: %w", err)
-	}
-	cmd := exec.CommandContext(ctx, "gh", "api", "-X", "POST", "/gists", "--input", "-")
-	cmd.Stdin = bytes.NewReader(body)
-	var stderr bytes.Buffer
-	cmd.Stderr = &stderr
-	out, err := cmd.Output()
-	if err != nil {
-		return Gist{}, fmt.Errorf("gh api create failed: %v: %s", err, strings.TrimSpace(stderr.String()))
-	}
-	var g Gist
-	if err := json.Unmarshal(out, &g); err != nil {
-		return Gist{}, fmt.Errorf("parse gist response: %w", err)
-	}
-	g.Raw = map[string]any{}
-	if err := json.Unmarshal(out, &g.Raw); err != nil {
-		// ignore secondary parse failure
-	}
-	return g, nil
-}
-
-func List(ctx context.Context, perPage, maxPages int) ([]ListItem, error) {
-	if perPage <= 0 {
-		perPage = 50
-	}
-	if maxPages <= 0 {
-		maxPages = 1
-	}
-	var all []ListItem
-	for page := 1; page <= maxPages; page++ {
-		path := fmt.Sprintf("/gists?per_page=%d&page=%d", perPage, page)
-		out, err := callGH(ctx, "api", path)
-		if err != nil {
-			return nil, err
-		}
-		var batch []ListItem
-		if err := json.Unmarshal(out, &batch); err != nil {
-			return nil, fmt.Errorf("parse gist list: %w", err)
-		}
-		all = append(all, batch...)
-		if len(batch) < perPage {
-			break
-		}
-	}
-	return all, nil
-}
-
-func ListForOwner(ctx context.Context, owner string, perPage, maxPages int) ([]ListItem, error) {
-	if perPage <= 0 {
-		perPage = 50
-	}
-	if maxPages <= 0 {
-		maxPages = 1
-	}
-	var all []ListItem
-	for page := 1; page <= maxPages; page++ {
-		path := fmt.Sprintf("/users/%s/gists?per_page=%d&page=%d", owner, perPage, page)
-		out, err := callGH(ctx, "api", path)
-		if err != nil {
-			return nil, err
-		}
-		var batch []ListItem
-		if err := json.Unmarshal(out, &batch); err != nil {
-			return nil, fmt.Errorf("parse gist list for owner %s: %w", owner, err)
-		}
-		all = append(all, batch...)
-		if len(batch) < perPage {
-			break
-		}
-	}
-	return all, nil
-}
-
-func CurrentUser(ctx context.Context) (string, error) {
-	out, err := callGH(ctx, "api", "/user")
-	if err != nil {
-		return "", err
-	}
-	var resp struct {
-		Login string `json:"login"`
-	}
-	if err := json.Unmarshal(out, &resp); err != nil {
-		return "", fmt.Errorf("parse current user: %w", err)
-	}
-	return resp.Login, nil
-}
-
 func GuessOwner(g Gist) string {
 	if g.Owner.Login != "" {
 		return g.Owner.Login
@@ -313,11 +104,14 @@ func IsLikelyGistID(id string) bool {
 	return true
 }
 
-// IsNotFound reports whether the error from gh indicates a 404.
+// IsNotFound reports whether the API error indicates a 404.
 func IsNotFound(err error) bool {
 	if err == nil {
 		return false
 	}
+	if status := StatusCode(err); status != 0 {
+		return status == 404
+	}
 	msg := strings.ToLower(err.Error())
 	return strings.Contains(msg, "http 404") || strings.Contains(msg, " 404 ") || strings.Contains(msg, "not found")
 }
@@ -0,0 +1,76 @@
+package gist
+
+import (
+	"net/url"
+	"strings"
+)
+
+const DefaultHost = "github.com"
+
+// NormalizeHost lowercases host and strips any scheme and path, and maps gist.github.com and
+// api.github.com to github.com, so "https://gist.github.com/" and "github.com" compare
+// equal. Enterprise hosts are kept whole, even ones starting with "api." or "gist.". Empty
+// input means DefaultHost.
+func NormalizeHost(host string) string {
+	h := strings.ToLower(strings.TrimSpace(host))
+	h = strings.TrimPrefix(h, "https://")
+	h = strings.TrimPrefix(h, "http://")
+	if i := strings.Index(h, "/"); i >= 0 {
+		h = h[:i]
+	}
+	if h == "" || h == "gist."+DefaultHost || h == "api."+DefaultHost {
+		return DefaultHost
+	}
+	return h
+}
+
+func IsDefaultHost(host string) bool {
+	return NormalizeHost(host) == DefaultHost
+}
+
+// APIURL returns the REST base URL for host: api.github.com for github.com and
+// https://<host>/api/v3 for GitHub Enterprise Server.
+func APIURL(host string) string {
+	if IsDefaultHost(host) {
+		return DefaultAPIURL
+	}
+	return "https://" + NormalizeHost(host) + "/api/v3"
+}
+
+// ExtractHostID returns the host and gist ID referenced by input. Host is "" when input
+// is not a URL (a bare ID or name), leaving the caller to apply the active host.
+func ExtractHostID(input string) (string, string) {
+	id := ExtractID(input)
+	trimmed := strings.TrimSpace(input)
+	if !strings.Contains(trimmed, "://") {
+		return "", id
+	}
+	u, err := url.Parse(trimmed)
+	if err != nil || u.Host == "" {
+		return "", id
+	}
+	host := NormalizeHost(u.Hostname())
+	// Enterprise gist pages live at https://<host>/gist/... or, with subdomain isolation,
+	// https://gist.<host>/...
+	if !strings.HasPrefix(u.Path, "/gist/") {
+		host = strings.TrimPrefix(host, "gist.")
+	}
+	return host, id
+}
+
+// Key identifies a gist across hosts. github.com gists keep their bare ID so existing
+// index, alias, trust, and cache entries stay valid; other hosts are prefixed "host/".
+func Key(host, id string) string {
+	if IsDefaultHost(host) {
+		return id
+	}
+	return NormalizeHost(host) + "/" + id
+}
+
+// SplitKey reverses Key.
+func SplitKey(key string) (string, string) {
+	if i := strings.LastIndex(key, "/"); i > 0 {
+		return NormalizeHost(key[:i]), key[i+1:]
+	}
+	return DefaultHost, key
+}
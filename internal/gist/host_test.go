@@ -0,0 +1,47 @@
+package gist
+
+import "testing"
+
+func TestExtractHostIDAndKeys(t *testing.T) {
+	cases := []struct {
+		input, host, id, key string
+	}{
+		{"deadbeefcafe", "", "deadbeefcafe", "deadbeefcafe"},
+		{"https://gist.github.com/alice/deadbeefcafe", "github.com", "deadbeefcafe", "deadbeefcafe"},
+		{"https://ghe.example.com/gist/alice/deadbeefcafe", "ghe.example.com", "deadbeefcafe", "ghe.example.com/deadbeefcafe"},
+		{"https://gist.ghe.example.com/alice/deadbeefcafe/", "ghe.example.com", "deadbeefcafe", "ghe.example.com/deadbeefcafe"},
+		{"https://gist.corp.example/gist/alice/deadbeefcafe", "gist.corp.example", "deadbeefcafe", "gist.corp.example/deadbeefcafe"},
+		{"https://api.corp.example/gist/alice/deadbeefcafe", "api.corp.example", "deadbeefcafe", "api.corp.example/deadbeefcafe"},
+	}
+	for _, tc := range cases {
+		host, id := ExtractHostID(tc.input)
+		if host != tc.host || id != tc.id {
+			t.Fatalf("ExtractHostID(%q) = %q, %q; want %q, %q", tc.input, host, id, tc.host, tc.id)
+		}
+		if key := Key(host, id); key != tc.key {
+			t.Fatalf("Key(%q, %q) = %q, want %q", host, id, key, tc.key)
+		}
+		wantHost := tc.host
+		if wantHost == "" {
+			wantHost = DefaultHost
+		}
+		if h, i := SplitKey(tc.key); h != wantHost || i != tc.id {
+			t.Fatalf("SplitKey(%q) = %q, %q", tc.key, h, i)
+		}
+	}
+}
+
+func TestAPIURL(t *testing.T) {
+	if got := APIURL(""); got != DefaultAPIURL {
+		t.Fatalf("default host api url = %s", got)
+	}
+	if got := APIURL("https://GHE.example.com/"); got != "https://ghe.example.com/api/v3" {
+		t.Fatalf("enterprise api url = %s", got)
+	}
+	if got := APIURL("api.corp.example"); got != "https://api.corp.example/api/v3" {
+		t.Fatalf("enterprise host starting with api. = %s", got)
+	}
+	if got := NormalizeHost("https://API.github.com/"); got != DefaultHost {
+		t.Fatalf("api.github.com should normalize to %s, got %s", DefaultHost, got)
+	}
+}
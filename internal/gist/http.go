@@ -0,0 +1,256 @@
+package gist
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/leolaurindo/gixt/internal/version"
+)
+
+const DefaultAPIURL = "https://api.github.com"
+
+// HTTPClient talks to the GitHub REST API directly, without requiring `gh`.
+type HTTPClient struct {
+	BaseURL string
+	Token   string
+	HTTP    *http.Client
+}
+
+func NewHTTPClient(baseURL string, token string) *HTTPClient {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	return &HTTPClient{
+		BaseURL: strings.TrimSuffix(baseURL, "/"),
+		Token:   token,
+		HTTP:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (c *HTTPClient) do(ctx context.Context, method string, path string, body []byte) ([]byte, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	req.Header.Set("User-Agent", "gixt/"+version.Version)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+	out, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%s %s: read response: %w", method, path, err)
+	}
+	if resp.StatusCode >= 300 {
+		msg := strings.TrimSpace(string(out))
+		var apiMsg struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(out, &apiMsg) == nil && apiMsg.Message != "" {
+			msg = apiMsg.Message
+		}
+		if resp.StatusCode == http.StatusUnauthorized && c.Token == "" {
+			msg += " (set GH_TOKEN or GITHUB_TOKEN, or log in with `gh auth login`)"
+		}
+		return nil, &APIError{
+			Status:  resp.StatusCode,
+			Message: fmt.Sprintf("%s %s: http %d: %s", method, path, resp.StatusCode, msg),
+		}
+	}
+	return out, nil
+}
+
+func (c *HTTPClient) Fetch(ctx context.Context, id string, ref string) (Gist, error) {
+	out, err := c.do(ctx, http.MethodGet, gistPath(id, ref), nil)
+	if err != nil {
+		return Gist{}, err
+	}
+	return decodeGist(out)
+}
+
+func (c *HTTPClient) UpdateFiles(ctx context.Context, id string, files map[string]string) (Gist, error) {
+	body, err := filesPayload(files)
+	if err != nil {
+		return Gist{}, err
+	}
+	out, err := c.do(ctx, http.MethodPatch, gistPath(id, ""), body)
+	if err != nil {
+		return Gist{}, err
+	}
+	return decodeGist(out)
+}
+
+func (c *HTTPClient) UpdateDescription(ctx context.Context, id string, description string) (Gist, error) {
+	body, err := descriptionPayload(description)
+	if err != nil {
+		return Gist{}, err
+	}
+	out, err := c.do(ctx, http.MethodPatch, gistPath(id, ""), body)
+	if err != nil {
+		return Gist{}, err
+	}
+	return decodeGist(out)
+}
+
+func (c *HTTPClient) Create(ctx context.Context, files map[string]string, description string, public bool) (Gist, error) {
+	body, err := createPayload(files, description, public)
+	if err != nil {
+		return Gist{}, err
+	}
+	out, err := c.do(ctx, http.MethodPost, "/gists", body)
+	if err != nil {
+		return Gist{}, err
+	}
+	return decodeGist(out)
+}
+
+func (c *HTTPClient) List(ctx context.Context, perPage, maxPages int) ([]ListItem, error) {
+	return paginate(perPage, maxPages, func(page, per int) ([]byte, error) {
+		return c.do(ctx, http.MethodGet, fmt.Sprintf("/gists?per_page=%d&page=%d", per, page), nil)
+	}, "parse gist list")
+}
+
+func (c *HTTPClient) ListForOwner(ctx context.Context, owner string, perPage, maxPages int) ([]ListItem, error) {
+	return paginate(perPage, maxPages, func(page, per int) ([]byte, error) {
+		return c.do(ctx, http.MethodGet, fmt.Sprintf("/users/%s/gists?per_page=%d&page=%d", url.PathEscape(owner), per, page), nil)
+	}, fmt.Sprintf("parse gist list for owner %s", owner))
+}
+
+func (c *HTTPClient) CurrentUser(ctx context.Context) (string, error) {
+	out, err := c.do(ctx, http.MethodGet, "/user", nil)
+	if err != nil {
+		return "", err
+	}
+	return decodeLogin(out)
+}
+
+// DiscoverToken returns an API token for host from the same environment variables gh
+// honours (GH_TOKEN/GITHUB_TOKEN, or GH_ENTERPRISE_TOKEN/GITHUB_ENTERPRISE_TOKEN for
+// Enterprise hosts), falling back to the oauth_token in gh's hosts.yml. It returns ""
+// when none is found.
+func DiscoverToken(host string) string {
+	host = NormalizeHost(host)
+	envKeys := []string{"GH_TOKEN", "GITHUB_TOKEN"}
+	if !IsDefaultHost(host) {
+		envKeys = []string{"GH_ENTERPRISE_TOKEN", "GITHUB_ENTERPRISE_TOKEN"}
+	}
+	for _, key := range envKeys {
+		if tok := strings.TrimSpace(os.Getenv(key)); tok != "" {
+			return tok
+		}
+	}
+	path := ghHostsFile()
+	if path == "" {
+		return ""
+	}
+	fh, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer fh.Close()
+	return tokenFromHostsYAML(fh, host)
+}
+
+func ghHostsFile() string {
+	if dir := os.Getenv("GH_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "hosts.yml")
+	}
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh", "hosts.yml")
+	}
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("AppData"); dir != "" {
+			return filepath.Join(dir, "GitHub CLI", "hosts.yml")
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gh", "hosts.yml")
+}
+
+// tokenFromHostsYAML extracts the host's own oauth_token or, failing that, the token of
+// its active `user:` from the `users:` map newer gh versions write for multiple accounts.
+// gh's hosts.yml is simple enough that a line scanner avoids pulling in a YAML dependency.
+func tokenFromHostsYAML(r io.Reader, host string) string {
+	scanner := bufio.NewScanner(r)
+	inHost, inUsers := false, false
+	hostIndent, usersIndent := -1, -1
+	var hostToken, active, account string
+	userTokens := map[string]string{}
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		indent := len(line) - len(strings.TrimLeft(line, " \t"))
+		key, val, _ := strings.Cut(trimmed, ":")
+		key = strings.Trim(key, `"'`)
+		val = strings.Trim(strings.TrimSpace(val), `"'`)
+		if indent == 0 {
+			inHost, inUsers = strings.EqualFold(key, host), false
+			hostIndent = -1
+			continue
+		}
+		if !inHost {
+			continue
+		}
+		if hostIndent < 0 {
+			hostIndent = indent
+		}
+		switch {
+		case indent == hostIndent:
+			inUsers, usersIndent, account = key == "users", -1, ""
+			switch key {
+			case "oauth_token":
+				hostToken = val
+			case "user":
+				active = val
+			}
+		case inUsers:
+			if usersIndent < 0 {
+				usersIndent = indent
+			}
+			if indent == usersIndent {
+				account = key
+			} else if key == "oauth_token" && account != "" {
+				userTokens[account] = val
+			}
+		}
+	}
+	if hostToken != "" {
+		return hostToken
+	}
+	return userTokens[active]
+}
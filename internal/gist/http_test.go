@@ -0,0 +1,102 @@
+package gist
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHTTPClientFetchSendsTokenAndDecodes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("unexpected auth header %q", got)
+		}
+		if r.URL.Path != "/gists/abc123/sha1" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"id":"abc123","description":"demo","owner":{"login":"alice"},"files":{"main.py":{"filename":"main.py","content":"print(1)"}},"history":[{"version":"sha1"}]}`)
+	}))
+	defer srv.Close()
+
+	c := NewHTTPClient(srv.URL, "secret")
+	g, err := c.Fetch(context.Background(), "abc123", "sha1")
+	if err != nil {
+		t.Fatalf("fetch: %v", err)
+	}
+	if g.ID != "abc123" || GuessOwner(g) != "alice" || g.LatestVersion() != "sha1" {
+		t.Fatalf("unexpected gist %+v", g)
+	}
+	if g.Files["main.py"].Content != "print(1)" {
+		t.Fatalf("unexpected file content %+v", g.Files)
+	}
+}
+
+func TestHTTPClientListPaginatesAndReportsNotFound(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/users/bob/gists" && r.URL.Query().Get("page") == "1":
+			fmt.Fprint(w, `[{"id":"a"},{"id":"b"}]`)
+		case r.URL.Path == "/users/bob/gists":
+			fmt.Fprint(w, `[{"id":"c"}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"message":"Not Found"}`)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewHTTPClient(srv.URL, "")
+	items, err := c.ListForOwner(context.Background(), "bob", 2, 5)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(items) != 3 {
+		t.Fatalf("expected 3 items across pages, got %d", len(items))
+	}
+
+	_, err = c.Fetch(context.Background(), "missing", "")
+	if !IsNotFound(err) {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if StatusCode(err) != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", StatusCode(err))
+	}
+}
+
+func TestTokenFromHostsYAML(t *testing.T) {
+	data := `github.com:
+    user: alice
+    oauth_token: gho_abc
+    git_protocol: https
+ghe.example.com:
+    oauth_token: "gho_ghe"
+`
+	if got := tokenFromHostsYAML(strings.NewReader(data), "github.com"); got != "gho_abc" {
+		t.Fatalf("expected github.com token, got %q", got)
+	}
+	if got := tokenFromHostsYAML(strings.NewReader(data), "ghe.example.com"); got != "gho_ghe" {
+		t.Fatalf("expected ghe token, got %q", got)
+	}
+	if got := tokenFromHostsYAML(strings.NewReader(data), "other.example.com"); got != "" {
+		t.Fatalf("expected no token, got %q", got)
+	}
+
+	multi := `github.com:
+    git_protocol: https
+    users:
+        alice:
+            oauth_token: gho_alice
+        bob:
+            oauth_token: gho_bob
+    user: bob
+`
+	if got := tokenFromHostsYAML(strings.NewReader(multi), "github.com"); got != "gho_bob" {
+		t.Fatalf("expected the active user's token, got %q", got)
+	}
+	if got := tokenFromHostsYAML(strings.NewReader(strings.Replace(multi, "user: bob", "user: carol", 1)), "github.com"); got != "" {
+		t.Fatalf("expected no token for an active user without one, got %q", got)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/leolaurindo/gixt/internal/gist"
 )
 
 type Entry struct {
@@ -16,6 +18,23 @@ type Entry struct {
 	Filenames   []string  `json:"filenames"`
 	UpdatedAt   time.Time `json:"updated_at"`
 	Owner       string    `json:"owner"`
+	Host        string    `json:"host,omitempty"`     // empty for github.com
+	Commands    []string  `json:"commands,omitempty"` // manifest command names, resolvable like filenames
+}
+
+// Key returns the host-qualified gist key for the entry (see gist.Key).
+func (e Entry) Key() string {
+	return gist.Key(e.Host, e.ID)
+}
+
+// Command returns the entry's manifest command matching name (case-insensitive).
+func (e Entry) Command(name string) (string, bool) {
+	for _, c := range e.Commands {
+		if strings.EqualFold(c, strings.TrimSpace(name)) {
+			return c, true
+		}
+	}
+	return "", false
 }
 
 type Index struct {
@@ -56,7 +75,7 @@ func Lookup(idx Index, name string) []Entry {
 	return matches
 }
 
-// LookupName matches by filename base (case-insensitive, sans extension) or the full filename (case-insensitive, with extension).
+// LookupName matches by filename base (case-insensitive, sans extension), the full filename (case-insensitive, with extension), or a manifest command name.
 func LookupName(idx Index, name string) []Entry {
 	target := strings.TrimSpace(strings.ToLower(name))
 	if target == "" {
@@ -73,6 +92,9 @@ func LookupName(idx Index, name string) []Entry {
 				break
 			}
 		}
+		if !found {
+			_, found = e.Command(target)
+		}
 		if found {
 			matches = append(matches, e)
 		}
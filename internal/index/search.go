@@ -0,0 +1,187 @@
+package index
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Document is an index entry plus searchable text that lives outside the index, such as
+// the details of a cached run manifest.
+type Document struct {
+	Entry   Entry
+	Details string
+}
+
+// Result is a ranked search hit. Name is the filename base that matched best, or the
+// entry's first filename when the match came from another field.
+type Result struct {
+	Entry Entry  `json:"entry"`
+	Name  string `json:"name"`
+	Score int    `json:"score"`
+}
+
+// Scores for one query term by kind of match. fieldWeight then scales them (in percent)
+// so a filename hit outranks the same hit in a description, owner, or manifest details.
+const (
+	scoreExact     = 100
+	scorePrefix    = 70
+	scoreSubstring = 50
+	scoreFuzzy     = 30
+)
+
+var fieldWeight = map[string]int{
+	"file":    100,
+	"desc":    70,
+	"owner":   60,
+	"details": 50,
+}
+
+// Search ranks docs against query. Each query term is scored against filename bases,
+// manifest command names, description words, the owner, and details words; matches may be exact, prefix,
+// substring, or fuzzy (small edit distance or subsequence). Documents with no matching
+// term are dropped. Results are ordered by score, then filename.
+func Search(docs []Document, query string) []Result {
+	terms := searchWords(query)
+	if len(terms) == 0 {
+		return nil
+	}
+	whole := strings.Join(terms, "")
+	var results []Result
+	for _, d := range docs {
+		score := 0
+		bestName, bestNameScore := "", 0
+		for _, term := range terms {
+			termBest := 0
+			for _, f := range d.Entry.Filenames {
+				base := strings.ToLower(strings.TrimSuffix(filepath.Base(f), filepath.Ext(f)))
+				s := weighted("file", matchScore(term, base))
+				for _, w := range searchWords(base) {
+					s = max(s, weighted("file", matchScore(term, w)))
+				}
+				if s > bestNameScore {
+					bestName, bestNameScore = base, s
+				}
+				termBest = max(termBest, s)
+			}
+			for _, c := range d.Entry.Commands {
+				s := weighted("file", matchScore(term, strings.ToLower(c)))
+				if s > bestNameScore {
+					bestName, bestNameScore = c, s
+				}
+				termBest = max(termBest, s)
+			}
+			for _, w := range searchWords(d.Entry.Description) {
+				termBest = max(termBest, weighted("desc", matchScore(term, w)))
+			}
+			termBest = max(termBest, weighted("owner", matchScore(term, strings.ToLower(d.Entry.Owner))))
+			for _, w := range searchWords(d.Details) {
+				termBest = max(termBest, weighted("details", matchScore(term, w)))
+			}
+			score += termBest
+		}
+		if score == 0 {
+			continue
+		}
+		// Reward a query that spells out a whole filename, e.g. "csv to json" for csv-to-json.py.
+		if len(terms) > 1 {
+			for _, f := range d.Entry.Filenames {
+				base := strings.ToLower(strings.TrimSuffix(filepath.Base(f), filepath.Ext(f)))
+				if strings.Join(searchWords(base), "") == whole {
+					score += scoreExact
+					bestName = base
+					break
+				}
+			}
+		}
+		if bestName == "" && len(d.Entry.Filenames) > 0 {
+			f := d.Entry.Filenames[0]
+			bestName = strings.TrimSuffix(filepath.Base(f), filepath.Ext(f))
+		}
+		results = append(results, Result{Entry: d.Entry, Name: bestName, Score: score})
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+func weighted(field string, score int) int {
+	return score * fieldWeight[field] / 100
+}
+
+// matchScore compares a lowercased query term with a lowercased word.
+func matchScore(term, word string) int {
+	switch {
+	case word == "" || term == "":
+		return 0
+	case word == term:
+		return scoreExact
+	case strings.HasPrefix(word, term):
+		return scorePrefix
+	case len(term) >= 3 && strings.Contains(word, term):
+		return scoreSubstring
+	}
+	if len(term) >= 4 {
+		limit := 1
+		if len(term) >= 8 {
+			limit = 2
+		}
+		if d := editDistance(term, word); d <= limit {
+			return scoreFuzzy - 5*(d-1)
+		}
+		if isSubsequence(term, word) && len(word) <= 2*len(term) {
+			return scoreFuzzy / 2
+		}
+	}
+	return 0
+}
+
+// searchWords lowercases s and splits it on anything that is not a letter or digit.
+func searchWords(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func isSubsequence(needle, hay string) bool {
+	rn := []rune(needle)
+	i := 0
+	for _, r := range hay {
+		if i < len(rn) && rn[i] == r {
+			i++
+		}
+	}
+	return i == len(rn)
+}
+
+// editDistance is the Levenshtein distance between a and b, counting an adjacent
+// transposition as one edit.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev2 := make([]int, len(rb)+1)
+	prev := make([]int, len(rb)+1)
+	cur := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		cur[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(rb)]
+}
@@ -0,0 +1,143 @@
+// Package lock reads and writes gixt.lock, which pins the gists a project runs to exact
+// revisions and file hashes.
+package lock
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+const FileName = "gixt.lock"
+
+// Entry pins one gist. Files maps each materialized file name to its SHA-256.
+type Entry struct {
+	Gist  string            `json:"gist"` // gist key (see gist.Key)
+	SHA   string            `json:"sha"`
+	Files map[string]string `json:"files"`
+}
+
+// File is the lockfile: identifiers as written by `gixt lock add` mapped to their pins.
+type File struct {
+	Version int              `json:"version"`
+	Gists   map[string]Entry `json:"gists"`
+}
+
+// Find walks up from dir to the filesystem root and returns the first gixt.lock found.
+func Find(dir string) (string, bool) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		path := filepath.Join(dir, FileName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+func Load(path string) (File, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return File{}, fmt.Errorf("read lockfile: %w", err)
+	}
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return File{}, fmt.Errorf("parse lockfile %s: %w", path, err)
+	}
+	if f.Gists == nil {
+		f.Gists = map[string]Entry{}
+	}
+	return f, nil
+}
+
+func Save(path string, f File) error {
+	f.Version = 1
+	if f.Gists == nil {
+		f.Gists = map[string]Entry{}
+	}
+	buf, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode lockfile: %w", err)
+	}
+	if err := os.WriteFile(path, append(buf, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write lockfile: %w", err)
+	}
+	return nil
+}
+
+// Lookup finds the pin for identifier, either by the name it was added under or by the
+// gist key it resolved to.
+func (f File) Lookup(identifier string, key string) (string, Entry, bool) {
+	if e, ok := f.Gists[identifier]; ok {
+		return identifier, e, true
+	}
+	if key == "" {
+		return "", Entry{}, false
+	}
+	for name, e := range f.Gists {
+		if e.Gist == key {
+			return name, e, true
+		}
+	}
+	return "", Entry{}, false
+}
+
+// Names returns the locked identifiers in sorted order.
+func (f File) Names() []string {
+	names := make([]string, 0, len(f.Gists))
+	for n := range f.Gists {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// HashFiles hashes each named file under dir.
+func HashFiles(dir string, names []string) (map[string]string, error) {
+	out := make(map[string]string, len(names))
+	for _, name := range names {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return nil, fmt.Errorf("hash %s: %w", name, err)
+		}
+		sum := sha256.Sum256(data)
+		out[name] = hex.EncodeToString(sum[:])
+	}
+	return out, nil
+}
+
+// Verify compares the files under dir against the entry and describes every mismatch.
+func (e Entry) Verify(dir string, names []string) []string {
+	var problems []string
+	got, err := HashFiles(dir, names)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	for _, name := range names {
+		want, ok := e.Files[name]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("%s: not in lockfile", name))
+		case want != got[name]:
+			problems = append(problems, fmt.Sprintf("%s: hash mismatch", name))
+		}
+	}
+	for name := range e.Files {
+		if _, ok := got[name]; !ok {
+			problems = append(problems, fmt.Sprintf("%s: missing", name))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
@@ -0,0 +1,57 @@
+package lock
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindWalksUpAndLookupMatchesNameOrKey(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(nested, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := Find(nested); ok {
+		t.Fatalf("no lockfile expected yet")
+	}
+	path := filepath.Join(root, FileName)
+	f := File{Gists: map[string]Entry{"build": {Gist: "deadbeef", SHA: "rev1"}}}
+	if err := Save(path, f); err != nil {
+		t.Fatal(err)
+	}
+	found, ok := Find(nested)
+	if !ok || found != path {
+		t.Fatalf("Find = %q, %v; want %q", found, ok, path)
+	}
+	loaded, err := Load(found)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, e, ok := loaded.Lookup("alice/build", "deadbeef"); !ok || name != "build" || e.SHA != "rev1" {
+		t.Fatalf("lookup by key failed: %q %+v %v", name, e, ok)
+	}
+	if _, _, ok := loaded.Lookup("other", "cafe"); ok {
+		t.Fatalf("unexpected match")
+	}
+}
+
+func TestVerifyReportsChangedAndMissingFiles(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "run.sh"), []byte("echo hi\n"), 0o644)
+	hashes, err := HashFiles(dir, []string{"run.sh"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := Entry{Files: hashes}
+	if problems := e.Verify(dir, []string{"run.sh"}); len(problems) != 0 {
+		t.Fatalf("unexpected problems %v", problems)
+	}
+
+	os.WriteFile(filepath.Join(dir, "run.sh"), []byte("rm -rf ~\n"), 0o644)
+	e.Files["lib.sh"] = "00"
+	problems := e.Verify(dir, []string{"run.sh"})
+	if len(problems) != 2 || problems[0] != "lib.sh: missing" || problems[1] != "run.sh: hash mismatch" {
+		t.Fatalf("unexpected problems %v", problems)
+	}
+}
@@ -0,0 +1,145 @@
+// Package rlimit describes the resource limits a gist runs under and applies them with
+// setrlimit where the platform supports it.
+package rlimit
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/leolaurindo/gixt/internal/bytesize"
+)
+
+// ErrUnsupported is returned by Apply on platforms without setrlimit support in gixt.
+var ErrUnsupported = errors.New("resource limits are only supported on Linux")
+
+// Spec is how limits are written in a manifest, in settings, and on the command line.
+type Spec struct {
+	Memory    string `json:"memory,omitempty"`    // address space, e.g. "512M"
+	CPU       string `json:"cpu,omitempty"`       // CPU time, e.g. "30s" or "90"
+	FileSize  string `json:"file_size,omitempty"` // largest file the gist may write, e.g. "100M"
+	Processes int    `json:"processes,omitempty"` // processes your user may have at once
+}
+
+// Limits are parsed limits. A zero field is no limit.
+type Limits struct {
+	Memory    uint64        // bytes of address space
+	CPU       time.Duration // CPU time, applied in whole seconds
+	FileSize  uint64        // bytes
+	Processes uint64
+}
+
+// Parse checks s and converts it to Limits.
+func (s Spec) Parse() (Limits, error) {
+	var l Limits
+	var err error
+	if s.Memory != "" {
+		if l.Memory, err = parseSize(s.Memory); err != nil {
+			return Limits{}, fmt.Errorf("memory: %w", err)
+		}
+	}
+	if s.CPU != "" {
+		if l.CPU, err = parseCPU(s.CPU); err != nil {
+			return Limits{}, fmt.Errorf("cpu: %w", err)
+		}
+	}
+	if s.FileSize != "" {
+		if l.FileSize, err = parseSize(s.FileSize); err != nil {
+			return Limits{}, fmt.Errorf("file_size: %w", err)
+		}
+	}
+	if s.Processes < 0 {
+		return Limits{}, fmt.Errorf("processes: must be positive, got %d", s.Processes)
+	}
+	l.Processes = uint64(s.Processes)
+	return l, nil
+}
+
+// IsZero reports whether no limit is set.
+func (l Limits) IsZero() bool {
+	return l == Limits{}
+}
+
+// Tightest combines two sets of limits, keeping the lower value where both set one.
+func Tightest(a, b Limits) Limits {
+	return Limits{
+		Memory:    lower(a.Memory, b.Memory),
+		CPU:       time.Duration(lower(uint64(a.CPU), uint64(b.CPU))),
+		FileSize:  lower(a.FileSize, b.FileSize),
+		Processes: lower(a.Processes, b.Processes),
+	}
+}
+
+// Override returns l with the limits set in o replacing its own.
+func (l Limits) Override(o Limits) Limits {
+	if o.Memory != 0 {
+		l.Memory = o.Memory
+	}
+	if o.CPU != 0 {
+		l.CPU = o.CPU
+	}
+	if o.FileSize != 0 {
+		l.FileSize = o.FileSize
+	}
+	if o.Processes != 0 {
+		l.Processes = o.Processes
+	}
+	return l
+}
+
+// CPUSeconds is the CPU limit rounded up to whole seconds, as setrlimit takes it.
+func (l Limits) CPUSeconds() uint64 {
+	return uint64((l.CPU + time.Second - 1) / time.Second)
+}
+
+// Describe lists the set limits, such as "memory 512M", in a fixed order.
+func (l Limits) Describe() []string {
+	var out []string
+	if l.Memory != 0 {
+		out = append(out, "memory "+bytesize.Format(int64(l.Memory)))
+	}
+	if l.CPU != 0 {
+		out = append(out, "cpu "+l.CPU.String())
+	}
+	if l.FileSize != 0 {
+		out = append(out, "file size "+bytesize.Format(int64(l.FileSize)))
+	}
+	if l.Processes != 0 {
+		out = append(out, fmt.Sprintf("processes %d", l.Processes))
+	}
+	return out
+}
+
+func lower(a, b uint64) uint64 {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// parseSize is bytesize.Parse for limits, where zero would mean no limit at all.
+func parseSize(s string) (uint64, error) {
+	n, err := bytesize.Parse(s)
+	if err != nil {
+		return 0, err
+	}
+	if n == 0 {
+		return 0, fmt.Errorf("invalid size %q: must be more than zero", s)
+	}
+	return uint64(n), nil
+}
+
+// parseCPU accepts a Go duration or a number of seconds.
+func parseCPU(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.ParseUint(s, 10, 32); err == nil && n > 0 {
+		return time.Duration(n) * time.Second, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid CPU time %q (use e.g. 30s, 5m or 90)", s)
+	}
+	return d, nil
+}
@@ -0,0 +1,53 @@
+//go:build linux
+
+package rlimit
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"syscall"
+)
+
+// Supported reports whether Apply works on this platform.
+const Supported = true
+
+// rlimitNproc is RLIMIT_NPROC, which package syscall does not define.
+func rlimitNproc() int {
+	if strings.HasPrefix(runtime.GOARCH, "mips") {
+		return 8
+	}
+	return 6
+}
+
+// Apply sets the limits on the calling process, so they hold for what it execs next.
+// Limits above the current hard limit are capped to it, as raising one needs privileges.
+// The CPU hard limit is one second above the soft one: the process gets SIGXCPU first,
+// then SIGKILL if it keeps running.
+func (l Limits) Apply() error {
+	set := []struct {
+		name     string
+		resource int
+		soft     uint64
+		hard     uint64
+	}{
+		{"memory", syscall.RLIMIT_AS, l.Memory, l.Memory},
+		{"cpu", syscall.RLIMIT_CPU, l.CPUSeconds(), l.CPUSeconds() + 1},
+		{"file size", syscall.RLIMIT_FSIZE, l.FileSize, l.FileSize},
+		{"processes", rlimitNproc(), l.Processes, l.Processes},
+	}
+	for _, s := range set {
+		if s.soft == 0 {
+			continue
+		}
+		var cur syscall.Rlimit
+		if err := syscall.Getrlimit(s.resource, &cur); err != nil {
+			return fmt.Errorf("read %s limit: %w", s.name, err)
+		}
+		next := syscall.Rlimit{Cur: min(s.soft, cur.Max), Max: min(s.hard, cur.Max)}
+		if err := syscall.Setrlimit(s.resource, &next); err != nil {
+			return fmt.Errorf("set %s limit: %w", s.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package rlimit
+
+// Supported reports whether Apply works on this platform.
+const Supported = false
+
+func (l Limits) Apply() error {
+	return ErrUnsupported
+}
@@ -0,0 +1,35 @@
+package rlimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSpecParse(t *testing.T) {
+	l, err := Spec{Memory: "512M", CPU: "90", FileSize: "1.5G", Processes: 64}.Parse()
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	want := Limits{Memory: 512 << 20, CPU: 90 * time.Second, FileSize: 3 << 29, Processes: 64}
+	if l != want {
+		t.Fatalf("got %+v, want %+v", l, want)
+	}
+	for _, bad := range []Spec{{Memory: "lots"}, {CPU: "-1s"}, {FileSize: "0"}, {Processes: -1}} {
+		if _, err := bad.Parse(); err == nil {
+			t.Errorf("expected error for %+v", bad)
+		}
+	}
+}
+
+func TestTightestThenOverride(t *testing.T) {
+	manifest := Limits{Memory: 1 << 30, CPU: time.Minute}
+	settings := Limits{Memory: 256 << 20, FileSize: 1 << 20}
+	got := Tightest(manifest, settings).Override(Limits{CPU: 2 * time.Minute})
+	want := Limits{Memory: 256 << 20, CPU: 2 * time.Minute, FileSize: 1 << 20}
+	if got != want {
+		t.Fatalf("got %+v, want %+v", got, want)
+	}
+	if d := got.Describe(); len(d) != 3 || d[0] != "memory 256MB" || d[1] != "cpu 2m0s" || d[2] != "file size 1MB" {
+		t.Fatalf("describe = %v", d)
+	}
+}
@@ -0,0 +1,498 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// ArgSpec declares the arguments a gist accepts (the manifest "args" section). When present,
+// gixt validates forwarded args against it before running and generates `--help` output.
+type ArgSpec struct {
+	Positional []Param `json:"positional,omitempty"`
+	Flags      []Param `json:"flags,omitempty"`
+}
+
+// Param is one declared positional argument or flag.
+type Param struct {
+	Name     string   `json:"name"`
+	Short    string   `json:"short,omitempty"` // flags only: one-letter alias used as -x
+	Type     string   `json:"type,omitempty"`  // string (default), int, float, bool, path
+	Default  Scalar   `json:"default,omitempty"`
+	Required bool     `json:"required,omitempty"`
+	Choices  []string `json:"choices,omitempty"`
+	Help     string   `json:"help,omitempty"`
+	Variadic bool     `json:"variadic,omitempty"` // last positional only: collects the remaining args
+}
+
+// Scalar is a manifest value written as a JSON string, number, or boolean.
+type Scalar string
+
+func (s *Scalar) UnmarshalJSON(data []byte) error {
+	var str string
+	if err := json.Unmarshal(data, &str); err == nil {
+		*s = Scalar(str)
+		return nil
+	}
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch v.(type) {
+	case float64, bool:
+		*s = Scalar(strings.TrimSpace(string(data)))
+		return nil
+	}
+	return fmt.Errorf("expected a string, number, or boolean, got %s", data)
+}
+
+const (
+	TypeString = "string"
+	TypeInt    = "int"
+	TypeFloat  = "float"
+	TypeBool   = "bool"
+	TypePath   = "path"
+)
+
+// ErrHelp is returned by ParseArgs when the args ask for the generated usage.
+var ErrHelp = errors.New("help requested")
+
+var (
+	paramNameRe   = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+	placeholderRe = regexp.MustCompile(`\{\{\s*([A-Za-z][A-Za-z0-9_-]*)\s*\}\}`)
+)
+
+// Args holds forwarded args after they were checked against an ArgSpec.
+type Args struct {
+	Values map[string][]string // by param name, with defaults applied
+	Argv   []string            // the forwarded args, minus a leading "--" separator
+}
+
+func (p Param) kind() string {
+	if p.Type == "" {
+		return TypeString
+	}
+	return p.Type
+}
+
+// ParseArgs checks argv against spec: flags as --name value, --name=value, or -x value
+// (bool flags take no value), positionals in order, and "--" to end flag parsing. Values are
+// checked for type and choices, defaults fill in missing params, and relative path values
+// are made absolute against callerDir. It returns ErrHelp for -h/--help unless the spec
+// declares those names itself.
+func ParseArgs(spec ArgSpec, argv []string, callerDir string) (Args, error) {
+	if len(argv) > 0 && argv[0] == "--" {
+		argv = argv[1:]
+	}
+	out := Args{Values: map[string][]string{}, Argv: append([]string{}, argv...)}
+	var positionals []string
+	flagsDone := false
+	for i := 0; i < len(argv); i++ {
+		tok := argv[i]
+		if flagsDone || !looksLikeFlag(tok) {
+			positionals = append(positionals, tok)
+			continue
+		}
+		if tok == "--" {
+			flagsDone = true
+			continue
+		}
+		name, value, hasValue := strings.TrimLeft(tok, "-"), "", false
+		if eq := strings.Index(name, "="); eq >= 0 {
+			name, value, hasValue = name[:eq], name[eq+1:], true
+		}
+		p, ok := spec.flag(name, !strings.HasPrefix(tok, "--"))
+		if !ok {
+			if name == "help" || name == "h" {
+				return Args{}, ErrHelp
+			}
+			return Args{}, fmt.Errorf("unknown flag %s", tok)
+		}
+		if !hasValue {
+			if p.kind() == TypeBool {
+				value = "true"
+			} else {
+				if i+1 >= len(argv) {
+					return Args{}, fmt.Errorf("flag --%s needs a value", p.Name)
+				}
+				i++
+				value = argv[i]
+			}
+		}
+		out.Values[p.Name] = []string{value}
+	}
+
+	for i, p := range spec.Positional {
+		switch {
+		case p.Variadic:
+			if len(positionals) > 0 {
+				out.Values[p.Name] = positionals
+			}
+			positionals = nil
+		case len(positionals) > 0:
+			out.Values[p.Name] = []string{positionals[0]}
+			positionals = positionals[1:]
+		}
+		if i == len(spec.Positional)-1 && len(positionals) > 0 {
+			return Args{}, fmt.Errorf("unexpected argument %q", positionals[0])
+		}
+	}
+	if len(spec.Positional) == 0 && len(positionals) > 0 {
+		return Args{}, fmt.Errorf("unexpected argument %q", positionals[0])
+	}
+
+	for _, p := range spec.all() {
+		vals, ok := out.Values[p.Name]
+		if !ok {
+			switch {
+			case p.Required:
+				return Args{}, fmt.Errorf("missing required %s", spec.label(p))
+			case p.Default != "":
+				vals = []string{string(p.Default)}
+			case p.kind() == TypeBool:
+				vals = []string{"false"}
+			default:
+				continue
+			}
+		}
+		for j, v := range vals {
+			checked, err := checkValue(p, v, callerDir)
+			if err != nil {
+				return Args{}, fmt.Errorf("%s: %w", spec.label(p), err)
+			}
+			vals[j] = checked
+		}
+		out.Values[p.Name] = vals
+	}
+	return out, nil
+}
+
+// looksLikeFlag reports whether tok should be parsed as a flag; "-" and negative numbers
+// are positionals.
+func looksLikeFlag(tok string) bool {
+	if len(tok) < 2 || tok[0] != '-' {
+		return false
+	}
+	_, err := strconv.ParseFloat(tok, 64)
+	return err != nil
+}
+
+func checkValue(p Param, v string, callerDir string) (string, error) {
+	switch p.kind() {
+	case TypeInt:
+		if _, err := strconv.ParseInt(v, 10, 64); err != nil {
+			return "", fmt.Errorf("%q is not an integer", v)
+		}
+	case TypeFloat:
+		if _, err := strconv.ParseFloat(v, 64); err != nil {
+			return "", fmt.Errorf("%q is not a number", v)
+		}
+	case TypeBool:
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return "", fmt.Errorf("%q is not true or false", v)
+		}
+		v = strconv.FormatBool(b)
+	case TypePath:
+		if callerDir != "" && v != "" && !filepath.IsAbs(v) {
+			v = filepath.Clean(filepath.Join(callerDir, v))
+		}
+	}
+	if len(p.Choices) > 0 {
+		for _, c := range p.Choices {
+			if c == v {
+				return v, nil
+			}
+		}
+		return "", fmt.Errorf("%q is not one of %s", v, strings.Join(p.Choices, ", "))
+	}
+	return v, nil
+}
+
+func (s ArgSpec) all() []Param {
+	return append(append([]Param{}, s.Positional...), s.Flags...)
+}
+
+func (s ArgSpec) flag(name string, short bool) (Param, bool) {
+	for _, p := range s.Flags {
+		if (!short && p.Name == name) || (short && p.Short != "" && p.Short == name) {
+			return p, true
+		}
+	}
+	return Param{}, false
+}
+
+// label names a param in error messages: "argument <name>" or "flag --name".
+func (s ArgSpec) label(p Param) string {
+	for _, pos := range s.Positional {
+		if pos.Name == p.Name {
+			return "argument <" + p.Name + ">"
+		}
+	}
+	return "flag --" + p.Name
+}
+
+// Env returns the parsed values as GIXT_ARG_<NAME> variables (variadic values space-joined).
+func (a Args) Env() map[string]string {
+	env := map[string]string{}
+	for name, vals := range a.Values {
+		env[ArgEnvName(name)] = strings.Join(vals, " ")
+	}
+	return env
+}
+
+// ArgEnvName is the env var carrying a declared arg: GIXT_ARG_ plus the name upper-cased
+// with dashes turned into underscores.
+func ArgEnvName(name string) string {
+	return "GIXT_ARG_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// HasPlaceholders reports whether run refers to declared args as {{name}}.
+func HasPlaceholders(run string) bool {
+	return placeholderRe.MatchString(run)
+}
+
+// ExpandPlaceholders replaces each {{name}} in run with the shell-quoted value(s) of that arg;
+// args without a value expand to nothing.
+func ExpandPlaceholders(run string, a Args) string {
+	return placeholderRe.ReplaceAllStringFunc(run, func(m string) string {
+		name := placeholderRe.FindStringSubmatch(m)[1]
+		var quoted []string
+		for _, v := range a.Values[name] {
+			quoted = append(quoted, shellQuote(v))
+		}
+		return strings.Join(quoted, " ")
+	})
+}
+
+// ExpandArgv is ExpandPlaceholders for argv-form runs. Values are substituted verbatim: an
+// element that is exactly one placeholder becomes one element per value (none when unset),
+// and placeholders inside a longer element are replaced by the values joined with spaces.
+func ExpandArgv(argv []string, a Args) []string {
+	if argv == nil {
+		return nil
+	}
+	out := make([]string, 0, len(argv))
+	for _, el := range argv {
+		if m := placeholderRe.FindStringSubmatch(el); m != nil && m[0] == el {
+			out = append(out, a.Values[m[1]]...)
+			continue
+		}
+		out = append(out, placeholderRe.ReplaceAllStringFunc(el, func(m string) string {
+			return strings.Join(a.Values[placeholderRe.FindStringSubmatch(m)[1]], " ")
+		}))
+	}
+	return out
+}
+
+// shellQuote quotes s for the shell that runs string-form manifests (sh -c or cmd /C).
+func shellQuote(s string) string {
+	if runtime.GOOS == "windows" {
+		if s != "" && !strings.ContainsAny(s, " \t\"&|<>^%") {
+			return s
+		}
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@%+,") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func validateArgSpec(s ArgSpec, run string) error {
+	seen := map[string]bool{}
+	shorts := map[string]bool{}
+	optionalSeen := false
+	for i := range s.Positional {
+		p := s.Positional[i]
+		if err := validateParam(p, seen); err != nil {
+			return err
+		}
+		if p.Short != "" {
+			return fmt.Errorf("run manifest arg %s: short is only valid for flags", p.Name)
+		}
+		if p.kind() == TypeBool {
+			return fmt.Errorf("run manifest arg %s: bool is only valid for flags", p.Name)
+		}
+		if p.Variadic && i != len(s.Positional)-1 {
+			return fmt.Errorf("run manifest arg %s: only the last positional can be variadic", p.Name)
+		}
+		if p.Required && optionalSeen {
+			return fmt.Errorf("run manifest arg %s: required positionals must come before optional ones", p.Name)
+		}
+		if !p.Required {
+			optionalSeen = true
+		}
+	}
+	for _, p := range s.Flags {
+		if err := validateParam(p, seen); err != nil {
+			return err
+		}
+		if p.Variadic {
+			return fmt.Errorf("run manifest flag %s: variadic is only valid for positionals", p.Name)
+		}
+		if p.Short != "" {
+			if len(p.Short) != 1 || !paramNameRe.MatchString(p.Short) {
+				return fmt.Errorf("run manifest flag %s: short must be a single letter", p.Name)
+			}
+			if shorts[p.Short] {
+				return fmt.Errorf("run manifest flag %s: duplicate short -%s", p.Name, p.Short)
+			}
+			shorts[p.Short] = true
+		}
+	}
+	for _, m := range placeholderRe.FindAllStringSubmatch(run, -1) {
+		if !seen[m[1]] {
+			return fmt.Errorf("run manifest run refers to undeclared arg {{%s}}", m[1])
+		}
+	}
+	return nil
+}
+
+func validateParam(p Param, seen map[string]bool) error {
+	if !paramNameRe.MatchString(p.Name) {
+		return fmt.Errorf("run manifest arg name %q must start with a letter and use letters, digits, - or _", p.Name)
+	}
+	if seen[p.Name] {
+		return fmt.Errorf("run manifest arg %s declared twice", p.Name)
+	}
+	seen[p.Name] = true
+	switch p.kind() {
+	case TypeString, TypeInt, TypeFloat, TypeBool, TypePath:
+	default:
+		return fmt.Errorf("run manifest arg %s: unknown type %q (use string, int, float, bool, or path)", p.Name, p.Type)
+	}
+	if len(p.Help) > 1024 {
+		return fmt.Errorf("run manifest arg %s: help too long", p.Name)
+	}
+	for _, c := range p.Choices {
+		if _, err := checkValue(Param{Type: p.Type}, c, ""); err != nil {
+			return fmt.Errorf("run manifest arg %s: choice %w", p.Name, err)
+		}
+	}
+	if p.Default != "" {
+		if _, err := checkValue(p, string(p.Default), ""); err != nil {
+			return fmt.Errorf("run manifest arg %s: default %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// Usage renders the generated help for a manifest with declared args.
+func Usage(prog string, m RunManifest) string {
+	var b strings.Builder
+	spec := ArgSpec{}
+	if m.Args != nil {
+		spec = *m.Args
+	}
+	fmt.Fprintf(&b, "usage: gixt %s", prog)
+	if len(m.Commands) > 0 && m.Args == nil {
+		b.WriteString(" <command> [args...]")
+	}
+	if len(spec.Flags) > 0 {
+		b.WriteString(" [flags]")
+	}
+	for _, p := range spec.Positional {
+		name := "<" + p.Name + ">"
+		if p.Variadic {
+			name += "..."
+		}
+		if !p.Required {
+			name = "[" + name + "]"
+		}
+		b.WriteString(" " + name)
+	}
+	b.WriteString("\n")
+	if d := strings.TrimSpace(m.Details); d != "" && d != DefaultDetails {
+		fmt.Fprintf(&b, "\n%s\n", d)
+	}
+	if len(m.Platforms) > 0 {
+		fmt.Fprintf(&b, "\nplatforms: %s\n", strings.Join(m.Platforms, ", "))
+	}
+	if len(spec.Positional) > 0 {
+		b.WriteString("\narguments:\n")
+		writeParams(&b, spec.Positional, func(p Param) string { return p.Name })
+	}
+	if len(spec.Flags) > 0 {
+		b.WriteString("\nflags:\n")
+		writeParams(&b, spec.Flags, func(p Param) string {
+			head := "--" + p.Name
+			if p.Short != "" {
+				head = "-" + p.Short + ", " + head
+			}
+			if p.kind() != TypeBool {
+				head += " " + p.kind()
+			}
+			return head
+		})
+	}
+	if len(m.Commands) > 0 {
+		b.WriteString("\ncommands:\n")
+		names := m.CommandNames()
+		width := 0
+		for _, name := range names {
+			width = max(width, len(name))
+		}
+		for _, name := range names {
+			line := strings.TrimSpace(m.Commands[name].Details)
+			if name == m.Default {
+				line = strings.TrimSpace(line + " (default)")
+			}
+			fmt.Fprintf(&b, "  %-*s  %s\n", width, name, line)
+		}
+		fmt.Fprintf(&b, "\nrun a command with `gixt %s:<command>` or `gixt %s <command>`\n", prog, prog)
+	}
+	return b.String()
+}
+
+func writeParams(b *strings.Builder, params []Param, head func(Param) string) {
+	width := 0
+	for _, p := range params {
+		width = max(width, len(head(p)))
+	}
+	for _, p := range params {
+		var notes []string
+		if p.Required {
+			notes = append(notes, "required")
+		}
+		if len(p.Choices) > 0 {
+			notes = append(notes, "one of: "+strings.Join(p.Choices, ", "))
+		}
+		if p.Default != "" {
+			notes = append(notes, "default: "+string(p.Default))
+		}
+		line := strings.TrimSpace(p.Help)
+		if len(notes) > 0 {
+			line = strings.TrimSpace(line + " (" + strings.Join(notes, "; ") + ")")
+		}
+		fmt.Fprintf(b, "  %-*s  %s\n", width, head(p), line)
+	}
+}
+
+// rebaseUserArgs makes relative args absolute against callerDir when they name an existing
+// file there, so they still work when the gist runs in its work dir.
+func rebaseUserArgs(args []string, callerDir string) []string {
+	if callerDir == "" {
+		return args
+	}
+	resolved := make([]string, 0, len(args))
+	for _, a := range args {
+		if filepath.IsAbs(a) {
+			resolved = append(resolved, a)
+			continue
+		}
+		candidate := filepath.Clean(filepath.Join(callerDir, a))
+		if _, err := os.Stat(candidate); err == nil {
+			resolved = append(resolved, candidate)
+			continue
+		}
+		resolved = append(resolved, a)
+	}
+	return resolved
+}
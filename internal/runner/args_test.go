@@ -0,0 +1,81 @@
+package runner
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const argsManifest = `{
+  "run": "python conv.py {{input}} --to {{format}}",
+  "details": "Convert a CSV file",
+  "args": {
+    "positional": [{"name": "input", "type": "path", "required": true, "help": "CSV file"}],
+    "flags": [
+      {"name": "format", "short": "f", "default": "json", "choices": ["json", "yaml"], "help": "output format"},
+      {"name": "limit", "type": "int", "default": 10},
+      {"name": "dry-run", "type": "bool"}
+    ]
+  }
+}`
+
+func TestParseArgsAppliesTypesDefaultsAndChoices(t *testing.T) {
+	m, err := LoadRunManifestBytes([]byte(argsManifest))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	caller := t.TempDir()
+	got, err := ParseArgs(*m.Args, []string{"--", "data.csv", "-f", "yaml", "--dry-run"}, caller)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if v := got.Values["input"]; len(v) != 1 || v[0] != filepath.Join(caller, "data.csv") {
+		t.Fatalf("path not resolved against caller dir: %v", v)
+	}
+	if got.Values["format"][0] != "yaml" || got.Values["limit"][0] != "10" || got.Values["dry-run"][0] != "true" {
+		t.Fatalf("unexpected values %v", got.Values)
+	}
+	if env := got.Env(); env["GIXT_ARG_DRY_RUN"] != "true" || env["GIXT_ARG_FORMAT"] != "yaml" {
+		t.Fatalf("unexpected env %v", env)
+	}
+
+	bad := map[string][]string{
+		"missing required argument <input>": nil,
+		`"xml" is not one of json, yaml`:    {"a.csv", "--format=xml"},
+		`"many" is not an integer`:          {"a.csv", "--limit", "many"},
+		"unknown flag --nope":               {"a.csv", "--nope"},
+		`unexpected argument "b.csv"`:       {"a.csv", "b.csv"},
+	}
+	for want, argv := range bad {
+		if _, err := ParseArgs(*m.Args, argv, caller); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("ParseArgs(%v) = %v, want error containing %q", argv, err, want)
+		}
+	}
+	if _, err := ParseArgs(*m.Args, []string{"--help"}, caller); !errors.Is(err, ErrHelp) {
+		t.Fatalf("expected ErrHelp, got %v", err)
+	}
+
+	usage := Usage("csv2", m)
+	for _, want := range []string{"usage: gixt csv2 [flags] <input>", "Convert a CSV file", "-f, --format string", "one of: json, yaml; default: json"} {
+		if !strings.Contains(usage, want) {
+			t.Fatalf("usage missing %q:\n%s", want, usage)
+		}
+	}
+}
+
+func TestLoadRunManifestRejectsBadArgs(t *testing.T) {
+	cases := []string{
+		`{"run": "echo {{name}}"}`,
+		`{"run": "echo {{other}}", "args": {"positional": [{"name": "name"}]}}`,
+		`{"run": "echo", "args": {"flags": [{"name": "n", "type": "number"}]}}`,
+		`{"run": "echo", "args": {"flags": [{"name": "n", "type": "int", "default": "x"}]}}`,
+		`{"run": "echo", "args": {"positional": [{"name": "a", "variadic": true}, {"name": "b"}]}}`,
+		`{"run": "echo", "args": {"positional": [{"name": "a"}, {"name": "b", "required": true}]}}`,
+	}
+	for _, data := range cases {
+		if _, err := LoadRunManifestBytes([]byte(data)); err == nil {
+			t.Errorf("expected error for %s", data)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package runner
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// Build is a compile step for a gist in a compiled language. The resolved command execs
+// Binary directly; the caller runs Argv from Dir first when Binary does not exist yet.
+type Build struct {
+	Lang      string   // go, rust, c, c++
+	Argv      []string // compiler invocation
+	Dir       string   // where to run Argv (the gist work dir)
+	Binary    string   // output path, unique per toolchain version
+	Toolchain string   // version line of the compiler the binary is keyed by
+}
+
+// Built reports whether the binary already exists.
+func (b Build) Built() bool {
+	info, err := os.Stat(b.Binary)
+	return err == nil && !info.IsDir()
+}
+
+// toolchainVersion runs a compiler's version command; tests replace it.
+var toolchainVersion = func(prog string, args ...string) (string, error) {
+	if _, err := exec.LookPath(prog); err != nil {
+		return "", fmt.Errorf("%s not found on PATH", prog)
+	}
+	out, err := exec.Command(prog, args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s %s: %w", prog, strings.Join(args, " "), err)
+	}
+	line, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	return strings.TrimSpace(line), nil
+}
+
+var cargoNameRe = regexp.MustCompile(`^\s*name\s*=\s*"([^"]+)"`)
+
+// planBuild returns the build for chosen when it is Go, Rust, C or C++ source (or a go.mod /
+// Cargo.toml), placing the binary under buildRoot/<lang>-<toolchain hash>/.
+func planBuild(dir string, files []string, chosen string, buildRoot string) (Build, bool, error) {
+	has := func(name string) bool {
+		for _, f := range files {
+			if f == name {
+				return true
+			}
+		}
+		return false
+	}
+	base := filepath.Base(chosen)
+	name := strings.TrimSuffix(base, filepath.Ext(base))
+	src := filepath.Join(dir, chosen)
+
+	var b Build
+	var versionArgs []string
+	switch ext := strings.ToLower(filepath.Ext(base)); {
+	case ext == ".go" || base == "go.mod":
+		b.Lang, versionArgs = "go", []string{"go", "version"}
+		b.Argv = []string{"go", "build", "-o", "{out}", src}
+		if has("go.mod") {
+			// Build the module so every file and its go.mod requirements are used.
+			b.Argv = []string{"go", "build", "-o", "{out}", "."}
+			if base == "go.mod" {
+				name = filepath.Base(dir)
+			}
+		}
+	case ext == ".rs" || base == "Cargo.toml":
+		b.Lang = "rust"
+		if has("Cargo.toml") {
+			pkg, err := cargoPackageName(filepath.Join(dir, "Cargo.toml"))
+			if err != nil {
+				return Build{}, false, err
+			}
+			versionArgs = []string{"cargo", "--version"}
+			b.Argv = []string{"cargo", "build", "--release", "--quiet", "--manifest-path", filepath.Join(dir, "Cargo.toml"), "--target-dir", "{target}"}
+			name = pkg
+		} else {
+			versionArgs = []string{"rustc", "--version"}
+			b.Argv = []string{"rustc", "-O", "-o", "{out}", src}
+		}
+	case ext == ".c":
+		b.Lang, versionArgs = "c", []string{"cc", "--version"}
+		b.Argv = []string{"cc", "-O2", "-o", "{out}", src}
+	case ext == ".cpp" || ext == ".cc" || ext == ".cxx":
+		b.Lang, versionArgs = "c++", []string{"c++", "--version"}
+		b.Argv = []string{"c++", "-O2", "-o", "{out}", src}
+	default:
+		return Build{}, false, nil
+	}
+
+	version, err := toolchainVersion(versionArgs[0], versionArgs[1:]...)
+	if err != nil {
+		return Build{}, false, fmt.Errorf("cannot build %s: %w", base, err)
+	}
+	sum := sha256.Sum256([]byte(version))
+	outDir := filepath.Join(buildRoot, strings.ReplaceAll(b.Lang, "+", "p")+"-"+hex.EncodeToString(sum[:])[:12])
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	b.Binary = filepath.Join(outDir, name)
+	target := filepath.Join(outDir, "target")
+	if b.Argv[0] == "cargo" {
+		b.Binary = filepath.Join(target, "release", name)
+	}
+	for i, a := range b.Argv {
+		switch a {
+		case "{out}":
+			b.Argv[i] = b.Binary
+		case "{target}":
+			b.Argv[i] = target
+		}
+	}
+	b.Dir = dir
+	b.Toolchain = version
+	return b, true, nil
+}
+
+// cargoPackageName reads the binary name from Cargo.toml: the first [[bin]] name, else the
+// [package] name.
+func cargoPackageName(path string) (string, error) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("read Cargo.toml: %w", err)
+	}
+	defer fh.Close()
+	section, pkg, bin := "", "", ""
+	scanner := bufio.NewScanner(fh)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			section = line
+			continue
+		}
+		m := cargoNameRe.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		switch {
+		case section == "[package]" && pkg == "":
+			pkg = m[1]
+		case section == "[[bin]]" && bin == "":
+			bin = m[1]
+		}
+	}
+	if bin != "" {
+		return bin, nil
+	}
+	if pkg == "" {
+		return "", fmt.Errorf("Cargo.toml has no [package] name")
+	}
+	return pkg, nil
+}
@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPlanBuildPerLanguage(t *testing.T) {
+	orig := toolchainVersion
+	version := "go version go1.22.0 linux/amd64"
+	toolchainVersion = func(prog string, args ...string) (string, error) { return prog + " " + version, nil }
+	t.Cleanup(func() { toolchainVersion = orig })
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Cargo.toml"), []byte("[package]\nname = \"hello\"\nversion = \"0.1.0\"\n\n[[bin]]\nname = \"greet\"\npath = \"main.rs\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	root := filepath.Join(dir, ".gixt-build")
+
+	b, ok, err := planBuild(dir, []string{"tool.go"}, "tool.go", root)
+	if err != nil || !ok || b.Lang != "go" || b.Argv[len(b.Argv)-1] != filepath.Join(dir, "tool.go") || b.Argv[3] != b.Binary {
+		t.Fatalf("single-file go build: %+v %v", b, err)
+	}
+	mod, _, _ := planBuild(dir, []string{"go.mod", "tool.go"}, "tool.go", root)
+	if mod.Argv[len(mod.Argv)-1] != "." {
+		t.Fatalf("go.mod should build the module: %q", mod.Argv)
+	}
+
+	version = "go version go1.23.0 linux/amd64"
+	newer, _, _ := planBuild(dir, []string{"tool.go"}, "tool.go", root)
+	if newer.Binary == b.Binary {
+		t.Fatalf("a new toolchain version must use a new binary path")
+	}
+
+	cargo, _, err := planBuild(dir, []string{"Cargo.toml", "main.rs"}, "main.rs", root)
+	if err != nil || cargo.Argv[0] != "cargo" || !strings.Contains(cargo.Binary, filepath.Join("target", "release", "greet")) {
+		t.Fatalf("cargo build: %+v %v", cargo, err)
+	}
+	rustc, _, _ := planBuild(dir, []string{"main.rs"}, "main.rs", root)
+	if rustc.Argv[0] != "rustc" {
+		t.Fatalf("expected rustc without Cargo.toml: %q", rustc.Argv)
+	}
+	cpp, _, _ := planBuild(dir, []string{"x.cpp"}, "x.cpp", root)
+	if cpp.Lang != "c++" || cpp.Argv[0] != "c++" {
+		t.Fatalf("c++ build: %+v", cpp)
+	}
+	if _, ok, _ := planBuild(dir, []string{"a.py"}, "a.py", root); ok {
+		t.Fatalf("python is not compiled")
+	}
+
+	toolchainVersion = func(prog string, args ...string) (string, error) { return "", os.ErrNotExist }
+	if _, _, err := planBuild(dir, []string{"main.c"}, "main.c", root); err == nil || !strings.Contains(err.Error(), "cannot build main.c") {
+		t.Fatalf("expected missing toolchain error, got %v", err)
+	}
+}
@@ -0,0 +1,153 @@
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// CommandSpec is one named entrypoint in the manifest "commands" map.
+type CommandSpec struct {
+	Run     RunSpec           `json:"run"`
+	Entry   string            `json:"entry,omitempty"` // gist file to run instead of run
+	Env     map[string]string `json:"env,omitempty"`
+	Details string            `json:"details,omitempty"`
+	Args    *ArgSpec          `json:"args,omitempty"`
+
+	RequiredEnv []string `json:"required_env,omitempty"` // added to the top-level required_env
+	Secrets     []string `json:"secrets,omitempty"`      // added to the top-level secrets
+}
+
+// ErrNoCommand is returned by Select when a manifest only has named commands, none was
+// chosen, and there is no default.
+var ErrNoCommand = errors.New("no command chosen")
+
+var commandNameRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// IsCommandName reports whether s can name a manifest command.
+func IsCommandName(s string) bool {
+	return commandNameRe.MatchString(s)
+}
+
+// CommandNames returns the manifest's command names, sorted.
+func (m RunManifest) CommandNames() []string {
+	names := make([]string, 0, len(m.Commands))
+	for name := range m.Commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SecretNames returns every secret name the manifest declares, at the top level or in a
+// command, sorted and without duplicates.
+func (m RunManifest) SecretNames() []string {
+	seen := map[string]bool{}
+	var names []string
+	add := func(list []string) {
+		for _, name := range list {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	add(m.Secrets)
+	for _, c := range m.Commands {
+		add(c.Secrets)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Select narrows m to the entrypoint that should run. name is a command picked explicitly
+// (gixt <gist>:<cmd>); otherwise a first arg naming a command picks it (gixt <gist> <cmd>),
+// then the manifest default, then the top-level run. It returns the narrowed manifest, the
+// chosen command name ("" for the top-level run), and the args left for the command.
+func (m RunManifest) Select(name string, args []string) (RunManifest, string, []string, error) {
+	if len(m.Commands) == 0 {
+		if name != "" {
+			return RunManifest{}, "", nil, fmt.Errorf("manifest defines no commands (asked for %q)", name)
+		}
+		return m, "", args, nil
+	}
+	if name == "" && len(args) > 0 {
+		if _, ok := m.Commands[args[0]]; ok {
+			name, args = args[0], args[1:]
+		}
+	}
+	if name == "" {
+		name = m.Default
+	}
+	if name == "" {
+		if !m.Run.IsZero() || m.Entry != "" {
+			top := m
+			top.Commands, top.Default = nil, ""
+			return top, "", args, nil
+		}
+		return RunManifest{}, "", nil, fmt.Errorf("%w: pick one of %s", ErrNoCommand, strings.Join(m.CommandNames(), ", "))
+	}
+	spec, ok := m.Commands[name]
+	if !ok {
+		return RunManifest{}, "", nil, fmt.Errorf("unknown command %q (available: %s)", name, strings.Join(m.CommandNames(), ", "))
+	}
+	env := map[string]string{}
+	for k, v := range m.Env {
+		env[k] = v
+	}
+	for k, v := range spec.Env {
+		env[k] = v
+	}
+	details := spec.Details
+	if strings.TrimSpace(details) == "" {
+		details = m.Details
+	}
+	required := append(append([]string(nil), m.RequiredEnv...), spec.RequiredEnv...)
+	secrets := append(append([]string(nil), m.Secrets...), spec.Secrets...)
+	return RunManifest{Run: spec.Run, Entry: spec.Entry, Platforms: m.Platforms, Env: env, RequiredEnv: required, Secrets: secrets, Permissions: m.Permissions, Limits: m.Limits, Details: details, Version: m.Version, Args: spec.Args}, name, args, nil
+}
+
+func validateCommands(m RunManifest) error {
+	for name, c := range m.Commands {
+		if !IsCommandName(name) {
+			return fmt.Errorf("run manifest command name %q must start with a letter or digit and use letters, digits, ., - or _", name)
+		}
+		if c.Entry != "" {
+			if !c.Run.IsZero() {
+				return fmt.Errorf("run manifest command %s sets both run and entry; use one", name)
+			}
+			if err := validateEntry(c.Entry, "command "+name); err != nil {
+				return err
+			}
+		} else if err := validateRunSpec(c.Run, "command "+name); err != nil {
+			return err
+		}
+		if err := validateEnvKeys(c.Env); err != nil {
+			return err
+		}
+		if err := validateEnvNames("required_env", c.RequiredEnv); err != nil {
+			return fmt.Errorf("command %s: %w", name, err)
+		}
+		if err := validateEnvNames("secrets", c.Secrets); err != nil {
+			return fmt.Errorf("command %s: %w", name, err)
+		}
+		if len(strings.TrimSpace(c.Details)) > 4096 {
+			return fmt.Errorf("run manifest command %s details too long", name)
+		}
+		if c.Args != nil {
+			if err := validateArgSpec(*c.Args, c.Run.text()); err != nil {
+				return fmt.Errorf("command %s: %w", name, err)
+			}
+		} else if HasPlaceholders(c.Run.text()) {
+			return fmt.Errorf("run manifest command %s uses {{...}} placeholders but declares no args", name)
+		}
+	}
+	if m.Default != "" {
+		if _, ok := m.Commands[m.Default]; !ok {
+			return fmt.Errorf("run manifest default %q is not a declared command", m.Default)
+		}
+	}
+	return nil
+}
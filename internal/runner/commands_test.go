@@ -0,0 +1,67 @@
+package runner
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+const toolkitManifest = `{
+  "env": {"STAGE": "prod"},
+  "details": "deploy toolkit",
+  "default": "status",
+  "commands": {
+    "deploy": {"run": "sh deploy.sh", "details": "ship it", "env": {"STAGE": "staging"}},
+    "rollback": {"run": "sh rollback.sh {{to}}", "args": {"positional": [{"name": "to", "required": true}]}},
+    "status": {"run": "python status.py"}
+  }
+}`
+
+func TestSelectPicksNamedArgOrDefaultCommand(t *testing.T) {
+	m, err := LoadRunManifestBytes([]byte(toolkitManifest))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got := strings.Join(m.CommandNames(), ","); got != "deploy,rollback,status" {
+		t.Fatalf("CommandNames = %s", got)
+	}
+
+	sel, name, args, err := m.Select("deploy", []string{"-v"})
+	if err != nil || name != "deploy" || sel.Run.Line != "sh deploy.sh" || sel.Env["STAGE"] != "staging" || len(args) != 1 {
+		t.Fatalf("explicit: %+v %q %v %v", sel, name, args, err)
+	}
+	sel, name, args, err = m.Select("", []string{"rollback", "v1"})
+	if err != nil || name != "rollback" || sel.Args == nil || len(args) != 1 || args[0] != "v1" {
+		t.Fatalf("first arg: %+v %q %v %v", sel, name, args, err)
+	}
+	sel, name, _, err = m.Select("", []string{"extra"})
+	if err != nil || name != "status" || sel.Details != "deploy toolkit" || sel.Env["STAGE"] != "prod" {
+		t.Fatalf("default: %+v %q %v", sel, name, err)
+	}
+	if _, _, _, err := m.Select("nope", nil); err == nil || !strings.Contains(err.Error(), "available: deploy, rollback, status") {
+		t.Fatalf("expected unknown command error, got %v", err)
+	}
+
+	m.Default = ""
+	if _, _, _, err := m.Select("", nil); !errors.Is(err, ErrNoCommand) {
+		t.Fatalf("expected ErrNoCommand, got %v", err)
+	}
+	if usage := Usage("tools", m); !strings.Contains(usage, "deploy    ship it") || !strings.Contains(usage, "gixt tools:<command>") {
+		t.Fatalf("usage should list commands:\n%s", usage)
+	}
+}
+
+func TestLoadRunManifestValidatesCommands(t *testing.T) {
+	cases := []string{
+		`{"commands": {}}`,
+		`{"default": "x", "commands": {"a": {"run": "echo"}}}`,
+		`{"commands": {"a b": {"run": "echo"}}}`,
+		`{"commands": {"a": {"run": ""}}}`,
+		`{"commands": {"a": {"run": "echo {{x}}"}}}`,
+	}
+	for _, data := range cases {
+		if _, err := LoadRunManifestBytes([]byte(data)); err == nil {
+			t.Errorf("expected error for %s", data)
+		}
+	}
+}
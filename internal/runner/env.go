@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/leolaurindo/gixt/internal/envvar"
+)
+
+// Variables gixt sets for every command, also usable as ${GIXT_WORKDIR} in env and run.
+const (
+	EnvWorkDir   = "GIXT_WORKDIR"    // the dir holding the gist files
+	EnvCallerDir = "GIXT_CALLER_DIR" // the user's shell directory
+)
+
+// environment builds the variables added to a command: the gixt variables, the manifest env
+// with ${VAR} references resolved, req.Secrets, then req.EnvOverrides. References resolve
+// against the overrides, secrets, req.EnvVars and the caller's environment. The returned
+// lookup sees the result over those, for expanding run.
+func (req Request) environment(manifest map[string]string) (map[string]string, envvar.Lookup, error) {
+	caller := req.LookupEnv
+	if caller == nil {
+		caller = os.LookupEnv
+	}
+	builtin := map[string]string{EnvWorkDir: req.Dir}
+	if req.CallerDir != "" {
+		builtin[EnvCallerDir] = req.CallerDir
+	}
+	outer := layered(caller, req.EnvVars, builtin, req.Secrets, req.EnvOverrides)
+	resolved, err := envvar.Resolve(manifest, outer)
+	if err != nil {
+		return nil, nil, fmt.Errorf("run manifest %w", err)
+	}
+	env := map[string]string{}
+	for _, layer := range []map[string]string{builtin, resolved, req.Secrets, req.EnvOverrides} {
+		for k, v := range layer {
+			env[k] = v
+		}
+	}
+	return env, layered(caller, req.EnvVars, env), nil
+}
+
+// layered looks names up in maps from last to first, then in base.
+func layered(base envvar.Lookup, maps ...map[string]string) envvar.Lookup {
+	return func(name string) (string, bool) {
+		for i := len(maps) - 1; i >= 0; i-- {
+			if v, ok := maps[i][name]; ok {
+				return v, true
+			}
+		}
+		return base(name)
+	}
+}
+
+// MissingEnv returns the names in cmd.RequiredEnv that are unset or empty in both the
+// command's env and the caller's environment.
+func MissingEnv(cmd Command, lookup envvar.Lookup) []string {
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+	var missing []string
+	for _, name := range cmd.RequiredEnv {
+		if v, ok := cmd.Env[name]; ok && v != "" {
+			continue
+		}
+		if v, _ := lookup(name); v != "" {
+			continue
+		}
+		missing = append(missing, name)
+	}
+	return missing
+}
@@ -0,0 +1,223 @@
+package runner
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+)
+
+// lookPath finds interpreters on PATH; tests replace it.
+var lookPath = exec.LookPath
+
+// builtinInterpreters lists, per extension, the commands tried in order to run a file. Each
+// entry is a command line; the file path is appended to it.
+var builtinInterpreters = map[string][]string{
+	".sh":   {"sh", "bash"},
+	".bash": {"bash"},
+	".zsh":  {"zsh"},
+	".fish": {"fish"},
+	".nu":   {"nu"},
+	".py":   {"python3", "python"},
+	".js":   {"node", "bun", "deno run"},
+	".mjs":  {"node", "bun", "deno run"},
+	".cjs":  {"node", "bun"},
+	".ts":   {"tsx", "ts-node", "bun", "deno run", "npx ts-node"},
+	".go":   {"go run"},
+	".rb":   {"ruby"},
+	".pl":   {"perl"},
+	".php":  {"php"},
+	".lua":  {"lua", "luajit", "lua5.4", "lua5.3"},
+	".r":    {"Rscript"},
+	".jl":   {"julia"},
+	".ps1":  {"pwsh -NoProfile -File", "powershell -ExecutionPolicy Bypass -File"},
+}
+
+// windowsInterpreters overrides builtinInterpreters on Windows.
+var windowsInterpreters = map[string][]string{
+	".py":  {"python", "py -3", "python3"},
+	".ps1": {"powershell -ExecutionPolicy Bypass -File", "pwsh -NoProfile -ExecutionPolicy Bypass -File"},
+}
+
+// NormalizeExt lowercases ext and makes sure it starts with a dot, so settings may say
+// "py" or ".PY".
+func NormalizeExt(ext string) string {
+	ext = strings.ToLower(strings.TrimSpace(ext))
+	if ext != "" && !strings.HasPrefix(ext, ".") {
+		ext = "." + ext
+	}
+	return ext
+}
+
+// BuiltinInterpreters returns the built-in candidates for ext on this platform.
+func BuiltinInterpreters(ext string) []string {
+	ext = NormalizeExt(ext)
+	if runtime.GOOS == "windows" {
+		if c, ok := windowsInterpreters[ext]; ok {
+			return c
+		}
+	}
+	return builtinInterpreters[ext]
+}
+
+// InterpreterError reports that no candidate interpreter for a file was found.
+type InterpreterError struct {
+	File  string
+	Ext   string
+	Tried []string
+}
+
+func (e *InterpreterError) Error() string {
+	hint := ""
+	if e.Ext != "" {
+		hint = fmt.Sprintf("; configure one with `gixt config-interpreter --ext %s --cmd <command>`", e.Ext)
+	}
+	if len(e.Tried) == 0 {
+		return fmt.Sprintf("cannot determine how to run %s (unknown extension)%s", e.File, hint)
+	}
+	return fmt.Sprintf("cannot run %s: no interpreter found on PATH (tried %s)%s", e.File, strings.Join(e.Tried, ", "), hint)
+}
+
+// available reports whether argv0 can be executed: it is found on PATH, exists as a path,
+// or will be replaced by one of req's environment interpreters.
+func (req Request) available(argv0 string) bool {
+	if _, ok := req.Interpreters[strings.TrimSuffix(filepath.Base(argv0), ".exe")]; ok {
+		return true
+	}
+	if strings.ContainsAny(argv0, `/\`) {
+		info, err := os.Stat(argv0)
+		return err == nil && !info.IsDir()
+	}
+	_, err := lookPath(argv0)
+	return err == nil
+}
+
+// pickInterpreter returns the first available candidate for ext: the entries configured in
+// req.InterpreterCandidates, then the built-ins. configured reports where the pick came from;
+// tried lists every candidate checked.
+func (req Request) pickInterpreter(ext string) (argv []string, configured bool, tried []string, err error) {
+	own := req.InterpreterCandidates[ext]
+	candidates := append(append([]string(nil), own...), BuiltinInterpreters(ext)...)
+	for i, c := range candidates {
+		words, err := SplitShellWords(c)
+		if err != nil || len(words) == 0 {
+			return nil, false, tried, fmt.Errorf("interpreter %q for %s: not a valid command", c, ext)
+		}
+		tried = append(tried, c)
+		if req.available(words[0]) {
+			return words, i < len(own), tried, nil
+		}
+	}
+	return nil, false, tried, nil
+}
+
+// commandFromExtension picks the interpreter for path by its extension.
+func (req Request) commandFromExtension(path string) ([]string, string, error) {
+	ext := strings.ToLower(filepath.Ext(path))
+	if (ext == ".bat" || ext == ".cmd") && len(req.InterpreterCandidates[ext]) == 0 {
+		if runtime.GOOS == "windows" {
+			return []string{"cmd", "/C", path}, "extension " + ext, nil
+		}
+		return []string{path}, "extension " + ext, nil
+	}
+	argv, configured, tried, err := req.pickInterpreter(ext)
+	if err != nil {
+		return nil, "", err
+	}
+	if argv == nil {
+		return nil, "", &InterpreterError{File: filepath.Base(path), Ext: ext, Tried: tried}
+	}
+	reason := "extension " + ext
+	if configured {
+		reason += " (settings)"
+	}
+	return append(argv, path), reason, nil
+}
+
+// readShebang returns the interpreter argv from path's #! line, if it has one.
+func readShebang(path string) ([]string, bool) {
+	fh, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer fh.Close()
+	scanner := bufio.NewScanner(fh)
+	if !scanner.Scan() {
+		return nil, false
+	}
+	line := scanner.Text()
+	if !strings.HasPrefix(line, "#!") {
+		return nil, false
+	}
+	argv := parseShebang(line)
+	return argv, len(argv) > 0
+}
+
+// shebangCommand makes shebang runnable here. An absolute interpreter path that does not
+// exist, such as /usr/local/bin/python3 on another machine, is looked up on PATH by name.
+func (req Request) shebangCommand(shebang []string) ([]string, bool) {
+	argv := append([]string(nil), shebang...)
+	if req.available(argv[0]) {
+		return argv, true
+	}
+	if base := filepath.Base(argv[0]); base != argv[0] && req.available(base) {
+		argv[0] = base
+		return argv, true
+	}
+	return nil, false
+}
+
+// parseShebang splits a #! line into argv. "/usr/bin/env prog args" becomes "prog args" so
+// the program is looked up like any other command, and "env -S" strings are split like
+// the shell would. Lines that set variables through env are kept as written.
+func parseShebang(line string) []string {
+	body := strings.TrimSpace(strings.TrimPrefix(line, "#!"))
+	fields := strings.Fields(body)
+	if len(fields) == 0 {
+		return nil
+	}
+	if filepath.Base(fields[0]) != "env" || len(fields) == 1 {
+		return fields
+	}
+	rest := strings.TrimSpace(strings.TrimPrefix(body, fields[0]))
+	var words []string
+	switch {
+	case strings.HasPrefix(rest, "--split-string="):
+		words, _ = SplitShellWords(strings.TrimPrefix(rest, "--split-string="))
+	case strings.HasPrefix(rest, "--split-string"), strings.HasPrefix(rest, "-S"):
+		rest = strings.TrimPrefix(strings.TrimPrefix(rest, "--split-string"), "-S")
+		words, _ = SplitShellWords(rest)
+	case strings.HasPrefix(rest, "-"):
+		return fields // other env options: let env handle them
+	default:
+		words = fields[1:]
+	}
+	if len(words) == 0 || strings.Contains(words[0], "=") {
+		return fields
+	}
+	return words
+}
+
+// InterpreterExts lists the extensions with built-in interpreters, sorted.
+func InterpreterExts() []string {
+	exts := make([]string, 0, len(builtinInterpreters))
+	for ext := range builtinInterpreters {
+		exts = append(exts, ext)
+	}
+	sort.Strings(exts)
+	return exts
+}
+
+// LookupInterpreter reports the command that would run a file with ext given the configured
+// candidates, or "" when none is installed.
+func LookupInterpreter(ext string, candidates map[string][]string) (string, error) {
+	argv, _, _, err := Request{InterpreterCandidates: candidates}.pickInterpreter(NormalizeExt(ext))
+	if err != nil || argv == nil {
+		return "", err
+	}
+	return FormatArgv(argv), nil
+}
@@ -0,0 +1,104 @@
+package runner
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseShebang(t *testing.T) {
+	cases := map[string][]string{
+		"#!/usr/bin/env bash":                              {"bash"},
+		"#!/bin/sh -e":                                     {"/bin/sh", "-e"},
+		"#!/usr/bin/env -S deno run --allow-net":           {"deno", "run", "--allow-net"},
+		"#!/usr/bin/env -S uv run --script":                {"uv", "run", "--script"},
+		"#!/usr/bin/env --split-string=node --no-warnings": {"node", "--no-warnings"},
+		"#!/usr/bin/env FOO=1 python3":                     {"/usr/bin/env", "FOO=1", "python3"},
+		"#!/usr/bin/env -i python3":                        {"/usr/bin/env", "-i", "python3"},
+		"#!":                                               nil,
+	}
+	for line, want := range cases {
+		if got := parseShebang(line); !reflect.DeepEqual(got, want) {
+			t.Errorf("parseShebang(%q) = %q, want %q", line, got, want)
+		}
+	}
+}
+
+func TestExtensionTriesCandidatesInOrder(t *testing.T) {
+	installed := map[string]bool{}
+	orig := lookPath
+	lookPath = func(name string) (string, error) {
+		if installed[name] {
+			return "/opt/bin/" + name, nil
+		}
+		return "", errors.New("not found")
+	}
+	t.Cleanup(func() { lookPath = orig })
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "tool.lua"), []byte("print(1)"), 0o644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	req := Request{
+		Dir:                   dir,
+		Files:                 []string{"tool.lua"},
+		ExecDir:               dir,
+		InterpreterCandidates: map[string][]string{".lua": {"luajit -O3"}},
+	}
+
+	_, err := Resolve(req)
+	var missing *InterpreterError
+	if !errors.As(err, &missing) {
+		t.Fatalf("expected InterpreterError, got %v", err)
+	}
+	for _, want := range []string{"luajit -O3", "lua5.4", "config-interpreter --ext .lua"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("error %q does not mention %q", err, want)
+		}
+	}
+
+	installed["lua5.4"] = true
+	cmd, err := Resolve(req)
+	if err != nil {
+		t.Fatalf("Resolve error: %v", err)
+	}
+	if cmd.Argv[0] != "lua5.4" || cmd.Reason != "extension .lua" {
+		t.Fatalf("expected built-in lua5.4, got %q (%s)", cmd.Argv, cmd.Reason)
+	}
+
+	installed["luajit"] = true
+	cmd, err = Resolve(req)
+	if err != nil {
+		t.Fatalf("Resolve error: %v", err)
+	}
+	want := []string{"luajit", "-O3", filepath.Join(dir, "tool.lua")}
+	if !reflect.DeepEqual(cmd.Argv, want) || cmd.Reason != "extension .lua (settings)" {
+		t.Fatalf("expected configured luajit, got %q (%s)", cmd.Argv, cmd.Reason)
+	}
+}
+
+func TestMissingShebangInterpreterFallsBackToExtension(t *testing.T) {
+	orig := lookPath
+	lookPath = func(name string) (string, error) {
+		if name == "python3" {
+			return "/opt/bin/python3", nil
+		}
+		return "", errors.New("not found")
+	}
+	t.Cleanup(func() { lookPath = orig })
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "app.py"), []byte("#!/usr/bin/env python3.11\nprint(1)"), 0o644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	cmd, err := Resolve(Request{Dir: dir, Files: []string{"app.py"}, ExecDir: dir})
+	if err != nil {
+		t.Fatalf("Resolve error: %v", err)
+	}
+	if cmd.Argv[0] != "python3" || !strings.Contains(cmd.Reason, "shebang python3.11 not found") {
+		t.Fatalf("expected extension fallback, got %q (%s)", cmd.Argv, cmd.Reason)
+	}
+}
@@ -0,0 +1,242 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// RunSpec is a manifest "run" value: a command line for the shell, an argv array executed
+// without a shell, or a map from platform keys ("linux", "linux/arm64", "default") to
+// either of those.
+type RunSpec struct {
+	Line       string
+	Argv       []string // non-nil for the array form
+	ByPlatform map[string]RunSpec
+}
+
+// PlatformDefault is the run map key used when no GOOS or GOOS/GOARCH key matches.
+const PlatformDefault = "default"
+
+// ErrUnsupportedPlatform is returned when a manifest has nothing to run on this platform.
+var ErrUnsupportedPlatform = errors.New("unsupported platform")
+
+// Known GOOS and GOARCH values, so a typo such as "macos" fails validation instead of
+// silently never matching.
+var (
+	knownGOOS = map[string]bool{
+		"aix": true, "android": true, "darwin": true, "dragonfly": true, "freebsd": true,
+		"illumos": true, "ios": true, "js": true, "linux": true, "netbsd": true,
+		"openbsd": true, "plan9": true, "solaris": true, "wasip1": true, "windows": true,
+	}
+	knownGOARCH = map[string]bool{
+		"386": true, "amd64": true, "arm": true, "arm64": true, "loong64": true,
+		"mips": true, "mips64": true, "mips64le": true, "mipsle": true, "ppc64": true,
+		"ppc64le": true, "riscv64": true, "s390x": true, "wasm": true,
+	}
+)
+
+// IsZero reports whether no command is set.
+func (r RunSpec) IsZero() bool {
+	return strings.TrimSpace(r.Line) == "" && len(r.Argv) == 0 && len(r.ByPlatform) == 0
+}
+
+// For returns the command for goos/goarch and the key that matched: the exact
+// GOOS/GOARCH key, then GOOS, then "default". A command without a platform map matches
+// every platform with an empty key.
+func (r RunSpec) For(goos, goarch string) (RunSpec, string, bool) {
+	if r.ByPlatform == nil {
+		return r, "", true
+	}
+	for _, key := range []string{goos + "/" + goarch, goos, PlatformDefault} {
+		if run, ok := r.ByPlatform[key]; ok {
+			return run, key, true
+		}
+	}
+	return RunSpec{}, "", false
+}
+
+// Keys returns the run map's platform keys, sorted, or nil for a plain run line.
+func (r RunSpec) Keys() []string {
+	if r.ByPlatform == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(r.ByPlatform))
+	for k := range r.ByPlatform {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// text joins every command line and argv word, for checks that apply to all of them
+// (placeholders).
+func (r RunSpec) text() string {
+	switch {
+	case r.ByPlatform != nil:
+		parts := make([]string, 0, len(r.ByPlatform))
+		for _, k := range r.Keys() {
+			parts = append(parts, r.ByPlatform[k].text())
+		}
+		return strings.Join(parts, "\n")
+	case r.Argv != nil:
+		return strings.Join(r.Argv, "\n")
+	}
+	return r.Line
+}
+
+func (r *RunSpec) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '{' {
+		var raw map[string]json.RawMessage
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return err
+		}
+		byPlatform := make(map[string]RunSpec, len(raw))
+		for key, v := range raw {
+			leaf, err := unmarshalRunCommand(v)
+			if err != nil {
+				return fmt.Errorf("run.%s: %w", key, err)
+			}
+			byPlatform[key] = leaf
+		}
+		*r = RunSpec{ByPlatform: byPlatform}
+		return nil
+	}
+	leaf, err := unmarshalRunCommand(data)
+	if err != nil {
+		return fmt.Errorf("run must be a string, an array of strings, or an object keyed by platform: %w", err)
+	}
+	*r = leaf
+	return nil
+}
+
+// unmarshalRunCommand decodes a string or argv array (not a platform map).
+func unmarshalRunCommand(data []byte) (RunSpec, error) {
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.Equal(data, []byte("null")):
+		return RunSpec{}, nil
+	case len(data) > 0 && data[0] == '"':
+		var line string
+		if err := json.Unmarshal(data, &line); err != nil {
+			return RunSpec{}, err
+		}
+		return RunSpec{Line: line}, nil
+	case len(data) > 0 && data[0] == '[':
+		argv := []string{}
+		if err := json.Unmarshal(data, &argv); err != nil {
+			return RunSpec{}, fmt.Errorf("argv elements must be strings")
+		}
+		return RunSpec{Argv: argv}, nil
+	}
+	return RunSpec{}, fmt.Errorf("expected a command string or an argv array")
+}
+
+func (r RunSpec) MarshalJSON() ([]byte, error) {
+	switch {
+	case r.ByPlatform != nil:
+		return json.Marshal(r.ByPlatform)
+	case r.Argv != nil:
+		return json.Marshal(r.Argv)
+	}
+	return json.Marshal(r.Line)
+}
+
+// PlatformRun returns the command m runs on goos/goarch and the run map key that matched.
+// It fails with ErrUnsupportedPlatform when the platform is not in m.Platforms or the run
+// map has no matching key.
+func (m RunManifest) PlatformRun(goos, goarch string) (RunSpec, string, error) {
+	here := goos + "/" + goarch
+	if len(m.Platforms) > 0 {
+		supported := false
+		for _, p := range m.Platforms {
+			if p == goos || p == here {
+				supported = true
+				break
+			}
+		}
+		if !supported {
+			return RunSpec{}, "", fmt.Errorf("%w: this gist supports %s, not %s", ErrUnsupportedPlatform, strings.Join(m.Platforms, ", "), here)
+		}
+	}
+	run, key, ok := m.Run.For(goos, goarch)
+	if !ok {
+		return RunSpec{}, "", fmt.Errorf("%w: run has commands for %s, not %s", ErrUnsupportedPlatform, strings.Join(m.Run.Keys(), ", "), here)
+	}
+	return run, key, nil
+}
+
+// validateRunSpec checks a run value; where names the command it belongs to, if any.
+func validateRunSpec(r RunSpec, where string) error {
+	if r.ByPlatform == nil {
+		return validateRunCommand(r, where)
+	}
+	field := "run"
+	if where != "" {
+		field = where + " run"
+	}
+	if len(r.ByPlatform) == 0 {
+		return fmt.Errorf("run manifest %s map is empty", field)
+	}
+	for _, key := range r.Keys() {
+		if key != PlatformDefault {
+			if err := validatePlatformKey(key); err != nil {
+				return fmt.Errorf("run manifest %s: %w", field, err)
+			}
+		}
+		if err := validateRunCommand(r.ByPlatform[key], strings.TrimSpace(where+" "+key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateRunCommand checks a string or argv run.
+func validateRunCommand(r RunSpec, where string) error {
+	if r.Argv == nil {
+		return validateRun(r.Line, where)
+	}
+	field := "run array"
+	if where != "" {
+		field = where + " run array"
+	}
+	if len(r.Argv) == 0 || strings.TrimSpace(r.Argv[0]) == "" {
+		return fmt.Errorf("run manifest %s needs a program as its first element", field)
+	}
+	size := 0
+	for _, a := range r.Argv {
+		if strings.ContainsRune(a, 0) {
+			return fmt.Errorf("run manifest %s must not contain NUL bytes", field)
+		}
+		size += len(a)
+	}
+	if size > 4096 {
+		return fmt.Errorf("run manifest %s too long", field)
+	}
+	return nil
+}
+
+func validatePlatforms(platforms []string) error {
+	for _, p := range platforms {
+		if err := validatePlatformKey(p); err != nil {
+			return fmt.Errorf("run manifest platforms: %w", err)
+		}
+	}
+	return nil
+}
+
+// validatePlatformKey accepts GOOS or GOOS/GOARCH.
+func validatePlatformKey(key string) error {
+	goos, goarch, hasArch := strings.Cut(key, "/")
+	if !knownGOOS[goos] {
+		return fmt.Errorf("unknown platform %q (use a GOOS such as linux, darwin, windows, optionally with /GOARCH)", key)
+	}
+	if hasArch && !knownGOARCH[goarch] {
+		return fmt.Errorf("unknown architecture in %q (use a GOARCH such as amd64 or arm64)", key)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestPlatformRunPicksMostSpecificKey(t *testing.T) {
+	m, err := LoadRunManifestBytes([]byte(`{"run": {
+		"linux/arm64": "./tool-arm64",
+		"linux": "./tool-amd64",
+		"windows": "powershell -File tool.ps1",
+		"default": "sh tool.sh"
+	}}`))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	cases := []struct{ goos, goarch, line, key string }{
+		{"linux", "arm64", "./tool-arm64", "linux/arm64"},
+		{"linux", "amd64", "./tool-amd64", "linux"},
+		{"windows", "amd64", "powershell -File tool.ps1", "windows"},
+		{"darwin", "arm64", "sh tool.sh", "default"},
+	}
+	for _, c := range cases {
+		run, key, err := m.PlatformRun(c.goos, c.goarch)
+		if err != nil || run.Line != c.line || key != c.key {
+			t.Errorf("%s/%s: got %q via %q (%v), want %q via %q", c.goos, c.goarch, run.Line, key, err, c.line, c.key)
+		}
+	}
+
+	delete(m.Run.ByPlatform, "default")
+	if _, _, err := m.PlatformRun("darwin", "arm64"); !errors.Is(err, ErrUnsupportedPlatform) {
+		t.Fatalf("expected ErrUnsupportedPlatform without a default, got %v", err)
+	}
+
+	plain := RunManifest{Run: RunSpec{Line: "sh tool.sh"}, Platforms: []string{"linux", "darwin/arm64"}}
+	if _, _, err := plain.PlatformRun("darwin", "arm64"); err != nil {
+		t.Fatalf("darwin/arm64 is listed: %v", err)
+	}
+	_, _, err = plain.PlatformRun("windows", "amd64")
+	if !errors.Is(err, ErrUnsupportedPlatform) || !strings.Contains(err.Error(), "supports linux, darwin/arm64, not windows/amd64") {
+		t.Fatalf("expected platforms refusal, got %v", err)
+	}
+}
+
+func TestRunSpecJSONRoundTripAndValidation(t *testing.T) {
+	cases := map[string]string{
+		`"python app.py"`: `"python app.py"`,
+		`{"linux": "./tool", "default": "sh tool.sh"}`: `{"default":"sh tool.sh","linux":"./tool"}`,
+	}
+	for in, want := range cases {
+		var r RunSpec
+		if err := json.Unmarshal([]byte(in), &r); err != nil {
+			t.Fatalf("unmarshal %s: %v", in, err)
+		}
+		out, err := json.Marshal(r)
+		if err != nil || string(out) != want {
+			t.Fatalf("round trip %s = %s (%v), want %s", in, out, err, want)
+		}
+	}
+
+	bad := []string{
+		`{"run": 42}`,
+		`{"run": {}}`,
+		`{"run": {"macos": "./tool"}}`,
+		`{"run": {"linux/x86": "./tool"}}`,
+		`{"run": {"linux": ""}}`,
+		`{"run": "sh tool.sh", "platforms": ["linux", "win"]}`,
+	}
+	for _, data := range bad {
+		if _, err := LoadRunManifestBytes([]byte(data)); err == nil {
+			t.Errorf("expected error for %s", data)
+		}
+	}
+}
@@ -1,24 +1,60 @@
 package runner
 
 import (
-	"bufio"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"runtime"
 	"strings"
+
+	"github.com/leolaurindo/gixt/internal/envvar"
+	"github.com/leolaurindo/gixt/internal/rlimit"
 )
 
 type RunManifest struct {
-	Run     string            `json:"run"`
-	Env     map[string]string `json:"env"`
-	Details string            `json:"details,omitempty"`
-	Version string            `json:"version,omitempty"`
+	Run         RunSpec           `json:"run"`                 // a command line, or one per platform
+	Entry       string            `json:"entry,omitempty"`     // gist file run by shebang or extension instead of run
+	Platforms   []string          `json:"platforms,omitempty"` // GOOS or GOOS/GOARCH values the gist supports; empty means any
+	Setup       *RunSpec          `json:"setup,omitempty"`     // runs once per cached revision before the first run
+	Env         map[string]string `json:"env"`
+	RequiredEnv []string          `json:"required_env,omitempty"` // variables the gist needs; missing ones are asked for
+	Secrets     []string          `json:"secrets,omitempty"`      // names from the secret store the gist may receive
+	Permissions *Permissions      `json:"permissions,omitempty"`  // what the gist needs inside the sandbox
+	Limits      *rlimit.Spec      `json:"limits,omitempty"`       // resource limits for the gist process
+	Details     string            `json:"details,omitempty"`
+	Version     string            `json:"version,omitempty"`
+	Args        *ArgSpec          `json:"args,omitempty"`
+
+	Commands map[string]CommandSpec `json:"commands,omitempty"` // named entrypoints: gixt <gist>:<name>
+	Default  string                 `json:"default,omitempty"`  // command used when none is named
 }
 
 const DefaultDetails = "No description provided"
 
+// Permissions declares what a gist needs when it runs in the sandbox. Everything not
+// declared is denied there.
+type Permissions struct {
+	Network  bool `json:"network,omitempty"`   // reach the network
+	WriteCWD bool `json:"write_cwd,omitempty"` // write to the directory gixt was started from
+}
+
+// List names the granted permissions, or returns nil when none are.
+func (p *Permissions) List() []string {
+	if p == nil {
+		return nil
+	}
+	var out []string
+	if p.Network {
+		out = append(out, "network")
+	}
+	if p.WriteCWD {
+		out = append(out, "write_cwd")
+	}
+	return out
+}
+
 func LoadRunManifest(path string) (RunManifest, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -40,66 +76,379 @@ func LoadRunManifestBytes(data []byte) (RunManifest, error) {
 	return normalizeRunManifest(m), nil
 }
 
-func BuildCommand(dir string, manifestPath string, files []string, userArgs []string, execDir string) ([]string, map[string]string, string, error) {
-	if manifestPath != "" {
-		full := filepath.Join(dir, manifestPath)
+// Request describes what to run: the gist files in Dir, an optional manifest, the args
+// forwarded by the user, and where the command will execute.
+type Request struct {
+	Dir          string   // work dir holding the gist files
+	ManifestPath string   // manifest file name relative to Dir; empty to skip manifests
+	Files        []string // gist file names
+	Args         []string // args forwarded to the gist
+	ExecDir      string   // where the command runs; relative paths in run are rebased when it differs from Dir
+	CallerDir    string   // the user's shell directory; relative file args are resolved against it when set
+	Command      string   // manifest command named explicitly (gixt <gist>:<cmd>)
+	File         string   // gist file named explicitly (gixt <gist>:<file> or --file); the manifest run is skipped
+
+	// Interpreters replaces program names in resolved commands, e.g. "python" with a
+	// virtualenv's interpreter. EnvVars is added to the command environment beneath the
+	// manifest env.
+	Interpreters map[string]string
+	EnvVars      map[string]string
+
+	// EnvOverrides (--env and --env-file) is applied over the manifest env. LookupEnv reads
+	// the caller's environment for ${VAR} references; nil means os.LookupEnv.
+	EnvOverrides map[string]string
+	LookupEnv    envvar.Lookup
+
+	// Secrets holds values from the secret store, added over the manifest env and beneath
+	// EnvOverrides. Only the names the manifest declares should be passed.
+	Secrets map[string]string
+
+	// InterpreterCandidates maps an extension such as ".py" to commands tried, in order,
+	// before the built-in interpreters for that extension; the first one found on PATH runs
+	// the file.
+	InterpreterCandidates map[string][]string
+
+	// BuildDir holds compiled binaries for Go, Rust and C/C++ gists. When empty, .go files
+	// use go run and the other compiled languages are not supported.
+	BuildDir string
+}
+
+// Command is a resolved invocation.
+type Command struct {
+	Argv   []string
+	Env    map[string]string
+	Reason string // which rule produced the command (manifest, shebang, extension .py, ...)
+	Build  *Build // compile step to run first when Build.Binary does not exist yet
+
+	// Entry is the gist file run by shebang or extension, and EntryRule why it was chosen
+	// (manifest entry, main.* file, ...). Both are empty for manifest run commands.
+	Entry     string
+	EntryRule string
+
+	RequiredEnv []string // variables the manifest requires
+	Secrets     []string // secret names the manifest declares
+}
+
+// Resolve picks the command for req: the manifest when one exists, otherwise the shebang or
+// extension of the selected file. Forwarded args are checked against manifest args when the
+// manifest declares them.
+func Resolve(req Request) (Command, error) {
+	cmd, err := resolve(req)
+	if err != nil {
+		return Command{}, err
+	}
+	cmd.Argv = substituteInterpreter(cmd.Argv, req.Interpreters)
+	if len(req.EnvVars) > 0 {
+		env := map[string]string{}
+		for k, v := range req.EnvVars {
+			env[k] = v
+		}
+		for k, v := range cmd.Env {
+			env[k] = v
+		}
+		cmd.Env = env
+	}
+	return cmd, nil
+}
+
+func resolve(req Request) (Command, error) {
+	const named = "named on the command line"
+	if req.ManifestPath != "" {
+		full := filepath.Join(req.Dir, req.ManifestPath)
 		if _, err := os.Stat(full); err == nil {
 			m, err := LoadRunManifest(full)
 			if err != nil {
-				return nil, nil, "", err
+				return Command{}, err
+			}
+			if req.File != "" {
+				// An explicit file skips run and commands but keeps the gist's env.
+				env, _, err := req.environment(m.Env)
+				if err != nil {
+					return Command{}, err
+				}
+				cmd, err := fileCommand(req, req.File, named, env, nil)
+				cmd.RequiredEnv, cmd.Secrets = m.RequiredEnv, m.Secrets
+				return cmd, err
+			}
+			sel, name, args, err := m.Select(req.Command, req.Args)
+			if err != nil {
+				return Command{}, err
+			}
+			if sel.Run.IsZero() && sel.Entry == "" {
+				return Command{}, fmt.Errorf("run manifest %s has empty run field", req.ManifestPath)
+			}
+			run, key, err := sel.PlatformRun(runtime.GOOS, runtime.GOARCH)
+			if err != nil {
+				return Command{}, err
+			}
+			req.Args = args
+			env, lookup, err := req.environment(sel.Env)
+			if err != nil {
+				return Command{}, err
+			}
+			sel.Env = env
+			if sel.Entry != "" {
+				rule := "manifest entry"
+				if name != "" {
+					rule = "manifest command " + name + " entry"
+				}
+				cmd, err := fileCommand(req, sel.Entry, rule, sel.Env, sel.Args)
+				cmd.RequiredEnv, cmd.Secrets = sel.RequiredEnv, sel.Secrets
+				return cmd, err
+			}
+			cmd, err := manifestCommand(req, sel, run, lookup)
+			cmd.RequiredEnv, cmd.Secrets = sel.RequiredEnv, sel.Secrets
+			if name != "" {
+				cmd.Reason = "manifest command " + name
+			}
+			var notes []string
+			if key != "" {
+				notes = append(notes, "run."+key)
 			}
-			if strings.TrimSpace(m.Run) == "" {
-				return nil, nil, "", fmt.Errorf("run manifest %s has empty run field", manifestPath)
+			if run.Argv != nil {
+				notes = append(notes, "argv, no shell")
 			}
-			runCmd := m.Run
-			if execDir != "" && execDir != dir {
-				runCmd = rebaseRunToDir(runCmd, dir)
+			if len(notes) > 0 {
+				cmd.Reason += " (" + strings.Join(notes, ", ") + ")"
 			}
-			shellCmd := shellCommand(runCmd)
-			return append(shellCmd, userArgs...), m.Env, "manifest", nil
+			return cmd, err
 		}
 	}
+	env, _, err := req.environment(nil)
+	if err != nil {
+		return Command{}, err
+	}
+	return fileCommand(req, req.File, named, env, nil)
+}
 
-	if len(files) == 0 {
-		return nil, nil, "", fmt.Errorf("no files in gist to run")
+// fileCommand runs one gist file by shebang, build, or extension. entry is the file to run,
+// or "" to pick one with selectFile; env and declared come from a manifest entry, if any.
+func fileCommand(req Request, entry, rule string, env map[string]string, declared *ArgSpec) (Command, error) {
+	if len(req.Files) == 0 {
+		return Command{}, fmt.Errorf("no files in gist to run")
+	}
+	if entry == "" {
+		entry, rule = selectFile(req.Files)
+	} else if !hasFile(req.Files, entry) {
+		return Command{}, fmt.Errorf("%s %q is not a file in the gist (files: %s)", rule, entry, strings.Join(req.Files, ", "))
+	}
+
+	args := req.Args
+	if declared != nil {
+		parsed, err := ParseArgs(*declared, req.Args, req.CallerDir)
+		if err != nil {
+			return Command{}, err
+		}
+		merged := map[string]string{}
+		for k, v := range env {
+			merged[k] = v
+		}
+		for k, v := range parsed.Env() {
+			merged[k] = v
+		}
+		env, args = merged, parsed.Argv
 	}
+	userArgs := rebaseUserArgs(args, req.CallerDir)
+	chosenPath := filepath.Join(req.Dir, entry)
+	cmd := Command{Env: env, Entry: entry, EntryRule: rule}
 
-	chosen := selectFile(files)
-	chosenPath := filepath.Join(dir, chosen)
+	shebang, hasShebang := readShebang(chosenPath)
+	if hasShebang {
+		if argv, ok := req.shebangCommand(shebang); ok {
+			cmd.Argv, cmd.Reason = append(append(argv, chosenPath), userArgs...), "shebang"
+			return cmd, nil
+		}
+	}
+
+	if req.BuildDir != "" {
+		b, ok, err := planBuild(req.Dir, req.Files, entry, req.BuildDir)
+		if err != nil {
+			return Command{}, err
+		}
+		if ok {
+			cmd.Argv, cmd.Reason, cmd.Build = append([]string{b.Binary}, userArgs...), "build "+b.Lang, &b
+			return cmd, nil
+		}
+	}
+
+	argv, reason, err := req.commandFromExtension(chosenPath)
+	var missing *InterpreterError
+	if hasShebang && errors.As(err, &missing) {
+		// Neither the shebang interpreter nor a fallback for the extension is installed.
+		missing.Tried = append([]string{shebang[0]}, missing.Tried...)
+	}
+	if err != nil {
+		return Command{}, err
+	}
+	if hasShebang {
+		reason += fmt.Sprintf(" (shebang %s not found)", shebang[0])
+	}
+	cmd.Argv, cmd.Reason = append(argv, userArgs...), reason
+	return cmd, nil
+}
 
-	if cmd, reason, ok := commandFromShebang(chosenPath); ok {
-		return append(cmd, userArgs...), nil, reason, nil
+func hasFile(files []string, name string) bool {
+	for _, f := range files {
+		if f == name {
+			return true
+		}
 	}
+	return false
+}
 
-	cmd, reason, err := commandFromExtension(chosenPath)
+// substituteInterpreter swaps the program of argv, or the program after /usr/bin/env, for
+// its replacement in interpreters.
+func substituteInterpreter(argv []string, interpreters map[string]string) []string {
+	if len(argv) == 0 || len(interpreters) == 0 {
+		return argv
+	}
+	i := 0
+	if filepath.Base(argv[0]) == "env" && len(argv) > 1 {
+		i = 1
+	}
+	name := strings.TrimSuffix(filepath.Base(argv[i]), ".exe")
+	if repl, ok := interpreters[name]; ok {
+		argv = append([]string(nil), argv...)
+		argv[i] = repl
+	}
+	return argv
+}
+
+// manifestCommand builds the command for run, a string or argv entry picked from m.
+func manifestCommand(req Request, m RunManifest, run RunSpec, lookup envvar.Lookup) (Command, error) {
+	// ${VAR} is expanded before {{placeholders}} so values from the user's args are never
+	// expanded again. Unknown names are left for the shell.
+	runCmd, err := envvar.Expand(run.Line, lookup, true)
+	if err != nil {
+		return Command{}, fmt.Errorf("run: %w", err)
+	}
+	var argv []string
+	if run.Argv != nil {
+		argv = make([]string, len(run.Argv))
+		for i, a := range run.Argv {
+			if argv[i], err = envvar.Expand(a, lookup, true); err != nil {
+				return Command{}, fmt.Errorf("run: %w", err)
+			}
+		}
+	}
+	env := m.Env
+	userArgs := req.Args
+	if m.Args != nil {
+		parsed, err := ParseArgs(*m.Args, req.Args, req.CallerDir)
+		if err != nil {
+			return Command{}, err
+		}
+		env = map[string]string{}
+		for k, v := range m.Env {
+			env[k] = v
+		}
+		for k, v := range parsed.Env() {
+			env[k] = v
+		}
+		userArgs = parsed.Argv
+		if HasPlaceholders(run.text()) {
+			// Values reach the command through the placeholders instead.
+			runCmd = ExpandPlaceholders(runCmd, parsed)
+			argv = ExpandArgv(argv, parsed)
+			userArgs = nil
+		}
+	}
+	rebase := req.ExecDir != "" && req.ExecDir != req.Dir
+	if argv != nil {
+		if rebase {
+			argv = rebaseArgvToDir(argv, req.Dir)
+		}
+		full := append(append([]string(nil), argv...), rebaseUserArgs(userArgs, req.CallerDir)...)
+		return Command{Argv: full, Env: env, Reason: "manifest"}, nil
+	}
+	if rebase {
+		runCmd = rebaseRunToDir(runCmd, req.Dir)
+	}
+	shellCmd := shellCommand(runCmd)
+	return Command{Argv: append(shellCmd, rebaseUserArgs(userArgs, req.CallerDir)...), Env: env, Reason: "manifest"}, nil
+}
+
+// SetupCommand returns the manifest's setup step for this platform, run from req.Dir with
+// the manifest env and req's overrides. It reports false when there is no setup step, or
+// none for this platform.
+func SetupCommand(req Request) (Command, bool, error) {
+	if req.ManifestPath == "" {
+		return Command{}, false, nil
+	}
+	full := filepath.Join(req.Dir, req.ManifestPath)
+	if _, err := os.Stat(full); err != nil {
+		return Command{}, false, nil
+	}
+	m, err := LoadRunManifest(full)
+	if err != nil {
+		return Command{}, false, err
+	}
+	if m.Setup == nil {
+		return Command{}, false, nil
+	}
+	run, key, ok := m.Setup.For(runtime.GOOS, runtime.GOARCH)
+	if !ok {
+		return Command{}, false, nil
+	}
+	reason := "setup"
+	if key != "" {
+		reason += " (setup." + key + ")"
+	}
+	env, lookup, err := req.environment(m.Env)
+	if err != nil {
+		return Command{}, false, err
+	}
+	if run.Argv != nil {
+		argv := make([]string, len(run.Argv))
+		for i, a := range run.Argv {
+			if argv[i], err = envvar.Expand(a, lookup, true); err != nil {
+				return Command{}, false, fmt.Errorf("setup: %w", err)
+			}
+		}
+		return Command{Argv: argv, Env: env, Reason: reason}, true, nil
+	}
+	line, err := envvar.Expand(run.Line, lookup, true)
+	if err != nil {
+		return Command{}, false, fmt.Errorf("setup: %w", err)
+	}
+	return Command{Argv: shellCommand(line), Env: env, Reason: reason}, true, nil
+}
+
+// BuildCommand resolves a command for already-resolved user args; see Resolve.
+func BuildCommand(dir string, manifestPath string, files []string, userArgs []string, execDir string) ([]string, map[string]string, string, error) {
+	cmd, err := Resolve(Request{Dir: dir, ManifestPath: manifestPath, Files: files, Args: userArgs, ExecDir: execDir})
 	if err != nil {
 		return nil, nil, "", err
 	}
-	return append(cmd, userArgs...), nil, reason, nil
+	return cmd.Argv, cmd.Env, cmd.Reason, nil
 }
 
-func selectFile(files []string) string {
+// selectFile picks the file to run when none is named, and describes the rule that chose it.
+func selectFile(files []string) (string, string) {
 	if len(files) == 0 {
-		return ""
+		return "", ""
+	}
+	if len(files) == 1 {
+		return files[0], "only file"
 	}
 	mainCandidates := filterByPrefix(files, "main.")
 	if chosen := choosePlatformSpecific(mainCandidates); chosen != "" {
-		return chosen
+		return chosen, "main.* file for this platform"
 	}
 	if len(mainCandidates) > 0 {
-		return mainCandidates[0]
+		return mainCandidates[0], "main.* file"
 	}
 	indexCandidates := filterByPrefix(files, "index.")
 	if chosen := choosePlatformSpecific(indexCandidates); chosen != "" {
-		return chosen
+		return chosen, "index.* file for this platform"
 	}
 	if len(indexCandidates) > 0 {
-		return indexCandidates[0]
+		return indexCandidates[0], "index.* file"
 	}
 	if chosen := choosePlatformSpecific(files); chosen != "" {
-		return chosen
+		return chosen, "shell script for this platform"
 	}
-	return files[0]
+	return files[0], "first file; set entry in the manifest or use <gist>:<file> to pick another"
 }
 
 func filterByPrefix(files []string, prefix string) []string {
@@ -182,58 +531,6 @@ func platformPreferredExts() map[string]bool {
 	}
 }
 
-func commandFromShebang(path string) ([]string, string, bool) {
-	fh, err := os.Open(path)
-	if err != nil {
-		return nil, "", false
-	}
-	defer fh.Close()
-	scanner := bufio.NewScanner(fh)
-	if !scanner.Scan() {
-		return nil, "", false
-	}
-	line := scanner.Text()
-	if !strings.HasPrefix(line, "#!") {
-		return nil, "", false
-	}
-	trimmed := strings.TrimSpace(strings.TrimPrefix(line, "#!"))
-	parts := strings.Fields(trimmed)
-	if len(parts) == 0 {
-		return nil, "", false
-	}
-	return append(parts, path), "shebang", true
-}
-
-func commandFromExtension(path string) ([]string, string, error) {
-	ext := strings.ToLower(filepath.Ext(path))
-	switch ext {
-	case ".sh":
-		return []string{"sh", path}, "extension .sh", nil
-	case ".ps1":
-		return []string{"powershell", "-ExecutionPolicy", "Bypass", "-File", path}, "extension .ps1", nil
-	case ".bat", ".cmd":
-		if runtime.GOOS == "windows" {
-			return []string{"cmd", "/C", path}, "extension .bat", nil
-		}
-		return []string{path}, "extension .bat", nil
-	case ".py":
-		return []string{"python", path}, "extension .py", nil
-	case ".js":
-		return []string{"node", path}, "extension .js", nil
-	case ".ts":
-		return []string{"npx", "ts-node", path}, "extension .ts", nil
-	case ".go":
-		return []string{"go", "run", path}, "extension .go", nil
-	case ".rb":
-		return []string{"ruby", path}, "extension .rb", nil
-	case ".pl":
-		return []string{"perl", path}, "extension .pl", nil
-	case ".php":
-		return []string{"php", path}, "extension .php", nil
-	}
-	return nil, "", fmt.Errorf("cannot determine how to run %s (unknown extension)", filepath.Base(path))
-}
-
 func shellCommand(cmd string) []string {
 	if runtime.GOOS == "windows" {
 		return []string{"cmd", "/C", cmd}
@@ -241,42 +538,136 @@ func shellCommand(cmd string) []string {
 	return []string{"sh", "-c", cmd}
 }
 
+// rebaseRunToDir rewrites the first word of run that names a file in dir to its absolute
+// path. Only that word is re-quoted; the rest of the line is kept as written. Lines the
+// shell-word parser rejects are returned unchanged.
 func rebaseRunToDir(run string, dir string) string {
-	if strings.TrimSpace(run) == "" {
-		return run
-	}
-	parts := strings.Fields(run)
-	if len(parts) == 0 {
+	words, err := shellWords(run)
+	if err != nil {
 		return run
 	}
-	for i, p := range parts {
-		if strings.HasPrefix(p, "-") {
+	for _, w := range words {
+		if w.Operator {
 			continue
 		}
-		if filepath.IsAbs(p) {
-			continue
+		if candidate, ok := gistFileIn(dir, w.Value); ok {
+			return run[:w.Start] + shellQuote(candidate) + run[w.End:]
 		}
-		candidate := filepath.Join(dir, p)
-		if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
-			parts[i] = candidate
+	}
+	return run
+}
+
+// rebaseArgvToDir is rebaseRunToDir for argv-form runs.
+func rebaseArgvToDir(argv []string, dir string) []string {
+	out := append([]string(nil), argv...)
+	for i, a := range out {
+		if candidate, ok := gistFileIn(dir, a); ok {
+			out[i] = candidate
 			break
 		}
 	}
-	return strings.Join(parts, " ")
+	return out
+}
+
+// gistFileIn returns dir/word when word is a relative, non-flag path to a file in dir.
+func gistFileIn(dir, word string) (string, bool) {
+	if word == "" || strings.HasPrefix(word, "-") || filepath.IsAbs(word) {
+		return "", false
+	}
+	candidate := filepath.Join(dir, word)
+	if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+		return candidate, true
+	}
+	return "", false
 }
 
 func validateRunManifest(m RunManifest) error {
-	run := strings.TrimSpace(m.Run)
+	if m.Entry != "" {
+		if !m.Run.IsZero() {
+			return fmt.Errorf("run manifest sets both run and entry; use one")
+		}
+		if err := validateEntry(m.Entry, ""); err != nil {
+			return err
+		}
+	} else if len(m.Commands) == 0 || !m.Run.IsZero() {
+		if err := validateRunSpec(m.Run, ""); err != nil {
+			return err
+		}
+	}
+	if err := validatePlatforms(m.Platforms); err != nil {
+		return err
+	}
+	if m.Setup != nil {
+		if err := validateRunSpec(*m.Setup, "setup"); err != nil {
+			return err
+		}
+		if HasPlaceholders(m.Setup.text()) {
+			return fmt.Errorf("run manifest setup cannot use {{...}} placeholders")
+		}
+	}
+	if err := validateEnvKeys(m.Env); err != nil {
+		return err
+	}
+	if err := validateEnvNames("required_env", m.RequiredEnv); err != nil {
+		return err
+	}
+	if err := validateEnvNames("secrets", m.Secrets); err != nil {
+		return err
+	}
+	if m.Limits != nil {
+		if _, err := m.Limits.Parse(); err != nil {
+			return fmt.Errorf("run manifest limits: %w", err)
+		}
+	}
+	if len(strings.TrimSpace(m.Details)) > 4096 {
+		return fmt.Errorf("run manifest details too long")
+	}
+	if len(strings.TrimSpace(m.Version)) > 256 {
+		return fmt.Errorf("run manifest version too long")
+	}
+	if m.Args != nil {
+		if err := validateArgSpec(*m.Args, m.Run.text()); err != nil {
+			return err
+		}
+	} else if HasPlaceholders(m.Run.text()) {
+		return fmt.Errorf("run manifest run uses {{...}} placeholders but declares no args")
+	}
+	return validateCommands(m)
+}
+
+// validateEntry checks an entry file name; where names the command it belongs to, if any.
+func validateEntry(entry string, where string) error {
+	field := "entry"
+	if where != "" {
+		field = where + " entry"
+	}
+	if entry != strings.TrimSpace(entry) || strings.ContainsAny(entry, `/\`) || entry == "." || entry == ".." {
+		return fmt.Errorf("run manifest %s %q must be the name of a file in the gist", field, entry)
+	}
+	return nil
+}
+
+// validateRun checks a run string; where names the command it belongs to, if any.
+func validateRun(run string, where string) error {
+	field := "run field"
+	if where != "" {
+		field = where + " run field"
+	}
+	run = strings.TrimSpace(run)
 	if run == "" {
-		return fmt.Errorf("run manifest has empty run field")
+		return fmt.Errorf("run manifest has empty %s", field)
 	}
 	if strings.ContainsAny(run, "\r\n") {
-		return fmt.Errorf("run manifest run field must not contain newlines")
+		return fmt.Errorf("run manifest %s must not contain newlines", field)
 	}
 	if len(run) > 4096 {
-		return fmt.Errorf("run manifest run field too long")
+		return fmt.Errorf("run manifest %s too long", field)
 	}
-	for k := range m.Env {
+	return nil
+}
+
+func validateEnvKeys(env map[string]string) error {
+	for k := range env {
 		if strings.TrimSpace(k) == "" {
 			return fmt.Errorf("run manifest env contains empty key")
 		}
@@ -287,11 +678,15 @@ func validateRunManifest(m RunManifest) error {
 			return fmt.Errorf("run manifest env key contains newline: %s", k)
 		}
 	}
-	if len(strings.TrimSpace(m.Details)) > 4096 {
-		return fmt.Errorf("run manifest details too long")
-	}
-	if len(strings.TrimSpace(m.Version)) > 256 {
-		return fmt.Errorf("run manifest version too long")
+	return nil
+}
+
+// validateEnvNames checks that the names listed in field can be environment variables.
+func validateEnvNames(field string, names []string) error {
+	for _, name := range names {
+		if !envvar.ValidName(name) {
+			return fmt.Errorf("run manifest %s name %q is not a valid variable name", field, name)
+		}
 	}
 	return nil
 }
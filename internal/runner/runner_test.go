@@ -57,7 +57,7 @@ func TestLoadRunManifestRejectsUnknownField(t *testing.T) {
 
 func TestSelectFilePrefersPlatformVariant(t *testing.T) {
 	files := []string{"test.sh", "test.bat"}
-	chosen := selectFile(files)
+	chosen, _ := selectFile(files)
 	if runtime.GOOS == "windows" {
 		if filepath.Base(chosen) != "test.bat" {
 			t.Fatalf("expected windows to prefer .bat, got %s", chosen)
@@ -68,3 +68,76 @@ func TestSelectFilePrefersPlatformVariant(t *testing.T) {
 		}
 	}
 }
+
+func TestManifestEntryPicksFile(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{"app.sh", "gixt.json", "helpers.sh"}
+	for _, f := range files[:2] {
+		if err := os.WriteFile(filepath.Join(dir, f), []byte("echo hi\n"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", f, err)
+		}
+	}
+	manifest := `{"entry":"helpers.sh","env":{"MODE":"x"},"commands":{"build":{"entry":"app.sh"}}}`
+	if err := os.WriteFile(filepath.Join(dir, "gixt.json"), []byte(manifest), 0o644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "helpers.sh"), []byte("echo helper\n"), 0o644); err != nil {
+		t.Fatalf("write helpers.sh: %v", err)
+	}
+	req := Request{Dir: dir, ManifestPath: "gixt.json", Files: files, ExecDir: dir}
+
+	cmd, err := Resolve(req)
+	if err != nil {
+		t.Fatalf("Resolve error: %v", err)
+	}
+	if cmd.Entry != "helpers.sh" || cmd.EntryRule != "manifest entry" || cmd.Env["MODE"] != "x" {
+		t.Fatalf("expected manifest entry with env, got %+v", cmd)
+	}
+
+	req.Command = "build"
+	if cmd, err = Resolve(req); err != nil || cmd.Entry != "app.sh" {
+		t.Fatalf("expected command entry app.sh, got %+v (%v)", cmd, err)
+	}
+
+	req.Command, req.File, req.ManifestPath = "", "app.sh", ""
+	if cmd, err = Resolve(req); err != nil || cmd.EntryRule != "named on the command line" {
+		t.Fatalf("expected named file, got %+v (%v)", cmd, err)
+	}
+
+	req.File = ""
+	if cmd, err = Resolve(req); err != nil || cmd.Entry != "app.sh" || cmd.EntryRule == "" {
+		t.Fatalf("expected a default file and its rule, got %+v (%v)", cmd, err)
+	}
+
+	for _, bad := range []string{`{"entry":"a.sh","run":"sh a.sh"}`, `{"entry":"../a.sh"}`} {
+		if _, err := LoadRunManifestBytes([]byte(bad)); err == nil {
+			t.Fatalf("expected %s to be rejected", bad)
+		}
+	}
+}
+
+func TestLoadRunManifestReadsPermissions(t *testing.T) {
+	m, err := LoadRunManifestBytes([]byte(`{"run": "echo hi", "permissions": {"write_cwd": true}}`))
+	if err != nil {
+		t.Fatalf("load manifest: %v", err)
+	}
+	if got := m.Permissions.List(); len(got) != 1 || got[0] != "write_cwd" {
+		t.Fatalf("permissions = %v", got)
+	}
+	if _, err := LoadRunManifestBytes([]byte(`{"run": "echo hi", "permissions": {"root": true}}`)); err == nil {
+		t.Fatalf("expected error for unknown permission")
+	}
+}
+
+func TestLoadRunManifestValidatesLimits(t *testing.T) {
+	m, err := LoadRunManifestBytes([]byte(`{"run": "echo hi", "limits": {"memory": "256M", "processes": 32}}`))
+	if err != nil {
+		t.Fatalf("load manifest: %v", err)
+	}
+	if m.Limits == nil || m.Limits.Memory != "256M" || m.Limits.Processes != 32 {
+		t.Fatalf("limits = %+v", m.Limits)
+	}
+	if _, err := LoadRunManifestBytes([]byte(`{"run": "echo hi", "limits": {"cpu": "soon"}}`)); err == nil {
+		t.Fatalf("expected error for a bad cpu limit")
+	}
+}
@@ -0,0 +1,118 @@
+package runner
+
+import (
+	"fmt"
+	"strings"
+)
+
+// shellWord is one word of a POSIX shell command line. Start and End are byte offsets of
+// the word as written (quotes included), so a word can be replaced without re-quoting the
+// rest of the line.
+type shellWord struct {
+	Value    string
+	Start    int
+	End      int
+	Operator bool // an unquoted control or redirection operator such as |, &&, ;, >
+}
+
+// SplitShellWords splits s into words the way a POSIX shell would before expansion:
+// single quotes are literal, double quotes honour \ before $ ` " \ and newline, and a
+// backslash outside quotes escapes the next character. Variables and globs are left as
+// written. Operators (| & ; < > ( )) are returned as separate words.
+func SplitShellWords(s string) ([]string, error) {
+	words, err := shellWords(s)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, 0, len(words))
+	for _, w := range words {
+		out = append(out, w.Value)
+	}
+	return out, nil
+}
+
+func shellWords(s string) ([]shellWord, error) {
+	var words []shellWord
+	var cur strings.Builder
+	start := -1
+	flush := func(end int) {
+		if start >= 0 {
+			words = append(words, shellWord{Value: cur.String(), Start: start, End: end})
+			cur.Reset()
+			start = -1
+		}
+	}
+	begin := func(i int) {
+		if start < 0 {
+			start = i
+		}
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			flush(i)
+		case c == '#' && start < 0:
+			// A comment runs to the end of the line.
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case strings.IndexByte("|&;<>()", c) >= 0:
+			flush(i)
+			end := i + 1
+			for end < len(s) && end-i < 2 && strings.IndexByte("|&;<>", s[end]) >= 0 && s[end] == c {
+				end++
+			}
+			words = append(words, shellWord{Value: s[i:end], Start: i, End: end, Operator: true})
+			i = end - 1
+		case c == '\\':
+			begin(i)
+			if i+1 < len(s) {
+				i++
+				if s[i] != '\n' {
+					cur.WriteByte(s[i])
+				}
+			}
+		case c == '\'':
+			begin(i)
+			j := strings.IndexByte(s[i+1:], '\'')
+			if j < 0 {
+				return nil, fmt.Errorf("unterminated single quote at offset %d", i)
+			}
+			cur.WriteString(s[i+1 : i+1+j])
+			i += j + 1
+		case c == '"':
+			begin(i)
+			j := i + 1
+			for ; j < len(s) && s[j] != '"'; j++ {
+				if s[j] == '\\' && j+1 < len(s) && strings.IndexByte("$`\"\\\n", s[j+1]) >= 0 {
+					j++
+					if s[j] != '\n' {
+						cur.WriteByte(s[j])
+					}
+					continue
+				}
+				cur.WriteByte(s[j])
+			}
+			if j >= len(s) {
+				return nil, fmt.Errorf("unterminated double quote at offset %d", i)
+			}
+			i = j
+		default:
+			begin(i)
+			cur.WriteByte(c)
+		}
+	}
+	flush(len(s))
+	return words, nil
+}
+
+// FormatArgv renders argv as a copy-pasteable command line, quoting only the words that
+// need it.
+func FormatArgv(argv []string) string {
+	quoted := make([]string, len(argv))
+	for i, a := range argv {
+		quoted[i] = shellQuote(a)
+	}
+	return strings.Join(quoted, " ")
+}
@@ -0,0 +1,60 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestSplitShellWords(t *testing.T) {
+	cases := map[string][]string{
+		`python app.py --verbose`:           {"python", "app.py", "--verbose"},
+		`python "my script.py" 'a b'`:       {"python", "my script.py", "a b"},
+		`echo "say \"hi\" \$HOME" it\'s`:    {"echo", `say "hi" $HOME`, "it's"},
+		`echo "keep \n" $VAR`:               {"echo", `keep \n`, "$VAR"},
+		`sh tool.sh|tee out.log && echo ok`: {"sh", "tool.sh", "|", "tee", "out.log", "&&", "echo", "ok"},
+		`run a""b '' # trailing comment`:    {"run", "ab", ""},
+		`  `:                                {},
+	}
+	for in, want := range cases {
+		got, err := SplitShellWords(in)
+		if err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		if len(got) == 0 && len(want) == 0 {
+			continue
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("SplitShellWords(%s) = %q, want %q", in, got, want)
+		}
+	}
+	for _, in := range []string{`echo 'open`, `echo "open`} {
+		if _, err := SplitShellWords(in); err == nil {
+			t.Errorf("expected unterminated quote error for %s", in)
+		}
+	}
+}
+
+func TestRebaseRunToDirKeepsQuoting(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("POSIX quoting")
+	}
+	dir := filepath.Join(t.TempDir(), "cache dir")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "my tool.py"), []byte("print(1)"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got := rebaseRunToDir(`python -u "my tool.py" --name 'a  b' | tee "out file"`, dir)
+	want := `python -u '` + filepath.Join(dir, "my tool.py") + `' --name 'a  b' | tee "out file"`
+	if got != want {
+		t.Fatalf("rebaseRunToDir = %s\nwant %s", got, want)
+	}
+	words, err := SplitShellWords(got)
+	if err != nil || words[2] != filepath.Join(dir, "my tool.py") || words[4] != "a  b" {
+		t.Fatalf("rebased line does not split back: %q (%v)", words, err)
+	}
+}
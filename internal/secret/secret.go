@@ -0,0 +1,216 @@
+// Package secret keeps named credentials in a local file that only the user can read,
+// optionally encrypted with a key derived from a passphrase.
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// PassphraseEnv may hold the passphrase of an encrypted store, for non-interactive use.
+// gixt removes it from the environment of the gists it runs.
+const PassphraseEnv = "GIXT_SECRET_PASSPHRASE"
+
+// Iterations is the PBKDF2 work factor used when saving an encrypted store. Stores record
+// their own count, so raising it does not break existing files.
+var Iterations = 600_000
+
+// ErrWrongPassphrase is returned when an encrypted store cannot be opened.
+var ErrWrongPassphrase = errors.New("wrong passphrase (or the secret store is corrupted)")
+
+const (
+	fileVersion = 1
+	kdfName     = "pbkdf2-hmac-sha256"
+	aad         = "gixt secrets v1"
+)
+
+// Store holds secrets by name. A non-empty passphrase means it is saved encrypted.
+type Store struct {
+	Secrets    map[string]string
+	passphrase string
+}
+
+type kdfParams struct {
+	Name       string `json:"name"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+}
+
+type fileFormat struct {
+	Version    int               `json:"version"`
+	Secrets    map[string]string `json:"secrets,omitempty"`
+	KDF        *kdfParams        `json:"kdf,omitempty"`
+	Nonce      []byte            `json:"nonce,omitempty"`
+	Ciphertext []byte            `json:"ciphertext,omitempty"`
+}
+
+// Encrypted reports whether the store is saved encrypted.
+func (s *Store) Encrypted() bool {
+	return s.passphrase != ""
+}
+
+// SetPassphrase changes the passphrase used by the next Save; "" saves the store unencrypted.
+func (s *Store) SetPassphrase(passphrase string) {
+	s.passphrase = passphrase
+}
+
+// Names returns the secret names, sorted.
+func (s *Store) Names() []string {
+	names := make([]string, 0, len(s.Secrets))
+	for name := range s.Secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load reads the store at path; a missing file is an empty, unencrypted store. passphrase is
+// called only when the file is encrypted.
+func Load(path string, passphrase func() (string, error)) (*Store, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Store{Secrets: map[string]string{}}, nil
+		}
+		return nil, fmt.Errorf("read secret store: %w", err)
+	}
+	var f fileFormat
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse secret store: %w", err)
+	}
+	if f.Version != fileVersion {
+		return nil, fmt.Errorf("secret store version %d is not supported", f.Version)
+	}
+	if f.KDF == nil {
+		if f.Secrets == nil {
+			f.Secrets = map[string]string{}
+		}
+		return &Store{Secrets: f.Secrets}, nil
+	}
+	if f.KDF.Name != kdfName || f.KDF.Iterations < 1 {
+		return nil, fmt.Errorf("secret store uses unknown key derivation %q", f.KDF.Name)
+	}
+	pass, err := passphrase()
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(pass, f.KDF.Salt, f.KDF.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Nonce) != gcm.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plain, err := gcm.Open(nil, f.Nonce, f.Ciphertext, []byte(aad))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	s := &Store{Secrets: map[string]string{}, passphrase: pass}
+	if err := json.Unmarshal(plain, &s.Secrets); err != nil {
+		return nil, fmt.Errorf("parse secret store: %w", err)
+	}
+	return s, nil
+}
+
+// Save writes the store to path with mode 0600, encrypting it when it has a passphrase. A
+// fresh salt and nonce are used on every save.
+func Save(path string, s *Store) error {
+	f := fileFormat{Version: fileVersion}
+	if s.passphrase == "" {
+		f.Secrets = s.Secrets
+	} else {
+		plain, err := json.Marshal(s.Secrets)
+		if err != nil {
+			return fmt.Errorf("encode secrets: %w", err)
+		}
+		salt := make([]byte, 16)
+		if _, err := rand.Read(salt); err != nil {
+			return fmt.Errorf("generate salt: %w", err)
+		}
+		gcm, err := newGCM(s.passphrase, salt, Iterations)
+		if err != nil {
+			return err
+		}
+		nonce := make([]byte, gcm.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return fmt.Errorf("generate nonce: %w", err)
+		}
+		f.KDF = &kdfParams{Name: kdfName, Iterations: Iterations, Salt: salt}
+		f.Nonce = nonce
+		f.Ciphertext = gcm.Seal(nil, nonce, plain, []byte(aad))
+	}
+	buf, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode secret store: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create config dir: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".secrets-*.tmp")
+	if err != nil {
+		return fmt.Errorf("write secret store: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if err := tmp.Chmod(0o600); err != nil && !errors.Is(err, errors.ErrUnsupported) {
+		tmp.Close()
+		return fmt.Errorf("write secret store: %w", err)
+	}
+	if _, err := tmp.Write(buf); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write secret store: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write secret store: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("write secret store: %w", err)
+	}
+	return nil
+}
+
+func newGCM(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key := pbkdf2SHA256([]byte(passphrase), salt, iterations, 32)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("init cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// pbkdf2SHA256 derives a key as in RFC 8018 section 5.2 with HMAC-SHA256 as the PRF.
+func pbkdf2SHA256(password, salt []byte, iterations, keyLen int) []byte {
+	prf := hmac.New(sha256.New, password)
+	hashLen := prf.Size()
+	blocks := (keyLen + hashLen - 1) / hashLen
+	dk := make([]byte, 0, blocks*hashLen)
+	u := make([]byte, hashLen)
+	var counter [4]byte
+	for block := 1; block <= blocks; block++ {
+		prf.Reset()
+		prf.Write(salt)
+		binary.BigEndian.PutUint32(counter[:], uint32(block))
+		prf.Write(counter[:])
+		dk = prf.Sum(dk)
+		t := dk[len(dk)-hashLen:]
+		copy(u, t)
+		for i := 2; i <= iterations; i++ {
+			prf.Reset()
+			prf.Write(u)
+			u = prf.Sum(u[:0])
+			for j := range u {
+				t[j] ^= u[j]
+			}
+		}
+	}
+	return dk[:keyLen]
+}
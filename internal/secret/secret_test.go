@@ -0,0 +1,69 @@
+package secret
+
+import (
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestPBKDF2MatchesRFC7914Vector(t *testing.T) {
+	got := hex.EncodeToString(pbkdf2SHA256([]byte("passwd"), []byte("salt"), 1, 64))
+	want := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	if got != want {
+		t.Fatalf("pbkdf2 mismatch:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestPlainStoreRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.json")
+	s, err := Load(path, nil)
+	if err != nil || len(s.Secrets) != 0 || s.Encrypted() {
+		t.Fatalf("expected empty store, got %+v (%v)", s, err)
+	}
+	s.Secrets["API_TOKEN"] = "abc"
+	if err := Save(path, s); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(path)
+		if err != nil || info.Mode().Perm() != 0o600 {
+			t.Fatalf("expected mode 0600, got %v (%v)", info.Mode().Perm(), err)
+		}
+	}
+	loaded, err := Load(path, nil)
+	if err != nil || loaded.Secrets["API_TOKEN"] != "abc" {
+		t.Fatalf("unexpected store %+v (%v)", loaded, err)
+	}
+}
+
+func TestEncryptedStoreNeedsPassphrase(t *testing.T) {
+	orig := Iterations
+	Iterations = 1000
+	t.Cleanup(func() { Iterations = orig })
+	path := filepath.Join(t.TempDir(), "secrets.json")
+
+	s := &Store{Secrets: map[string]string{"API_TOKEN": "abc"}}
+	s.SetPassphrase("hunter2")
+	if err := Save(path, s); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "API_TOKEN") {
+		t.Fatalf("encrypted store leaks secret names: %s", data)
+	}
+
+	pass := func(p string) func() (string, error) {
+		return func() (string, error) { return p, nil }
+	}
+	if _, err := Load(path, pass("wrong")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("expected ErrWrongPassphrase, got %v", err)
+	}
+	loaded, err := Load(path, pass("hunter2"))
+	if err != nil || loaded.Secrets["API_TOKEN"] != "abc" || !loaded.Encrypted() {
+		t.Fatalf("unexpected store %+v (%v)", loaded, err)
+	}
+}
@@ -0,0 +1,126 @@
+// Package shim writes launcher scripts that run a gist through gixt and tracks which
+// launchers gixt owns.
+package shim
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Record describes one installed launcher. Records are the only proof of ownership:
+// gixt never overwrites or deletes a file that has no matching record.
+type Record struct {
+	Name        string    `json:"name"`
+	Path        string    `json:"path"`
+	Gist        string    `json:"gist"`              // gist key passed to `gixt run`
+	Command     string    `json:"command,omitempty"` // manifest command, run as gist:command
+	Ref         string    `json:"ref,omitempty"`
+	Flags       []string  `json:"flags,omitempty"` // extra run flags baked into the launcher
+	SHA256      string    `json:"sha256"`          // hash of the launcher as written
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+type file struct {
+	Shims []Record `json:"shims"`
+}
+
+func Load(path string) ([]Record, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read installs: %w", err)
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse installs: %w", err)
+	}
+	return f.Shims, nil
+}
+
+func Save(path string, records []Record) error {
+	sort.Slice(records, func(i, j int) bool { return records[i].Name < records[j].Name })
+	buf, err := json.MarshalIndent(file{Shims: records}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode installs: %w", err)
+	}
+	if err := os.WriteFile(path, buf, 0o644); err != nil {
+		return fmt.Errorf("write installs: %w", err)
+	}
+	return nil
+}
+
+// FileName is the launcher file name for cmd on goos.
+func FileName(goos string, cmd string) string {
+	if goos == "windows" {
+		return cmd + ".cmd"
+	}
+	return cmd
+}
+
+// Args is the gixt argument list a launcher runs, before the user's own arguments.
+func (r Record) Args() []string {
+	args := []string{"run"}
+	if r.Ref != "" {
+		args = append(args, "--ref", r.Ref)
+	}
+	args = append(args, r.Flags...)
+	target := r.Gist
+	if r.Command != "" {
+		target += ":" + r.Command
+	}
+	return append(args, target, "--")
+}
+
+// Render returns the launcher script for goos that execs gixtPath with r's arguments and
+// forwards its own arguments to the gist.
+func Render(goos string, gixtPath string, r Record) (string, error) {
+	args := append([]string{gixtPath}, r.Args()...)
+	if goos == "windows" {
+		quoted := make([]string, len(args))
+		for i, a := range args {
+			if strings.ContainsAny(a, "\"%\r\n") {
+				return "", fmt.Errorf("cannot quote %q for a .cmd launcher", a)
+			}
+			quoted[i] = `"` + a + `"`
+		}
+		return fmt.Sprintf("@echo off\r\nrem Installed by gixt; remove with `gixt uninstall %s`.\r\n%s %%*\r\nexit /b %%ERRORLEVEL%%\r\n", r.Name, strings.Join(quoted, " ")), nil
+	}
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		quoted[i] = shellQuote(a)
+	}
+	return fmt.Sprintf("#!/bin/sh\n# Installed by gixt; remove with `gixt uninstall %s`.\nexec %s \"$@\"\n", r.Name, strings.Join(quoted, " ")), nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func Hash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// Status reports whether the launcher on disk is still the one gixt wrote.
+func (r Record) Status() string {
+	data, err := os.ReadFile(r.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "missing"
+	}
+	if err != nil {
+		return "unreadable"
+	}
+	if Hash(data) != r.SHA256 {
+		return "modified"
+	}
+	return "ok"
+}
@@ -0,0 +1,33 @@
+package shim
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderQuotesArguments(t *testing.T) {
+	r := Record{Name: "hello", Gist: "ghe.example.com/deadbeef", Ref: "abc123", Flags: []string{"--timeout", "30s", "--env", "GREETING=it's me"}}
+
+	sh, err := Render("linux", "/opt/gixt bin/gixt", r)
+	if err != nil {
+		t.Fatalf("render sh: %v", err)
+	}
+	want := `exec '/opt/gixt bin/gixt' 'run' '--ref' 'abc123' '--timeout' '30s' '--env' 'GREETING=it'\''s me' 'ghe.example.com/deadbeef' '--' "$@"`
+	if !strings.HasPrefix(sh, "#!/bin/sh\n") || !strings.Contains(sh, want) {
+		t.Fatalf("unexpected sh launcher:\n%s", sh)
+	}
+
+	r.Flags = nil
+	cmd, err := Render("windows", `C:\gixt\gixt.exe`, r)
+	if err != nil {
+		t.Fatalf("render cmd: %v", err)
+	}
+	if !strings.Contains(cmd, `"C:\gixt\gixt.exe" "run" "--ref" "abc123" "ghe.example.com/deadbeef" "--" %*`) {
+		t.Fatalf("unexpected cmd launcher:\n%s", cmd)
+	}
+
+	r.Flags = []string{"--env", "PCT=100%"}
+	if _, err := Render("windows", "gixt.exe", r); err == nil {
+		t.Fatalf("expected error for unquotable argument")
+	}
+}
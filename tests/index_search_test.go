@@ -0,0 +1,32 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/leolaurindo/gixt/internal/index"
+)
+
+func TestSearchRanksFuzzyMatches(t *testing.T) {
+	docs := []index.Document{
+		{Entry: index.Entry{ID: "id1", Description: "Convert CSV exports to JSON", Filenames: []string{"csv-to-json.py"}, Owner: "alice"}},
+		{Entry: index.Entry{ID: "id2", Description: "backup script", Filenames: []string{"backup.sh"}, Owner: "bob"}},
+		{Entry: index.Entry{ID: "id3", Description: "misc", Filenames: []string{"tool.go"}, Owner: "carol"}, Details: "reads a csv and prints totals"},
+	}
+
+	got := index.Search(docs, "csv script")
+	if len(got) < 3 || got[0].Entry.ID != "id1" {
+		t.Fatalf("expected csv-to-json first, got %+v", got)
+	}
+	if got := index.Search(docs, "bakcup"); len(got) != 1 || got[0].Entry.ID != "id2" || got[0].Name != "backup" {
+		t.Fatalf("expected transposition to match backup, got %+v", got)
+	}
+	if got := index.Search(docs, "totals"); len(got) != 1 || got[0].Entry.ID != "id3" {
+		t.Fatalf("expected manifest details match, got %+v", got)
+	}
+	if got := index.Search(docs, "carol"); len(got) != 1 || got[0].Entry.ID != "id3" {
+		t.Fatalf("expected owner match, got %+v", got)
+	}
+	if got := index.Search(docs, "zzzz"); len(got) != 0 {
+		t.Fatalf("expected no matches, got %+v", got)
+	}
+}
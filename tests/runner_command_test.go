@@ -3,6 +3,7 @@ package tests
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
@@ -10,6 +11,14 @@ import (
 )
 
 func TestBuildCommandPrefersExtension(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake interpreter is a shell script")
+	}
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "python3"), []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("write fake python3: %v", err)
+	}
+	t.Setenv("PATH", bin)
 	dir := t.TempDir()
 	mainPath := filepath.Join(dir, "main.py")
 	if err := os.WriteFile(mainPath, []byte("print('ok')"), 0o644); err != nil {
@@ -20,8 +29,8 @@ func TestBuildCommandPrefersExtension(t *testing.T) {
 	if err != nil {
 		t.Fatalf("BuildCommand error: %v", err)
 	}
-	if reason == "" || cmd[0] != "python" {
-		t.Fatalf("expected python command, got %v (reason %q)", cmd, reason)
+	if reason == "" || cmd[0] != "python3" {
+		t.Fatalf("expected python3 command, got %v (reason %q)", cmd, reason)
 	}
 	if got := cmd[len(cmd)-1]; got != "--foo" {
 		t.Fatalf("expected forwarded arg, got %s", got)
@@ -115,3 +124,125 @@ func TestBuildCommandRespectsShebangAndUnknownExtension(t *testing.T) {
 		t.Fatalf("expected error for unknown extension")
 	}
 }
+
+func TestResolveExpandsDeclaredArgsIntoRun(t *testing.T) {
+	dir := t.TempDir()
+	manifest := `{"run":"echo {{name}}","args":{"positional":[{"name":"name","required":true}]}}`
+	if err := os.WriteFile(filepath.Join(dir, "gixt.json"), []byte(manifest), 0o644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+
+	cmd, err := runner.Resolve(runner.Request{Dir: dir, ManifestPath: "gixt.json", Files: []string{"gixt.json"}, Args: []string{"it's me"}, ExecDir: dir})
+	if err != nil {
+		t.Fatalf("Resolve error: %v", err)
+	}
+	if runtime.GOOS != "windows" && cmd.Argv[len(cmd.Argv)-1] != `echo 'it'\''s me'` {
+		t.Fatalf("expected quoted placeholder and no appended args, got %q", cmd.Argv)
+	}
+	if cmd.Env["GIXT_ARG_NAME"] != "it's me" {
+		t.Fatalf("expected arg env var, got %v", cmd.Env)
+	}
+	if _, err := runner.Resolve(runner.Request{Dir: dir, ManifestPath: "gixt.json", ExecDir: dir}); err == nil {
+		t.Fatalf("expected missing required argument error")
+	}
+}
+
+func TestResolveRunsArgvManifestWithoutShell(t *testing.T) {
+	dir := t.TempDir()
+	other := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "my tool.py"), []byte("print('hi')"), 0o644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	manifest := `{"run":["python","my tool.py","--files","{{files}}","--tag=v{{tag}}"],
+		"args":{"positional":[{"name":"tag"},{"name":"files","variadic":true}]}}`
+	if err := os.WriteFile(filepath.Join(dir, "gixt.json"), []byte(manifest), 0o644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+
+	cmd, err := runner.Resolve(runner.Request{Dir: dir, ManifestPath: "gixt.json", Files: []string{"my tool.py"}, Args: []string{"1.0", "a b", "$HOME"}, ExecDir: other})
+	if err != nil {
+		t.Fatalf("Resolve error: %v", err)
+	}
+	want := []string{"python", filepath.Join(dir, "my tool.py"), "--files", "a b", "$HOME", "--tag=v1.0"}
+	if strings.Join(cmd.Argv, "\x00") != strings.Join(want, "\x00") {
+		t.Fatalf("argv = %q, want %q", cmd.Argv, want)
+	}
+	if cmd.Reason != "manifest (argv, no shell)" {
+		t.Fatalf("unexpected reason %q", cmd.Reason)
+	}
+}
+
+func TestResolveUsesEnvironmentInterpreters(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.py"), []byte("print(1)"), 0o644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.py"), []byte("#!/usr/bin/env python3\nprint(1)"), 0o644); err != nil {
+		t.Fatalf("write script: %v", err)
+	}
+	venvPython := filepath.Join(dir, ".gixt-venv", "bin", "python")
+	req := runner.Request{
+		Dir:          dir,
+		Files:        []string{"a.py"},
+		ExecDir:      dir,
+		Interpreters: map[string]string{"python": venvPython, "python3": venvPython},
+		EnvVars:      map[string]string{"VIRTUAL_ENV": filepath.Join(dir, ".gixt-venv")},
+	}
+	cmd, err := runner.Resolve(req)
+	if err != nil {
+		t.Fatalf("Resolve error: %v", err)
+	}
+	if cmd.Argv[0] != venvPython || cmd.Env["VIRTUAL_ENV"] == "" {
+		t.Fatalf("expected venv interpreter and env, got %q %v", cmd.Argv, cmd.Env)
+	}
+
+	req.Files = []string{"b.py"}
+	cmd, err = runner.Resolve(req)
+	if err != nil {
+		t.Fatalf("Resolve error: %v", err)
+	}
+	if cmd.Argv[0] != venvPython || cmd.Reason != "shebang" {
+		t.Fatalf("expected shebang interpreter swapped for the venv, got %q (%s)", cmd.Argv, cmd.Reason)
+	}
+}
+
+func TestResolveExpandsEnvReferences(t *testing.T) {
+	dir := t.TempDir()
+	manifest := `{"run":["tool","--out","${OUT_DIR:-${GIXT_WORKDIR}/out}","${UNSET_IN_RUN}"],
+		"env":{"PATH":"${GIXT_WORKDIR}/bin:${PATH}","API":"${API_BASE:-https://api.example.com}/v1"},
+		"required_env":["API_TOKEN"]}`
+	if err := os.WriteFile(filepath.Join(dir, "gixt.json"), []byte(manifest), 0o644); err != nil {
+		t.Fatalf("write manifest: %v", err)
+	}
+	caller := map[string]string{"PATH": "/usr/bin"}
+	req := runner.Request{
+		Dir:          dir,
+		ManifestPath: "gixt.json",
+		Files:        []string{"gixt.json"},
+		ExecDir:      dir,
+		EnvOverrides: map[string]string{"API_BASE": "http://localhost:8080"},
+		LookupEnv: func(name string) (string, bool) {
+			v, ok := caller[name]
+			return v, ok
+		},
+	}
+	cmd, err := runner.Resolve(req)
+	if err != nil {
+		t.Fatalf("Resolve error: %v", err)
+	}
+	want := []string{"tool", "--out", dir + "/out", "${UNSET_IN_RUN}"}
+	if strings.Join(cmd.Argv, "|") != strings.Join(want, "|") {
+		t.Fatalf("argv = %q, want %q", cmd.Argv, want)
+	}
+	if cmd.Env["PATH"] != dir+"/bin:/usr/bin" || cmd.Env["API"] != "http://localhost:8080/v1" || cmd.Env["API_BASE"] != "http://localhost:8080" {
+		t.Fatalf("unexpected env %v", cmd.Env)
+	}
+	lookup := req.LookupEnv
+	if missing := runner.MissingEnv(cmd, lookup); len(missing) != 1 || missing[0] != "API_TOKEN" {
+		t.Fatalf("expected API_TOKEN to be missing, got %v", missing)
+	}
+	caller["API_TOKEN"] = "secret"
+	if missing := runner.MissingEnv(cmd, lookup); len(missing) != 0 {
+		t.Fatalf("expected no missing env, got %v", missing)
+	}
+}